@@ -1,9 +1,18 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"strconv"
+	"time"
 	
+	"github.com/anti-fraud-golang/internal/cardintel"
+	"github.com/anti-fraud-golang/internal/currency"
 	"github.com/anti-fraud-golang/internal/handlers"
+	"github.com/anti-fraud-golang/internal/ipintel"
+	"github.com/anti-fraud-golang/internal/ml"
+	"github.com/anti-fraud-golang/internal/rules"
 	"github.com/anti-fraud-golang/internal/services"
 	"github.com/gin-gonic/gin"
 )
@@ -20,8 +29,149 @@ func main() {
 	// Inicializa serviço de detecção de fraude
 	fraudService := services.NewFraudDetectionService(profileStore, blacklistStore)
 	
+	// Carrega modelo treinado pelo cmd/train, se configurado
+	if modelPath := os.Getenv("FRAUD_MODEL_PATH"); modelPath != "" {
+		model, err := ml.LoadModel(modelPath)
+		if err != nil {
+			log.Fatalf("Erro ao carregar modelo: %v", err)
+		}
+		if err := fraudService.SetModel(model); err != nil {
+			log.Fatalf("Erro ao carregar modelo: %v", err)
+		}
+		log.Printf("🧠 Modelo %s carregado de %s", model.Version, modelPath)
+	}
+	
+	// Carrega configuração do motor de regras, se informada
+	if configPath := os.Getenv("FRAUD_RULES_CONFIG"); configPath != "" {
+		config, err := rules.LoadEngineConfig(configPath)
+		if err != nil {
+			log.Fatalf("Erro ao carregar configuração de regras: %v", err)
+		}
+		version, err := fraudService.ApplyRuleConfig(*config, services.RuleChange{
+			Author:  "system",
+			Comment: "carregada de " + configPath,
+		})
+		if err != nil {
+			log.Fatalf("Configuração de regras inválida: %v", err)
+		}
+		log.Printf("⚙️  Configuração de regras carregada de %s (versão %d)", configPath, version.Version)
+	}
+	
+	// Carrega políticas de degradação das dependências, se informadas
+	if degradationPath := os.Getenv("FRAUD_DEGRADATION_CONFIG"); degradationPath != "" {
+		config, err := services.LoadDegradationConfig(degradationPath)
+		if err != nil {
+			log.Fatalf("Erro ao carregar políticas de degradação: %v", err)
+		}
+		if err := fraudService.SetDegradationConfig(*config); err != nil {
+			log.Fatalf("Políticas de degradação inválidas: %v", err)
+		}
+		log.Printf("🛡️  Políticas de degradação carregadas de %s", degradationPath)
+	}
+	
+	// Carrega a base local de geolocalização de IPs, se informada
+	if geoPath := os.Getenv("FRAUD_IP_GEO_DB"); geoPath != "" {
+		geoDatabase, err := ipintel.LoadGeoDatabase(geoPath)
+		if err != nil {
+			log.Fatalf("Erro ao carregar base de geolocalização de IPs: %v", err)
+		}
+		fraudService.SetIPGeolocator(geoDatabase)
+		log.Printf("🌐 Base de geolocalização de IPs carregada de %s (%d faixas)", geoPath, geoDatabase.Len())
+	}
+	
+	// Carrega as listas de anonimizadores e as recarrega periodicamente, se informadas
+	if reputationPath := os.Getenv("FRAUD_IP_REPUTATION_CONFIG"); reputationPath != "" {
+		config, err := ipintel.LoadReputationConfig(reputationPath)
+		if err != nil {
+			log.Fatalf("Erro ao carregar listas de reputação de IPs: %v", err)
+		}
+		reputation, err := ipintel.NewReputation(config.Sources)
+		if err != nil {
+			log.Fatalf("Erro ao carregar listas de reputação de IPs: %v", err)
+		}
+		fraudService.SetIPReputation(reputation)
+		log.Printf("🕵️  Listas de reputação de IPs carregadas de %s (%d entradas)", reputationPath, reputation.Size())
+		
+		if config.ReloadIntervalS > 0 {
+			go reputation.Watch(context.Background(), time.Duration(config.ReloadIntervalS)*time.Second,
+				func() { log.Printf("🕵️  Listas de reputação de IPs recarregadas (%d entradas)", reputation.Size()) },
+				func(err error) { log.Printf("Erro ao recarregar listas de reputação de IPs: %v", err) },
+			)
+		}
+	}
+	
+	// Carrega a tabela de BINs dos emissores de cartão, se informada
+	if binPath := os.Getenv("FRAUD_BIN_TABLE"); binPath != "" {
+		binTable, err := cardintel.LoadBINTable(binPath)
+		if err != nil {
+			log.Fatalf("Erro ao carregar tabela de BINs: %v", err)
+		}
+		fraudService.SetBINTable(binTable)
+		log.Printf("💳 Tabela de BINs carregada de %s (%d BINs)", binPath, binTable.Len())
+	}
+	
+	// Moeda base de limites e perfis; sem cotações, só ela é aceita
+	if base := os.Getenv("FRAUD_BASE_CURRENCY"); base != "" {
+		if err := fraudService.SetBaseCurrency(base); err != nil {
+			log.Fatalf("FRAUD_BASE_CURRENCY inválido: %v", err)
+		}
+	}
+	
+	// Carrega as cotações para conversão à moeda base, se informadas
+	if ratesPath := os.Getenv("FRAUD_FX_RATES"); ratesPath != "" {
+		rates, err := currency.LoadRateTable(ratesPath)
+		if err != nil {
+			log.Fatalf("Erro ao carregar cotações: %v", err)
+		}
+		var maxAge time.Duration
+		if age := os.Getenv("FRAUD_FX_MAX_RATE_AGE"); age != "" {
+			if maxAge, err = time.ParseDuration(age); err != nil {
+				log.Fatalf("FRAUD_FX_MAX_RATE_AGE inválido: %v", err)
+			}
+		}
+		if err := fraudService.SetFXRates(rates, maxAge); err != nil {
+			log.Fatalf("Erro ao configurar cotações: %v", err)
+		}
+		log.Printf("💱 Cotações de %s carregadas de %s (%d moedas, base %s)",
+			rates.Date().Format("2006-01-02"), ratesPath, rates.Len(), rates.Base())
+	}
+	
+	// Aprovações necessárias para um dispositivo se tornar confiável
+	if approvals := os.Getenv("FRAUD_DEVICE_TRUST_APPROVALS"); approvals != "" {
+		threshold, err := strconv.Atoi(approvals)
+		if err != nil {
+			log.Fatalf("FRAUD_DEVICE_TRUST_APPROVALS inválido: %v", err)
+		}
+		if err := fraudService.SetDeviceTrustThreshold(threshold); err != nil {
+			log.Fatalf("FRAUD_DEVICE_TRUST_APPROVALS inválido: %v", err)
+		}
+	}
+	
+	// Janela aceita para timestamps enviados pelos clientes
+	timestampPolicy := services.DefaultTimestampPolicy()
+	if skew := os.Getenv("FRAUD_MAX_CLOCK_SKEW"); skew != "" {
+		duration, err := time.ParseDuration(skew)
+		if err != nil {
+			log.Fatalf("FRAUD_MAX_CLOCK_SKEW inválido: %v", err)
+		}
+		timestampPolicy.MaxFutureSkew = duration
+	}
+	if age := os.Getenv("FRAUD_MAX_EVENT_AGE"); age != "" {
+		duration, err := time.ParseDuration(age)
+		if err != nil {
+			log.Fatalf("FRAUD_MAX_EVENT_AGE inválido: %v", err)
+		}
+		timestampPolicy.MaxEventAge = duration
+	}
+	if err := fraudService.SetTimestampPolicy(timestampPolicy); err != nil {
+		log.Fatalf("Janela de timestamps inválida: %v", err)
+	}
+	
 	// Inicializa handlers
 	fraudHandler := handlers.NewFraudHandler(fraudService)
+	ruleHandler := handlers.NewRuleHandler(fraudService)
+	travelHandler := handlers.NewTravelHandler(fraudService)
+	pixHandler := handlers.NewPIXHandler(fraudService)
 	
 	// Configura router
 	router := gin.Default()
@@ -45,6 +195,41 @@ func main() {
 		analytics := api.Group("/analytics")
 		{
 			analytics.GET("/:user_id", fraudHandler.GetAnalytics)
+			analytics.GET("/peers/:dimension", fraudHandler.GetPeerGroupAnalytics)
+		}
+		
+		// Avisos de viagem e limites PIX dos clientes
+		users := api.Group("/users/:user_id")
+		{
+			users.POST("/travel-notices", travelHandler.RegisterTravelNotice)
+			users.GET("/travel-notices", travelHandler.ListTravelNotices)
+			users.DELETE("/travel-notices/:id", travelHandler.DeleteTravelNotice)
+			users.GET("/pix-limits", pixHandler.GetPIXLimits)
+			users.PUT("/pix-limits", pixHandler.SetPIXLimits)
+		}
+		
+		// Contas laranjas do PIX
+		pix := api.Group("/pix")
+		{
+			pix.POST("/mules", pixHandler.FlagMule)
+			pix.GET("/mules", pixHandler.ListMules)
+			pix.DELETE("/mules/:id", pixHandler.UnflagMule)
+		}
+		
+		// Regras
+		ruleRoutes := api.Group("/rules")
+		{
+			ruleRoutes.GET("/expressions", ruleHandler.ListExpressionRules)
+			ruleRoutes.GET("/expressions/variables", ruleHandler.GetExpressionVariables)
+			ruleRoutes.POST("/expressions", ruleHandler.SaveExpressionRule)
+			ruleRoutes.POST("/expressions/validate", ruleHandler.ValidateExpressionRule)
+			ruleRoutes.DELETE("/expressions/:id", ruleHandler.DeleteExpressionRule)
+			ruleRoutes.GET("/config", ruleHandler.GetRuleConfig)
+			ruleRoutes.PUT("/config", ruleHandler.UpdateRuleConfig)
+			ruleRoutes.GET("/versions", ruleHandler.ListRuleVersions)
+			ruleRoutes.GET("/versions/diff", ruleHandler.DiffRuleVersions)
+			ruleRoutes.GET("/versions/:version", ruleHandler.GetRuleVersion)
+			ruleRoutes.POST("/versions/:version/rollback", ruleHandler.RollbackRuleVersion)
 		}
 	}
 	
@@ -58,6 +243,26 @@ func main() {
 				"GET  /api/v1/health",
 				"POST /api/v1/transaction/analyze",
 				"GET  /api/v1/analytics/:user_id",
+				"GET  /api/v1/analytics/peers/:dimension",
+				"POST /api/v1/users/:user_id/travel-notices",
+				"GET  /api/v1/users/:user_id/travel-notices",
+				"DELETE /api/v1/users/:user_id/travel-notices/:id",
+				"GET  /api/v1/users/:user_id/pix-limits",
+				"PUT  /api/v1/users/:user_id/pix-limits",
+				"POST /api/v1/pix/mules",
+				"GET  /api/v1/pix/mules",
+				"DELETE /api/v1/pix/mules/:id",
+				"GET  /api/v1/rules/expressions",
+				"GET  /api/v1/rules/expressions/variables",
+				"POST /api/v1/rules/expressions",
+				"POST /api/v1/rules/expressions/validate",
+				"DELETE /api/v1/rules/expressions/:id",
+				"GET  /api/v1/rules/config",
+				"PUT  /api/v1/rules/config",
+				"GET  /api/v1/rules/versions",
+				"GET  /api/v1/rules/versions/diff?from=&to=",
+				"GET  /api/v1/rules/versions/:version",
+				"POST /api/v1/rules/versions/:version/rollback",
 			},
 		})
 	})
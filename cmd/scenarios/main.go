@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/anti-fraud-golang/internal/rules"
+	"github.com/anti-fraud-golang/internal/scenarios"
+)
+
+func main() {
+	configPath := flag.String("config", os.Getenv("FRAUD_RULES_CONFIG"), "arquivo de configuração do motor de regras (padrão: FRAUD_RULES_CONFIG)")
+	dir := flag.String("dir", "scenarios", "diretório com arquivos de cenários *.json")
+	jsonOutput := flag.Bool("json", false, "imprime o resultado em JSON")
+	flag.Parse()
+
+	config := rules.EngineConfig{}
+	if *configPath != "" {
+		loaded, err := rules.LoadEngineConfig(*configPath)
+		if err != nil {
+			log.Fatalf("Erro ao carregar configuração de regras: %v", err)
+		}
+		config = *loaded
+	}
+
+	files := flag.Args()
+	if len(files) == 0 {
+		matches, err := filepath.Glob(filepath.Join(*dir, "*.json"))
+		if err != nil {
+			log.Fatalf("Erro ao listar cenários: %v", err)
+		}
+		files = matches
+	}
+	sort.Strings(files)
+
+	if len(files) == 0 {
+		log.Fatalf("Nenhum arquivo de cenários encontrado")
+	}
+
+	outcomes := make([]*scenarios.Outcome, 0)
+	failed := 0
+
+	for _, file := range files {
+		loaded, err := scenarios.LoadFile(file)
+		if err != nil {
+			log.Fatalf("Erro ao ler cenários: %v", err)
+		}
+
+		for _, scenario := range loaded {
+			outcome, err := scenarios.Run(context.Background(), config, scenario)
+			if err != nil {
+				log.Fatalf("Erro ao executar cenário %q de %s: %v", scenario.Name, file, err)
+			}
+			outcome.File = file
+			outcomes = append(outcomes, outcome)
+			if !outcome.Passed {
+				failed++
+			}
+		}
+	}
+
+	if *jsonOutput {
+		data, err := json.MarshalIndent(outcomes, "", "  ")
+		if err != nil {
+			log.Fatalf("Erro ao gerar resultado: %v", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		for _, outcome := range outcomes {
+			if outcome.Passed {
+				fmt.Printf("PASS  %s: %s\n", outcome.File, outcome.Scenario)
+				continue
+			}
+			fmt.Printf("FAIL  %s: %s (decisão %s, score %d, regras %v)\n",
+				outcome.File, outcome.Scenario, outcome.Decision, outcome.RiskScore, outcome.Triggered)
+			for _, mismatch := range outcome.Mismatches {
+				fmt.Printf("      - %s\n", mismatch)
+			}
+		}
+		fmt.Printf("\n%d cenários, %d falhas\n", len(outcomes), failed)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
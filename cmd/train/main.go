@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/anti-fraud-golang/internal/ml"
+)
+
+func main() {
+	defaults := ml.DefaultTrainConfig()
+
+	input := flag.String("input", "", "arquivo JSON Lines com transações rotuladas")
+	output := flag.String("output", "model.json", "arquivo de saída do modelo")
+	reportPath := flag.String("report", "report.json", "arquivo de saída do relatório de validação")
+	validation := flag.Float64("validation", 0.2, "fração mais recente dos dados usada para validação")
+	learningRate := flag.Float64("learning-rate", defaults.LearningRate, "taxa de aprendizado")
+	epochs := flag.Int("epochs", defaults.Epochs, "número de épocas")
+	l2 := flag.Float64("l2", defaults.L2, "regularização L2")
+	classWeighting := flag.Bool("class-weighting", defaults.ClassWeighting, "balanceia o peso das classes fraude/legítima")
+	flag.Parse()
+
+	if *input == "" {
+		log.Fatal("Parâmetro -input é obrigatório")
+	}
+	if *validation <= 0 || *validation >= 1 {
+		log.Fatal("Parâmetro -validation deve estar entre 0 e 1")
+	}
+
+	file, err := os.Open(*input)
+	if err != nil {
+		log.Fatalf("Erro ao abrir dados de treinamento: %v", err)
+	}
+	examples, err := ml.ReadLabeledTransactions(file)
+	file.Close()
+	if err != nil {
+		log.Fatalf("Erro ao ler dados de treinamento: %v", err)
+	}
+
+	train, validationSet := ml.TimeSplit(examples, *validation)
+	log.Printf("📦 %d exemplos: %d treino, %d validação", len(examples), len(train), len(validationSet))
+
+	model, err := ml.Train(train, ml.TrainConfig{
+		LearningRate:   *learningRate,
+		Epochs:         *epochs,
+		L2:             *l2,
+		ClassWeighting: *classWeighting,
+	})
+	if err != nil {
+		log.Fatalf("Erro ao treinar modelo: %v", err)
+	}
+
+	report := struct {
+		ModelVersion string              `json:"model_version"`
+		Train        ml.EvaluationReport `json:"train"`
+		Validation   ml.EvaluationReport `json:"validation"`
+	}{
+		ModelVersion: model.Version,
+		Train:        ml.Evaluate(model, train),
+		Validation:   ml.Evaluate(model, validationSet),
+	}
+
+	if err := model.Save(*output); err != nil {
+		log.Fatalf("Erro ao salvar modelo: %v", err)
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("Erro ao gerar relatório: %v", err)
+	}
+	if err := os.WriteFile(*reportPath, data, 0o644); err != nil {
+		log.Fatalf("Erro ao salvar relatório: %v", err)
+	}
+
+	log.Printf("✅ Modelo %s salvo em %s", model.Version, *output)
+	log.Printf("📊 Validação: AUC %.4f, precisão média %.4f", report.Validation.AUC, report.Validation.AveragePrecision)
+}
@@ -0,0 +1,152 @@
+package cardintel
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// Tamanhos aceitos de BIN: 6 dígitos (legado) a 8 dígitos (ISO/IEC 7812 atual)
+const (
+	MinBINLength = 6
+	MaxBINLength = 8
+)
+
+// BINTable tabela de BINs carregada em memória. A busca usa o prefixo mais
+// longo, então faixas de 8 dígitos podem refinar uma entrada de 6.
+type BINTable struct {
+	issuers map[string]models.CardIssuer
+}
+
+// LoadBINTable carrega a tabela de um arquivo CSV
+func LoadBINTable(path string) (*BINTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	table, err := ParseBINTable(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bin table %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// ParseBINTable lê BINs em CSV com cabeçalho: bin e country são obrigatórios;
+// bank, brand, type (credit, debit ou prepaid) e commercial (true/false) são
+// opcionais. Linhas iniciadas por # são ignoradas.
+func ParseBINTable(r io.Reader) (*BINTable, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("missing header")
+		}
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"bin", "country"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("header must have %s", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	table := &BINTable{issuers: make(map[string]models.CardIssuer)}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		bin := field(record, "bin")
+		if !ValidBIN(bin) {
+			return nil, fmt.Errorf("line %d: invalid bin %q", line, bin)
+		}
+		if _, exists := table.issuers[bin]; exists {
+			return nil, fmt.Errorf("line %d: duplicated bin %s", line, bin)
+		}
+
+		issuer := models.CardIssuer{
+			BIN:         bin,
+			Country:     strings.ToUpper(field(record, "country")),
+			Bank:        field(record, "bank"),
+			Brand:       field(record, "brand"),
+			ProductType: strings.ToLower(field(record, "type")),
+		}
+		if issuer.Country == "" {
+			return nil, fmt.Errorf("line %d: country is required", line)
+		}
+		switch issuer.ProductType {
+		case "", models.CardProductCredit, models.CardProductDebit, models.CardProductPrepaid:
+		default:
+			return nil, fmt.Errorf("line %d: invalid type %q", line, issuer.ProductType)
+		}
+		switch strings.ToLower(field(record, "commercial")) {
+		case "", "false", "no":
+		case "true", "yes":
+			issuer.Commercial = true
+		default:
+			return nil, fmt.Errorf("line %d: invalid commercial %q", line, field(record, "commercial"))
+		}
+
+		table.issuers[bin] = issuer
+	}
+
+	return table, nil
+}
+
+// Lookup retorna o emissor do BIN de 6 a 8 dígitos informado
+func (t *BINTable) Lookup(bin string) (*models.CardIssuer, bool) {
+	if !ValidBIN(bin) {
+		return nil, false
+	}
+	for length := len(bin); length >= MinBINLength; length-- {
+		if issuer, found := t.issuers[bin[:length]]; found {
+			issuer.BIN = bin
+			return &issuer, true
+		}
+	}
+	return nil, false
+}
+
+// Len número de BINs da tabela
+func (t *BINTable) Len() int {
+	return len(t.issuers)
+}
+
+// ValidBIN verifica se o valor tem de 6 a 8 dígitos
+func ValidBIN(bin string) bool {
+	if len(bin) < MinBINLength || len(bin) > MaxBINLength {
+		return false
+	}
+	for _, c := range bin {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
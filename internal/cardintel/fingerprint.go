@@ -0,0 +1,69 @@
+package cardintel
+
+import "strings"
+
+// Tamanhos aceitos para a impressão digital do cartão: PAR (29 caracteres),
+// tokens de rede e hashes com chave em hexadecimal ou base64
+const (
+	MinFingerprintLength = 16
+	MaxFingerprintLength = 128
+)
+
+// ValidFingerprint verifica se o valor é um identificador opaco de cartão e
+// não um número de cartão
+func ValidFingerprint(value string) bool {
+	if len(value) < MinFingerprintLength || len(value) > MaxFingerprintLength {
+		return false
+	}
+	for _, c := range value {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.ContainsRune("_-:+/=", c):
+		default:
+			return false
+		}
+	}
+	return !LooksLikePAN(value)
+}
+
+// LooksLikePAN indica se o valor tem formato de número de cartão: 12 a 19
+// dígitos, com ou sem espaços e hífens, e dígito verificador de Luhn válido
+func LooksLikePAN(value string) bool {
+	digits := digitsOnly(value)
+	if len(digits) < 12 || len(digits) > 19 {
+		return false
+	}
+	for _, c := range value {
+		if (c < '0' || c > '9') && c != ' ' && c != '-' {
+			return false
+		}
+	}
+	return luhnValid(digits)
+}
+
+func luhnValid(digits string) bool {
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+func digitsOnly(value string) string {
+	var b strings.Builder
+	for _, c := range value {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
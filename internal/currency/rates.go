@@ -0,0 +1,125 @@
+package currency
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// dateLayout formato das datas de cotação
+const dateLayout = "2006-01-02"
+
+// rateFile formato do arquivo de cotações: quanto vale uma unidade de cada
+// moeda na moeda base, com a data da cotação geral e, opcionalmente, datas
+// próprias para moedas cotadas em outro dia
+type rateFile struct {
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Rates map[string]float64 `json:"rates"`
+	Dates map[string]string  `json:"dates,omitempty"`
+}
+
+type rate struct {
+	value float64
+	date  time.Time
+}
+
+// RateTable cotações carregadas de um arquivo local para conversão à moeda base
+type RateTable struct {
+	base  string
+	date  time.Time
+	rates map[string]rate
+}
+
+// LoadRateTable carrega as cotações de um arquivo JSON
+func LoadRateTable(path string) (*RateTable, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	table, err := ParseRateTable(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fx rates %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// ParseRateTable lê as cotações em JSON:
+//
+//	{"base": "BRL", "date": "2026-01-15", "rates": {"USD": 5.42}, "dates": {"ARS": "2026-01-14"}}
+//
+// Moedas e base precisam ser códigos ISO 4217 ativos e cotações, positivas.
+func ParseRateTable(r io.Reader) (*RateTable, error) {
+	var file rateFile
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, err
+	}
+
+	base := Normalize(file.Base)
+	if !Valid(base) {
+		return nil, fmt.Errorf("invalid base currency %q", file.Base)
+	}
+	date, err := time.Parse(dateLayout, file.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", file.Date)
+	}
+
+	table := &RateTable{
+		base:  base,
+		date:  date,
+		rates: map[string]rate{base: {value: 1, date: date}},
+	}
+	for code, value := range file.Rates {
+		normalized := Normalize(code)
+		if !Valid(normalized) {
+			return nil, fmt.Errorf("invalid currency %q", code)
+		}
+		if value <= 0 {
+			return nil, fmt.Errorf("rate of %s must be positive", normalized)
+		}
+		if normalized == base && value != 1 {
+			return nil, fmt.Errorf("rate of base currency %s must be 1", base)
+		}
+		table.rates[normalized] = rate{value: value, date: date}
+	}
+	for code, value := range file.Dates {
+		normalized := Normalize(code)
+		entry, ok := table.rates[normalized]
+		if !ok {
+			return nil, fmt.Errorf("date given for currency %q without rate", code)
+		}
+		if entry.date, err = time.Parse(dateLayout, value); err != nil {
+			return nil, fmt.Errorf("invalid date %q for %s, expected YYYY-MM-DD", value, normalized)
+		}
+		table.rates[normalized] = entry
+	}
+
+	return table, nil
+}
+
+// Base moeda para a qual os valores são convertidos
+func (t *RateTable) Base() string {
+	return t.base
+}
+
+// Date data geral das cotações
+func (t *RateTable) Date() time.Time {
+	return t.date
+}
+
+// Rate retorna quanto vale uma unidade da moeda na moeda base e a data da cotação
+func (t *RateTable) Rate(code string) (float64, time.Time, bool) {
+	entry, ok := t.rates[Normalize(code)]
+	return entry.value, entry.date, ok
+}
+
+// Len número de moedas cotadas, incluindo a base
+func (t *RateTable) Len() int {
+	return len(t.rates)
+}
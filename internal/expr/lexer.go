@@ -0,0 +1,186 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// tokenKind tipo de token léxico
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenLBracket
+	tokenRBracket
+	tokenComma
+)
+
+// token unidade léxica com sua posição na expressão
+type token struct {
+	kind  tokenKind
+	text  string
+	value interface{}
+	pos   Position
+}
+
+// Position posição na expressão (linha e coluna começam em 1)
+type Position struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Position) String() string {
+	return fmt.Sprintf("line %d, column %d", p.Line, p.Column)
+}
+
+// Error erro de compilação ou avaliação com a posição de origem
+type Error struct {
+	Pos     Position `json:"position"`
+	Message string   `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+func errorf(pos Position, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+// operators operadores reconhecidos, dos mais longos para os mais curtos
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "<", ">", "+", "-", "*", "/", "%", "!"}
+
+// lex divide a expressão em tokens
+func lex(source string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(source)
+	line, column := 1, 1
+	i := 0
+
+	advance := func(n int) {
+		for k := 0; k < n; k++ {
+			if runes[i] == '\n' {
+				line++
+				column = 1
+			} else {
+				column++
+			}
+			i++
+		}
+	}
+
+	for i < len(runes) {
+		r := runes[i]
+		pos := Position{Offset: i, Line: line, Column: column}
+
+		switch {
+		case unicode.IsSpace(r):
+			advance(1)
+
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == '_') {
+				advance(1)
+			}
+			text := string(runes[start:i])
+			value, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64)
+			if err != nil {
+				return nil, errorf(pos, "invalid number %q", text)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: text, value: value, pos: pos})
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				advance(1)
+			}
+			text := string(runes[start:i])
+			if strings.HasSuffix(text, ".") || strings.Contains(text, "..") {
+				return nil, errorf(pos, "invalid identifier %q", text)
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: text, pos: pos})
+
+		case r == '"' || r == '\'':
+			quote := r
+			advance(1)
+			var builder strings.Builder
+			closed := false
+			for i < len(runes) {
+				c := runes[i]
+				if c == quote {
+					advance(1)
+					closed = true
+					break
+				}
+				if c == '\\' && i+1 < len(runes) {
+					advance(1)
+					switch runes[i] {
+					case 'n':
+						builder.WriteRune('\n')
+					case 't':
+						builder.WriteRune('\t')
+					default:
+						builder.WriteRune(runes[i])
+					}
+					advance(1)
+					continue
+				}
+				builder.WriteRune(c)
+				advance(1)
+			}
+			if !closed {
+				return nil, errorf(pos, "unterminated string")
+			}
+			tokens = append(tokens, token{kind: tokenString, text: builder.String(), value: builder.String(), pos: pos})
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: pos})
+			advance(1)
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: pos})
+			advance(1)
+		case r == '[':
+			tokens = append(tokens, token{kind: tokenLBracket, text: "[", pos: pos})
+			advance(1)
+		case r == ']':
+			tokens = append(tokens, token{kind: tokenRBracket, text: "]", pos: pos})
+			advance(1)
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: pos})
+			advance(1)
+
+		default:
+			matched := ""
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:minInt(i+len(op), len(runes))]), op) {
+					matched = op
+					break
+				}
+			}
+			if matched == "" {
+				return nil, errorf(pos, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: matched, pos: pos})
+			advance(len(matched))
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: Position{Offset: i, Line: line, Column: column}})
+	return tokens, nil
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,262 @@
+package expr
+
+// Limites que mantêm a avaliação barata e previsível
+const (
+	MaxExpressionLength = 4096
+	MaxDepth            = 64
+	MaxNodes            = 1024
+)
+
+// node nó da árvore sintática
+type node interface {
+	position() Position
+}
+
+type literalNode struct {
+	pos   Position
+	value interface{}
+}
+
+type identNode struct {
+	pos  Position
+	path string
+}
+
+type unaryNode struct {
+	pos     Position
+	op      string
+	operand node
+}
+
+type binaryNode struct {
+	pos         Position
+	op          string
+	left, right node
+}
+
+type callNode struct {
+	pos  Position
+	name string
+	args []node
+}
+
+type listNode struct {
+	pos      Position
+	elements []node
+}
+
+func (n *literalNode) position() Position { return n.pos }
+func (n *identNode) position() Position   { return n.pos }
+func (n *unaryNode) position() Position   { return n.pos }
+func (n *binaryNode) position() Position  { return n.pos }
+func (n *callNode) position() Position    { return n.pos }
+func (n *listNode) position() Position    { return n.pos }
+
+// precedence precedência dos operadores binários
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, "<=": 4, ">": 4, ">=": 4, "in": 4,
+	"+": 5, "-": 5,
+	"*": 6, "/": 6, "%": 6,
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	depth  int
+	nodes  int
+}
+
+// parse constrói a árvore sintática de uma expressão
+func parse(source string) (node, error) {
+	if len(source) > MaxExpressionLength {
+		return nil, errorf(Position{Line: 1, Column: 1}, "expression longer than %d characters", MaxExpressionLength)
+	}
+
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, errorf(tok.pos, "unexpected %q", tok.text)
+	}
+
+	return root, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) count(pos Position) error {
+	p.nodes++
+	if p.nodes > MaxNodes {
+		return errorf(pos, "expression has more than %d nodes", MaxNodes)
+	}
+	return nil
+}
+
+// binaryOperator retorna o operador binário do token atual, se houver
+func (p *parser) binaryOperator() (string, bool) {
+	tok := p.peek()
+	if tok.kind == tokenOperator || (tok.kind == tokenIdent && tok.text == "in") {
+		if _, ok := precedence[tok.text]; ok {
+			return tok.text, true
+		}
+	}
+	return "", false
+}
+
+// parseExpression analisa operadores binários por precedence climbing
+func (p *parser) parseExpression(minPrecedence int) (node, error) {
+	p.depth++
+	defer func() { p.depth-- }()
+	if p.depth > MaxDepth {
+		return nil, errorf(p.peek().pos, "expression nested deeper than %d levels", MaxDepth)
+	}
+
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, ok := p.binaryOperator()
+		if !ok || precedence[op] <= minPrecedence {
+			return left, nil
+		}
+		tok := p.next()
+
+		right, err := p.parseExpression(precedence[op])
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.count(tok.pos); err != nil {
+			return nil, err
+		}
+		left = &binaryNode{pos: tok.pos, op: op, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	tok := p.peek()
+	if tok.kind == tokenOperator && (tok.text == "!" || tok.text == "-") {
+		p.next()
+		p.depth++
+		defer func() { p.depth-- }()
+		if p.depth > MaxDepth {
+			return nil, errorf(tok.pos, "expression nested deeper than %d levels", MaxDepth)
+		}
+
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.count(tok.pos); err != nil {
+			return nil, err
+		}
+		return &unaryNode{pos: tok.pos, op: tok.text, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+	if err := p.count(tok.pos); err != nil {
+		return nil, err
+	}
+
+	switch tok.kind {
+	case tokenNumber, tokenString:
+		return &literalNode{pos: tok.pos, value: tok.value}, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true":
+			return &literalNode{pos: tok.pos, value: true}, nil
+		case "false":
+			return &literalNode{pos: tok.pos, value: false}, nil
+		case "in":
+			return nil, errorf(tok.pos, "unexpected \"in\"")
+		}
+
+		if p.peek().kind == tokenLParen {
+			p.next()
+			args, err := p.parseList(tokenRParen)
+			if err != nil {
+				return nil, err
+			}
+			return &callNode{pos: tok.pos, name: tok.text, args: args}, nil
+		}
+		return &identNode{pos: tok.pos, path: tok.text}, nil
+
+	case tokenLParen:
+		inner, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, errorf(closing.pos, "expected \")\"")
+		}
+		return inner, nil
+
+	case tokenLBracket:
+		elements, err := p.parseList(tokenRBracket)
+		if err != nil {
+			return nil, err
+		}
+		return &listNode{pos: tok.pos, elements: elements}, nil
+
+	case tokenEOF:
+		return nil, errorf(tok.pos, "unexpected end of expression")
+
+	default:
+		return nil, errorf(tok.pos, "unexpected %q", tok.text)
+	}
+}
+
+// parseList analisa elementos separados por vírgula até o token de fechamento
+func (p *parser) parseList(closing tokenKind) ([]node, error) {
+	elements := make([]node, 0)
+
+	if p.peek().kind == closing {
+		p.next()
+		return elements, nil
+	}
+
+	for {
+		element, err := p.parseExpression(0)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, element)
+
+		tok := p.next()
+		switch tok.kind {
+		case tokenComma:
+			continue
+		case closing:
+			return elements, nil
+		default:
+			return nil, errorf(tok.pos, "expected \",\" or closing bracket")
+		}
+	}
+}
@@ -0,0 +1,489 @@
+package expr
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// Type tipo estático de um valor da linguagem
+type Type int
+
+const (
+	TypeNumber Type = iota + 1
+	TypeString
+	TypeBool
+	TypeStringList
+	TypeNumberList
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeNumber:
+		return "number"
+	case TypeString:
+		return "string"
+	case TypeBool:
+		return "bool"
+	case TypeStringList:
+		return "list<string>"
+	case TypeNumberList:
+		return "list<number>"
+	default:
+		return "unknown"
+	}
+}
+
+// listOf retorna o tipo de lista cujos elementos são do tipo t
+func listOf(t Type) (Type, bool) {
+	switch t {
+	case TypeString:
+		return TypeStringList, true
+	case TypeNumber:
+		return TypeNumberList, true
+	default:
+		return 0, false
+	}
+}
+
+// Function função disponível nas expressões
+type Function struct {
+	Params []Type
+	Result Type
+	// Validate é chamado na compilação com os argumentos literais
+	// (nil para argumentos não literais)
+	Validate func(literals []interface{}) error
+	Call     func(args []interface{}) (interface{}, error)
+}
+
+// Schema variáveis e funções que uma expressão pode usar
+type Schema struct {
+	Variables map[string]Type
+	Functions map[string]Function
+}
+
+// Program expressão compilada e validada, pronta para avaliação
+type Program struct {
+	source    string
+	root      node
+	schema    Schema
+	variables []string
+}
+
+// Compile analisa e valida uma expressão booleana contra o schema
+func Compile(source string, schema Schema) (*Program, error) {
+	root, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{schema: schema, variables: make(map[string]bool)}
+	resultType, err := c.check(root)
+	if err != nil {
+		return nil, err
+	}
+	if resultType != TypeBool {
+		return nil, errorf(root.position(), "expression must be bool, got %s", resultType)
+	}
+
+	variables := make([]string, 0, len(c.variables))
+	for name := range c.variables {
+		variables = append(variables, name)
+	}
+	sort.Strings(variables)
+
+	return &Program{source: source, root: root, schema: schema, variables: variables}, nil
+}
+
+// Source retorna o texto original da expressão
+func (p *Program) Source() string { return p.source }
+
+// Variables retorna as variáveis referenciadas pela expressão
+func (p *Program) Variables() []string { return p.variables }
+
+// Eval avalia a expressão com os valores das variáveis
+func (p *Program) Eval(env map[string]interface{}) (bool, error) {
+	value, err := p.eval(p.root, env)
+	if err != nil {
+		return false, err
+	}
+	return value.(bool), nil
+}
+
+// checker verificador de tipos
+type checker struct {
+	schema    Schema
+	variables map[string]bool
+}
+
+func (c *checker) check(n node) (Type, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		switch n.value.(type) {
+		case float64:
+			return TypeNumber, nil
+		case string:
+			return TypeString, nil
+		default:
+			return TypeBool, nil
+		}
+
+	case *identNode:
+		t, exists := c.schema.Variables[n.path]
+		if !exists {
+			return 0, errorf(n.pos, "unknown variable %q", n.path)
+		}
+		c.variables[n.path] = true
+		return t, nil
+
+	case *listNode:
+		if len(n.elements) == 0 {
+			return 0, errorf(n.pos, "empty list")
+		}
+		var elementType Type
+		for i, element := range n.elements {
+			t, err := c.check(element)
+			if err != nil {
+				return 0, err
+			}
+			if i == 0 {
+				elementType = t
+			} else if t != elementType {
+				return 0, errorf(element.position(), "list mixes %s and %s", elementType, t)
+			}
+		}
+		listType, ok := listOf(elementType)
+		if !ok {
+			return 0, errorf(n.pos, "lists of %s are not supported", elementType)
+		}
+		return listType, nil
+
+	case *unaryNode:
+		t, err := c.check(n.operand)
+		if err != nil {
+			return 0, err
+		}
+		if n.op == "!" && t != TypeBool {
+			return 0, errorf(n.pos, "operator ! expects bool, got %s", t)
+		}
+		if n.op == "-" && t != TypeNumber {
+			return 0, errorf(n.pos, "operator - expects number, got %s", t)
+		}
+		return t, nil
+
+	case *binaryNode:
+		left, err := c.check(n.left)
+		if err != nil {
+			return 0, err
+		}
+		right, err := c.check(n.right)
+		if err != nil {
+			return 0, err
+		}
+		return c.checkBinary(n, left, right)
+
+	case *callNode:
+		function, exists := c.schema.Functions[n.name]
+		if !exists {
+			return 0, errorf(n.pos, "unknown function %q", n.name)
+		}
+		if len(n.args) != len(function.Params) {
+			return 0, errorf(n.pos, "%s expects %d arguments, got %d", n.name, len(function.Params), len(n.args))
+		}
+		literals := make([]interface{}, len(n.args))
+		for i, arg := range n.args {
+			t, err := c.check(arg)
+			if err != nil {
+				return 0, err
+			}
+			if t != function.Params[i] {
+				return 0, errorf(arg.position(), "argument %d of %s must be %s, got %s", i+1, n.name, function.Params[i], t)
+			}
+			if literal, ok := arg.(*literalNode); ok {
+				literals[i] = literal.value
+			}
+		}
+		if function.Validate != nil {
+			if err := function.Validate(literals); err != nil {
+				return 0, errorf(n.pos, "%s: %v", n.name, err)
+			}
+		}
+		return function.Result, nil
+	}
+
+	return 0, errorf(n.position(), "invalid expression")
+}
+
+func (c *checker) checkBinary(n *binaryNode, left, right Type) (Type, error) {
+	switch n.op {
+	case "&&", "||":
+		if left != TypeBool || right != TypeBool {
+			return 0, errorf(n.pos, "operator %s expects bool operands, got %s and %s", n.op, left, right)
+		}
+		return TypeBool, nil
+	case "==", "!=":
+		if left != right {
+			return 0, errorf(n.pos, "cannot compare %s with %s", left, right)
+		}
+		// Listas não são comparáveis; use in para testar pertinência
+		if left == TypeStringList || left == TypeNumberList {
+			return 0, errorf(n.pos, "operator %s does not accept %s operands", n.op, left)
+		}
+		return TypeBool, nil
+	case "<", "<=", ">", ">=":
+		if left != TypeNumber || right != TypeNumber {
+			return 0, errorf(n.pos, "operator %s expects numbers, got %s and %s", n.op, left, right)
+		}
+		return TypeBool, nil
+	case "in":
+		listType, ok := listOf(left)
+		if !ok || right != listType {
+			return 0, errorf(n.pos, "operator in expects %s in a list of the same type, got %s", left, right)
+		}
+		return TypeBool, nil
+	case "+":
+		if left == TypeString && right == TypeString {
+			return TypeString, nil
+		}
+		fallthrough
+	default:
+		if left != TypeNumber || right != TypeNumber {
+			return 0, errorf(n.pos, "operator %s expects numbers, got %s and %s", n.op, left, right)
+		}
+		return TypeNumber, nil
+	}
+}
+
+// eval avalia um nó; os tipos já foram verificados na compilação
+func (p *Program) eval(n node, env map[string]interface{}) (interface{}, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		return n.value, nil
+
+	case *identNode:
+		value, exists := env[n.path]
+		if !exists {
+			return nil, errorf(n.pos, "variable %q not provided", n.path)
+		}
+		return normalize(value), nil
+
+	case *listNode:
+		values := make([]interface{}, len(n.elements))
+		for i, element := range n.elements {
+			value, err := p.eval(element, env)
+			if err != nil {
+				return nil, err
+			}
+			values[i] = value
+		}
+		return values, nil
+
+	case *unaryNode:
+		value, err := p.eval(n.operand, env)
+		if err != nil {
+			return nil, err
+		}
+		if n.op == "!" {
+			return !value.(bool), nil
+		}
+		return -value.(float64), nil
+
+	case *binaryNode:
+		return p.evalBinary(n, env)
+
+	case *callNode:
+		function := p.schema.Functions[n.name]
+		args := make([]interface{}, len(n.args))
+		for i, arg := range n.args {
+			value, err := p.eval(arg, env)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = value
+		}
+		result, err := function.Call(args)
+		if err != nil {
+			return nil, errorf(n.pos, "%s: %v", n.name, err)
+		}
+		return normalize(result), nil
+	}
+
+	return nil, errorf(n.position(), "invalid expression")
+}
+
+func (p *Program) evalBinary(n *binaryNode, env map[string]interface{}) (interface{}, error) {
+	left, err := p.eval(n.left, env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Curto-circuito dos operadores lógicos
+	switch n.op {
+	case "&&":
+		if !left.(bool) {
+			return false, nil
+		}
+		right, err := p.eval(n.right, env)
+		if err != nil {
+			return nil, err
+		}
+		return right.(bool), nil
+	case "||":
+		if left.(bool) {
+			return true, nil
+		}
+		right, err := p.eval(n.right, env)
+		if err != nil {
+			return nil, err
+		}
+		return right.(bool), nil
+	}
+
+	right, err := p.eval(n.right, env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return equal(left, right), nil
+	case "!=":
+		return !equal(left, right), nil
+	case "in":
+		for _, element := range toSlice(right) {
+			if equal(left, element) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	if s, ok := left.(string); ok {
+		return s + right.(string), nil
+	}
+
+	a, b := left.(float64), right.(float64)
+	switch n.op {
+	case "<":
+		return a < b, nil
+	case "<=":
+		return a <= b, nil
+	case ">":
+		return a > b, nil
+	case ">=":
+		return a >= b, nil
+	case "+":
+		return a + b, nil
+	case "-":
+		return a - b, nil
+	case "*":
+		return a * b, nil
+	case "/":
+		if b == 0 {
+			return nil, errorf(n.pos, "division by zero")
+		}
+		return a / b, nil
+	case "%":
+		if b == 0 {
+			return nil, errorf(n.pos, "division by zero")
+		}
+		return math.Mod(a, b), nil
+	}
+
+	return nil, errorf(n.pos, "invalid operator %s", n.op)
+}
+
+// normalize converte os tipos Go do ambiente para os tipos da linguagem
+func normalize(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float32:
+		return float64(v)
+	case []string:
+		values := make([]interface{}, len(v))
+		for i, s := range v {
+			values[i] = s
+		}
+		return values
+	case []float64:
+		values := make([]interface{}, len(v))
+		for i, f := range v {
+			values[i] = f
+		}
+		return values
+	default:
+		return value
+	}
+}
+
+func toSlice(value interface{}) []interface{} {
+	if values, ok := value.([]interface{}); ok {
+		return values
+	}
+	return nil
+}
+
+func equal(a, b interface{}) bool {
+	return a == b
+}
+
+// Builtins funções puras disponíveis em todas as expressões
+func Builtins() map[string]Function {
+	return map[string]Function{
+		"abs": {
+			Params: []Type{TypeNumber},
+			Result: TypeNumber,
+			Call:   func(args []interface{}) (interface{}, error) { return math.Abs(args[0].(float64)), nil },
+		},
+		"min": {
+			Params: []Type{TypeNumber, TypeNumber},
+			Result: TypeNumber,
+			Call: func(args []interface{}) (interface{}, error) {
+				return math.Min(args[0].(float64), args[1].(float64)), nil
+			},
+		},
+		"max": {
+			Params: []Type{TypeNumber, TypeNumber},
+			Result: TypeNumber,
+			Call: func(args []interface{}) (interface{}, error) {
+				return math.Max(args[0].(float64), args[1].(float64)), nil
+			},
+		},
+		"lower": {
+			Params: []Type{TypeString},
+			Result: TypeString,
+			Call:   func(args []interface{}) (interface{}, error) { return strings.ToLower(args[0].(string)), nil },
+		},
+		"upper": {
+			Params: []Type{TypeString},
+			Result: TypeString,
+			Call:   func(args []interface{}) (interface{}, error) { return strings.ToUpper(args[0].(string)), nil },
+		},
+		"contains": {
+			Params: []Type{TypeString, TypeString},
+			Result: TypeBool,
+			Call: func(args []interface{}) (interface{}, error) {
+				return strings.Contains(args[0].(string), args[1].(string)), nil
+			},
+		},
+		"starts_with": {
+			Params: []Type{TypeString, TypeString},
+			Result: TypeBool,
+			Call: func(args []interface{}) (interface{}, error) {
+				return strings.HasPrefix(args[0].(string), args[1].(string)), nil
+			},
+		},
+	}
+}
+
+// Functions combina funções extras às funções embutidas
+func Functions(extra map[string]Function) map[string]Function {
+	functions := Builtins()
+	for name, function := range extra {
+		functions[name] = function
+	}
+	return functions
+}
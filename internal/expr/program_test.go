@@ -0,0 +1,164 @@
+package expr
+
+import (
+	"strings"
+	"testing"
+)
+
+func testSchema() Schema {
+	return Schema{
+		Variables: map[string]Type{
+			"tx.amount":   TypeNumber,
+			"tx.country":  TypeString,
+			"tx.online":   TypeBool,
+			"user.tags":   TypeStringList,
+			"user.scores": TypeNumberList,
+		},
+		Functions: Builtins(),
+	}
+}
+
+func testEnv() map[string]interface{} {
+	return map[string]interface{}{
+		"tx.amount":   1500,
+		"tx.country":  "BR",
+		"tx.online":   true,
+		"user.tags":   []string{"vip", "new"},
+		"user.scores": []float64{0.2, 0.8},
+	}
+}
+
+func TestCompileAndEval(t *testing.T) {
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{`tx.amount > 1000`, true},
+		{`tx.amount >= 1500 && tx.amount < 1500.01`, true},
+		{`tx.amount == 1_500`, true},
+		{`tx.country == "BR" || tx.amount > 1_000_000_000`, true},
+		{`tx.country != 'BR'`, false},
+		{`!tx.online`, false},
+		{`-tx.amount < 0`, true},
+		{`tx.amount * 2 - 1000 == 2000`, true},
+		{`tx.amount % 1000 == 500`, true},
+		{`1 + 2 * 3 == 7`, true},
+		{`(1 + 2) * 3 == 9`, true},
+		{`tx.country in ["BR", "AR"]`, true},
+		{`"vip" in user.tags`, true},
+		{`0.5 in user.scores`, false},
+		{`lower("ABC") == "abc" && upper(tx.country) == "BR"`, true},
+		{`contains(tx.country + "-SP", "-") && starts_with(tx.country, "B")`, true},
+		{`abs(-2) == max(1, 2) && min(1, 2) == 1`, true},
+		// Curto-circuito: o lado direito dividiria por zero
+		{`false && 1 / 0 > 0`, false},
+		{`true || 1 / 0 > 0`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			program, err := Compile(tt.source, testSchema())
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			got, err := program.Eval(testEnv())
+			if err != nil {
+				t.Fatalf("Eval: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		message string
+	}{
+		{"empty", ``, "unexpected end of expression"},
+		{"unterminated string", `tx.country == "BR`, "unterminated string"},
+		{"unexpected character", `tx.amount > 1 ; 2`, "unexpected character"},
+		{"trailing token", `tx.amount > 1 2`, "unexpected"},
+		{"unclosed paren", `(tx.amount > 1`, `expected ")"`},
+		{"invalid identifier", `tx..amount > 1`, "invalid identifier"},
+		{"unknown variable", `tx.merchant == "x"`, "unknown variable"},
+		{"unknown function", `sqrt(tx.amount) > 1`, "unknown function"},
+		{"wrong arity", `abs(1, 2) > 0`, "expects 1 arguments"},
+		{"wrong argument type", `lower(tx.amount) == "x"`, "argument 1 of lower must be string"},
+		{"not bool", `tx.amount + 1`, "expression must be bool"},
+		{"mixed comparison", `tx.amount == "1500"`, "cannot compare number with string"},
+		{"ordering strings", `tx.country < "C"`, "expects numbers"},
+		{"logical on numbers", `tx.amount && true`, "expects bool operands"},
+		{"in wrong element type", `tx.amount in user.tags`, "operator in expects"},
+		{"empty list", `tx.country in []`, "empty list"},
+		{"list equality", `["a"] == ["a"]`, "does not accept list<string> operands"},
+		{"list inequality", `user.scores != [0.2, 0.8]`, "does not accept list<number> operands"},
+		{"too long", strings.Repeat("1", MaxExpressionLength+1), "longer than"},
+		{"too deep", strings.Repeat("(", MaxDepth+1) + "true" + strings.Repeat(")", MaxDepth+1), "nested deeper"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source, testSchema())
+			if err == nil {
+				t.Fatalf("Compile(%q) succeeded, want error containing %q", tt.source, tt.message)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error %q does not contain %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestCompileErrorPosition(t *testing.T) {
+	_, err := Compile("tx.amount > 1 &&\n  tx.country", testSchema())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	exprErr, ok := err.(*Error)
+	if !ok {
+		t.Fatalf("error is %T, want *Error", err)
+	}
+	if exprErr.Pos.Line != 1 || exprErr.Pos.Column != 15 {
+		t.Errorf("position = %s, want line 1, column 15", exprErr.Pos)
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		env     map[string]interface{}
+		message string
+	}{
+		{"division by zero", `tx.amount / 0 > 1`, testEnv(), "division by zero"},
+		{"modulo by zero", `tx.amount % 0 > 1`, testEnv(), "division by zero"},
+		{"missing variable", `tx.amount > 1`, map[string]interface{}{}, `variable "tx.amount" not provided`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.source, testSchema())
+			if err != nil {
+				t.Fatalf("Compile: %v", err)
+			}
+			if _, err := program.Eval(tt.env); err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Eval error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	program, err := Compile(`tx.country == "BR" && tx.amount > 1 && tx.amount < 10`, testSchema())
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	got := strings.Join(program.Variables(), ",")
+	if got != "tx.amount,tx.country" {
+		t.Errorf("Variables() = %s", got)
+	}
+}
@@ -0,0 +1,246 @@
+package geo
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	// Base de fusos embutida no binário: não depende do tzdata do servidor
+	_ "time/tzdata"
+)
+
+// Origem do fuso horário resolvido
+const (
+	TimezoneSourceCountry     = "country"
+	TimezoneSourceCoordinates = "coordinates"
+	TimezoneSourceTimestamp   = "timestamp"
+)
+
+// zoneBand fuso de uma faixa do país. Faixas são verificadas em ordem e a
+// primeira que contém as coordenadas vence.
+type zoneBand struct {
+	zone         string
+	maxLongitude float64
+	minLatitude  float64
+	maxLatitude  float64
+}
+
+// band fuso válido a oeste de maxLongitude
+func band(zone string, maxLongitude float64) zoneBand {
+	return zoneBand{zone: zone, maxLongitude: maxLongitude, minLatitude: -90, maxLatitude: 90}
+}
+
+// area fuso válido a oeste de maxLongitude, entre as latitudes informadas
+func area(zone string, maxLongitude, minLatitude, maxLatitude float64) zoneBand {
+	return zoneBand{zone: zone, maxLongitude: maxLongitude, minLatitude: minLatitude, maxLatitude: maxLatitude}
+}
+
+// zone fuso único do país
+func zone(name string) []zoneBand {
+	return []zoneBand{band(name, 180)}
+}
+
+// countryZones fusos IANA por país (ISO 3166-1 alfa-2). Países com mais de um
+// fuso são divididos em faixas aproximadas de longitude e latitude.
+var countryZones = map[string][]zoneBand{
+	// Américas
+	"BR": {
+		band("America/Rio_Branco", -67.5),
+		area("America/Manaus", -52.5, -24, 6),
+		band("America/Sao_Paulo", -33),
+		band("America/Noronha", 180),
+	},
+	"US": {
+		area("Pacific/Honolulu", -154, 18, 23),
+		area("America/Anchorage", -129, 51, 72),
+		band("America/Los_Angeles", -114.5),
+		band("America/Denver", -102),
+		band("America/Chicago", -86.5),
+		band("America/New_York", 180),
+	},
+	"CA": {
+		band("America/Vancouver", -120),
+		band("America/Edmonton", -110),
+		band("America/Winnipeg", -90),
+		band("America/Toronto", -64),
+		band("America/Halifax", -57),
+		band("America/St_Johns", 180),
+	},
+	"MX": {
+		band("America/Tijuana", -114.5),
+		band("America/Mazatlan", -106),
+		band("America/Mexico_City", -89),
+		band("America/Cancun", 180),
+	},
+	"AR": zone("America/Argentina/Buenos_Aires"),
+	"BO": zone("America/La_Paz"),
+	"CL": zone("America/Santiago"),
+	"CO": zone("America/Bogota"),
+	"CR": zone("America/Costa_Rica"),
+	"CU": zone("America/Havana"),
+	"DO": zone("America/Santo_Domingo"),
+	"EC": zone("America/Guayaquil"),
+	"GT": zone("America/Guatemala"),
+	"PA": zone("America/Panama"),
+	"PE": zone("America/Lima"),
+	"PY": zone("America/Asuncion"),
+	"UY": zone("America/Montevideo"),
+	"VE": zone("America/Caracas"),
+
+	// Europa
+	"AT": zone("Europe/Vienna"),
+	"BE": zone("Europe/Brussels"),
+	"CH": zone("Europe/Zurich"),
+	"CZ": zone("Europe/Prague"),
+	"DE": zone("Europe/Berlin"),
+	"DK": zone("Europe/Copenhagen"),
+	"ES": {
+		band("Atlantic/Canary", -13),
+		band("Europe/Madrid", 180),
+	},
+	"FI": zone("Europe/Helsinki"),
+	"FR": zone("Europe/Paris"),
+	"GB": zone("Europe/London"),
+	"GR": zone("Europe/Athens"),
+	"HU": zone("Europe/Budapest"),
+	"IE": zone("Europe/Dublin"),
+	"IT": zone("Europe/Rome"),
+	"NL": zone("Europe/Amsterdam"),
+	"NO": zone("Europe/Oslo"),
+	"PL": zone("Europe/Warsaw"),
+	"PT": {
+		band("Atlantic/Azores", -20),
+		band("Europe/Lisbon", 180),
+	},
+	"RO": zone("Europe/Bucharest"),
+	"RU": {
+		band("Europe/Kaliningrad", 23),
+		band("Europe/Moscow", 50),
+		band("Europe/Samara", 55),
+		band("Asia/Yekaterinburg", 68),
+		band("Asia/Omsk", 80),
+		band("Asia/Krasnoyarsk", 97),
+		band("Asia/Irkutsk", 112),
+		band("Asia/Yakutsk", 130),
+		band("Asia/Vladivostok", 142),
+		band("Asia/Magadan", 156),
+		band("Asia/Kamchatka", 180),
+	},
+	"SE": zone("Europe/Stockholm"),
+	"TR": zone("Europe/Istanbul"),
+	"UA": zone("Europe/Kyiv"),
+
+	// Ásia e Oceania
+	"AE": zone("Asia/Dubai"),
+	"AU": {
+		band("Australia/Perth", 129),
+		area("Australia/Darwin", 138, -26, 0),
+		band("Australia/Adelaide", 141),
+		area("Australia/Brisbane", 154, -29, 0),
+		band("Australia/Sydney", 180),
+	},
+	"BD": zone("Asia/Dhaka"),
+	"CN": zone("Asia/Shanghai"),
+	"HK": zone("Asia/Hong_Kong"),
+	"ID": {
+		band("Asia/Jakarta", 115),
+		band("Asia/Makassar", 127),
+		band("Asia/Jayapura", 180),
+	},
+	"IL": zone("Asia/Jerusalem"),
+	"IN": zone("Asia/Kolkata"),
+	"JP": zone("Asia/Tokyo"),
+	"KR": zone("Asia/Seoul"),
+	"MY": zone("Asia/Kuala_Lumpur"),
+	"NZ": zone("Pacific/Auckland"),
+	"PH": zone("Asia/Manila"),
+	"PK": zone("Asia/Karachi"),
+	"SA": zone("Asia/Riyadh"),
+	"SG": zone("Asia/Singapore"),
+	"TH": zone("Asia/Bangkok"),
+	"TW": zone("Asia/Taipei"),
+	"VN": zone("Asia/Ho_Chi_Minh"),
+
+	// África
+	"AO": zone("Africa/Luanda"),
+	"EG": zone("Africa/Cairo"),
+	"KE": zone("Africa/Nairobi"),
+	"MA": zone("Africa/Casablanca"),
+	"MZ": zone("Africa/Maputo"),
+	"NG": zone("Africa/Lagos"),
+	"ZA": zone("Africa/Johannesburg"),
+}
+
+// defaultZones fuso usado sem coordenadas em países com mais de um fuso
+var defaultZones = map[string]string{
+	"AU": "Australia/Sydney",
+	"BR": "America/Sao_Paulo",
+	"CA": "America/Toronto",
+	"ES": "Europe/Madrid",
+	"ID": "Asia/Jakarta",
+	"MX": "America/Mexico_City",
+	"PT": "Europe/Lisbon",
+	"RU": "Europe/Moscow",
+	"US": "America/New_York",
+}
+
+var locationCache sync.Map
+
+// Timezone resolve o fuso de uma localização. O país tem precedência e as
+// coordenadas escolhem a faixa dentro dele; sem país conhecido, usa o fuso
+// solar das coordenadas. Coordenadas (0, 0) são tratadas como ausentes.
+func Timezone(country string, latitude, longitude float64) (*time.Location, string, bool) {
+	hasCoordinates := latitude != 0 || longitude != 0
+
+	country = strings.ToUpper(country)
+	if bands, exists := countryZones[country]; exists {
+		name, ok := defaultZones[country]
+		if !ok {
+			name = bands[0].zone
+		}
+		source := TimezoneSourceCountry
+		if hasCoordinates {
+			for _, b := range bands {
+				if longitude < b.maxLongitude && latitude >= b.minLatitude && latitude <= b.maxLatitude {
+					name = b.zone
+					source = TimezoneSourceCoordinates
+					break
+				}
+			}
+		}
+		if location, ok := loadLocation(name); ok {
+			return location, source, true
+		}
+	}
+
+	if hasCoordinates {
+		offset := int(math.Round(longitude/15)) * 3600
+		return time.FixedZone("", offset), TimezoneSourceCoordinates, true
+	}
+
+	return nil, "", false
+}
+
+// LocalTime converte o instante para o horário local da localização. Se o
+// fuso não puder ser resolvido, mantém o fuso do próprio timestamp.
+func LocalTime(t time.Time, country string, latitude, longitude float64) (time.Time, string) {
+	location, source, ok := Timezone(country, latitude, longitude)
+	if !ok {
+		return t, TimezoneSourceTimestamp
+	}
+	return t.In(location), source
+}
+
+func loadLocation(name string) (*time.Location, bool) {
+	if cached, ok := locationCache.Load(name); ok {
+		return cached.(*time.Location), true
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, false
+	}
+	locationCache.Store(name, location)
+	return location, true
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 	
@@ -24,16 +26,22 @@ func NewFraudHandler(fraudService *services.FraudDetectionService) *FraudHandler
 
 // AnalyzeTransactionRequest request para análise de transação
 type AnalyzeTransactionRequest struct {
-	TransactionID string              `json:"transaction_id,omitempty"`
-	UserID        string              `json:"user_id" binding:"required"`
-	Amount        float64             `json:"amount" binding:"required,gt=0"`
-	Currency      string              `json:"currency" binding:"required"`
-	Merchant      string              `json:"merchant" binding:"required"`
-	Location      models.Location     `json:"location" binding:"required"`
-	DeviceInfo    *models.DeviceInfo  `json:"device_info,omitempty"`
-	CardLast4     string              `json:"card_last4,omitempty"`
-	CardType      string              `json:"card_type,omitempty"`
-	Description   string              `json:"description,omitempty"`
+	TransactionID   string              `json:"transaction_id,omitempty"`
+	UserID          string              `json:"user_id" binding:"required"`
+	Amount          json.Number         `json:"amount" binding:"required"` // decimal nas casas da moeda
+	Currency        string              `json:"currency" binding:"required"`
+	Merchant        string              `json:"merchant" binding:"required_without=PIX"`
+	Location        models.Location     `json:"location" binding:"required"`
+	DeviceInfo      *models.DeviceInfo  `json:"device_info,omitempty"`
+	CardBIN         string              `json:"card_bin,omitempty" binding:"omitempty,numeric,min=6,max=8"`
+	CardLast4       string              `json:"card_last4,omitempty"`
+	CardFingerprint string              `json:"card_fingerprint,omitempty"`
+	CardType        string              `json:"card_type,omitempty"`
+	CardCountry     string              `json:"card_country,omitempty"`
+	IPCountry       string              `json:"ip_country,omitempty"`
+	PIX             *models.PIXTransfer `json:"pix,omitempty"` // recebedor, em transferências PIX
+	Description     string              `json:"description,omitempty"`
+	Timestamp       *time.Time          `json:"timestamp,omitempty"`
 }
 
 // AnalyzeTransaction analisa uma transação
@@ -46,6 +54,7 @@ type AnalyzeTransactionRequest struct {
 // @Success 200 {object} models.FraudAnalysisResult
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
 // @Router /api/v1/transaction/analyze [post]
 func (h *FraudHandler) AnalyzeTransaction(c *gin.Context) {
 	var req AnalyzeTransactionRequest
@@ -58,6 +67,13 @@ func (h *FraudHandler) AnalyzeTransaction(c *gin.Context) {
 		return
 	}
 	
+	// Valor exato, sem passar por float; moeda validada pelo serviço
+	amount, err := services.ParseAmount(req.Amount.String(), req.Currency)
+	if err != nil {
+		respondServiceError(c, "Invalid request", err)
+		return
+	}
+	
 	// Gera ID da transação se não fornecido
 	transactionID := req.TransactionID
 	if transactionID == "" {
@@ -66,29 +82,35 @@ func (h *FraudHandler) AnalyzeTransaction(c *gin.Context) {
 	
 	// Cria objeto de transação
 	transaction := &models.Transaction{
-		ID:          transactionID,
-		UserID:      req.UserID,
-		Amount:      req.Amount,
-		Currency:    req.Currency,
-		Merchant:    req.Merchant,
-		Location:    req.Location,
-		Timestamp:   time.Now(),
-		CardLast4:   req.CardLast4,
-		CardType:    req.CardType,
-		Description: req.Description,
+		ID:              transactionID,
+		UserID:          req.UserID,
+		Amount:          amount,
+		Currency:        req.Currency,
+		Merchant:        req.Merchant,
+		Location:        req.Location,
+		CardBIN:         req.CardBIN,
+		CardLast4:       req.CardLast4,
+		CardFingerprint: req.CardFingerprint,
+		CardType:        req.CardType,
+		CardCountry:     req.CardCountry,
+		IPCountry:       req.IPCountry,
+		PIX:             req.PIX,
+		Description:     req.Description,
 	}
 	
 	if req.DeviceInfo != nil {
 		transaction.DeviceInfo = *req.DeviceInfo
 	}
 	
+	// Horário do evento informado pelo cliente; validado pelo serviço
+	if req.Timestamp != nil {
+		transaction.Timestamp = *req.Timestamp
+	}
+	
 	// Analisa a transação
-	result, err := h.fraudService.AnalyzeTransaction(transaction)
+	result, err := h.fraudService.AnalyzeTransaction(c.Request.Context(), transaction)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Analysis failed",
-			Message: err.Error(),
-		})
+		respondServiceError(c, "Analysis failed", err)
 		return
 	}
 	
@@ -102,23 +124,45 @@ func (h *FraudHandler) AnalyzeTransaction(c *gin.Context) {
 // @Produce json
 // @Param user_id path string true "User ID"
 // @Success 200 {object} services.TransactionAnalytics
+// @Failure 404 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
 // @Router /api/v1/analytics/{user_id} [get]
 func (h *FraudHandler) GetAnalytics(c *gin.Context) {
 	userID := c.Param("user_id")
 	
 	analytics, err := h.fraudService.GetTransactionAnalytics(userID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{
-			Error:   "Failed to get analytics",
-			Message: err.Error(),
-		})
+		respondServiceError(c, "Failed to get analytics", err)
 		return
 	}
 	
 	c.JSON(http.StatusOK, analytics)
 }
 
+// GetPeerGroupAnalytics retorna estatísticas de grupos de pares
+// @Summary Retorna estatísticas agregadas de grupos de pares
+// @Description Lista média e desvio dos valores por grupo de pares (country, card_type, device_type, merchant)
+// @Tags analytics
+// @Produce json
+// @Param dimension path string true "Dimensão do grupo"
+// @Success 200 {array} models.PeerGroupStats
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/analytics/peers/{dimension} [get]
+func (h *FraudHandler) GetPeerGroupAnalytics(c *gin.Context) {
+	dimension := c.Param("dimension")
+	
+	stats, err := h.fraudService.GetPeerGroupAnalytics(dimension)
+	if err != nil {
+		respondServiceError(c, "Failed to get peer group analytics", err)
+		return
+	}
+	
+	c.JSON(http.StatusOK, stats)
+}
+
 // HealthCheck verifica o status da API
 // @Summary Health check
 // @Description Verifica se a API está funcionando
@@ -135,6 +179,30 @@ func (h *FraudHandler) HealthCheck(c *gin.Context) {
 	})
 }
 
+// respondServiceError mapeia os erros do serviço para o status HTTP:
+// entrada inválida (inclusive timestamp fora da janela) 400, usuário, aviso ou marcação desconhecidos 404 e store indisponível 503
+func respondServiceError(c *gin.Context, message string, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, services.ErrInvalidPeerDimension), errors.Is(err, services.ErrInvalidTimestamp),
+		errors.Is(err, services.ErrInvalidCard), errors.Is(err, services.ErrInvalidTravelNotice),
+		errors.Is(err, services.ErrInvalidCurrency), errors.Is(err, models.ErrInvalidAmount),
+		errors.Is(err, services.ErrInvalidPIX), errors.Is(err, services.ErrInvalidPIXLimits),
+		errors.Is(err, services.ErrInvalidMuleFlag):
+		status = http.StatusBadRequest
+	case errors.Is(err, services.ErrProfileNotFound), errors.Is(err, services.ErrTravelNoticeNotFound),
+		errors.Is(err, services.ErrMuleFlagNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, services.ErrStoreUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+	
+	c.JSON(status, ErrorResponse{
+		Error:   message,
+		Message: err.Error(),
+	})
+}
+
 // ErrorResponse resposta de erro padrão
 type ErrorResponse struct {
 	Error   string `json:"error"`
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/anti-fraud-golang/internal/models"
+	"github.com/anti-fraud-golang/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// PIXHandler handler para os limites PIX dos clientes e as contas laranjas
+type PIXHandler struct {
+	fraudService *services.FraudDetectionService
+}
+
+// NewPIXHandler cria uma nova instância do handler
+func NewPIXHandler(fraudService *services.FraudDetectionService) *PIXHandler {
+	return &PIXHandler{
+		fraudService: fraudService,
+	}
+}
+
+// PIXLimitsRequest request para configuração dos limites PIX, em reais.
+// Limite diurno zero remove o limite do período.
+type PIXLimitsRequest struct {
+	DaytimeLimit   json.Number `json:"daytime_limit" binding:"required"`
+	NighttimeLimit json.Number `json:"nighttime_limit" binding:"required"`
+	NightStartHour int         `json:"night_start_hour" binding:"required,oneof=20 22"`
+}
+
+// MuleFlagRequest request para marcação de chave ou conta como laranja
+type MuleFlagRequest struct {
+	Type             string `json:"type" binding:"required,oneof=key account"`
+	KeyType          string `json:"key_type,omitempty"`
+	Key              string `json:"key,omitempty"`
+	RecipientISPB    string `json:"recipient_ispb,omitempty"`
+	RecipientBranch  string `json:"recipient_branch,omitempty"`
+	RecipientAccount string `json:"recipient_account,omitempty"`
+	Reason           string `json:"reason,omitempty"`
+}
+
+// GetPIXLimits retorna os limites PIX em vigor do usuário
+// @Summary Retorna os limites PIX do usuário
+// @Description Limites diurno e noturno em vigor e o aumento pendente, se houver
+// @Tags pix
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} models.PIXLimits
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/users/{user_id}/pix-limits [get]
+func (h *PIXHandler) GetPIXLimits(c *gin.Context) {
+	limits, err := h.fraudService.GetPIXLimits(c.Param("user_id"))
+	if err != nil {
+		respondServiceError(c, "Failed to get pix limits", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// SetPIXLimits configura os limites PIX do usuário
+// @Summary Configura os limites PIX do usuário
+// @Description Reduções valem na hora; aumentos ficam pendentes por 24h
+// @Tags pix
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param limits body PIXLimitsRequest true "Limites"
+// @Success 200 {object} models.PIXLimits
+// @Failure 400 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/users/{user_id}/pix-limits [put]
+func (h *PIXHandler) SetPIXLimits(c *gin.Context) {
+	var req PIXLimitsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	daytime, err := models.ParseMoney(req.DaytimeLimit.String(), "BRL")
+	if err != nil {
+		respondServiceError(c, "Invalid request", err)
+		return
+	}
+	nighttime, err := models.ParseMoney(req.NighttimeLimit.String(), "BRL")
+	if err != nil {
+		respondServiceError(c, "Invalid request", err)
+		return
+	}
+
+	limits, err := h.fraudService.SetPIXLimits(&models.PIXLimits{
+		UserID:     c.Param("user_id"),
+		Daytime:    daytime,
+		Nighttime:  nighttime,
+		NightStart: req.NightStartHour,
+	})
+	if err != nil {
+		respondServiceError(c, "Failed to set pix limits", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, limits)
+}
+
+// FlagMule marca uma chave ou conta recebedora como laranja
+// @Summary Marca uma chave ou conta PIX como laranja
+// @Description Tipo "key" exige key_type e key; tipo "account", recipient_ispb e recipient_account
+// @Tags pix
+// @Accept json
+// @Produce json
+// @Param flag body MuleFlagRequest true "Marcação"
+// @Success 201 {object} models.MuleFlag
+// @Failure 400 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/pix/mules [post]
+func (h *PIXHandler) FlagMule(c *gin.Context) {
+	var req MuleFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	flag, err := h.fraudService.FlagMule(req.Type, models.PIXTransfer{
+		KeyType:          req.KeyType,
+		Key:              req.Key,
+		RecipientISPB:    req.RecipientISPB,
+		RecipientBranch:  req.RecipientBranch,
+		RecipientAccount: req.RecipientAccount,
+	}, req.Reason)
+	if err != nil {
+		respondServiceError(c, "Failed to flag mule", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, flag)
+}
+
+// ListMules lista as chaves e contas marcadas como laranjas
+// @Summary Lista as marcações de contas laranjas
+// @Tags pix
+// @Produce json
+// @Success 200 {array} models.MuleFlag
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/pix/mules [get]
+func (h *PIXHandler) ListMules(c *gin.Context) {
+	flags, err := h.fraudService.ListMules()
+	if err != nil {
+		respondServiceError(c, "Failed to list mules", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, flags)
+}
+
+// UnflagMule remove uma marcação de conta laranja
+// @Summary Remove uma marcação de conta laranja
+// @Tags pix
+// @Param id path string true "ID da marcação"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/pix/mules/{id} [delete]
+func (h *PIXHandler) UnflagMule(c *gin.Context) {
+	if err := h.fraudService.UnflagMule(c.Param("id")); err != nil {
+		respondServiceError(c, "Failed to unflag mule", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
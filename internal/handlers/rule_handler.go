@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anti-fraud-golang/internal/expr"
+	"github.com/anti-fraud-golang/internal/rules"
+	"github.com/anti-fraud-golang/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// Cabeçalhos usados na gestão de regras
+const (
+	authorHeader         = "X-Author"
+	ruleSetVersionHeader = "X-Rule-Set-Version"
+)
+
+// RuleHandler handler para gestão das regras em tempo de execução
+type RuleHandler struct {
+	fraudService *services.FraudDetectionService
+}
+
+// NewRuleHandler cria uma nova instância do handler
+func NewRuleHandler(fraudService *services.FraudDetectionService) *RuleHandler {
+	return &RuleHandler{
+		fraudService: fraudService,
+	}
+}
+
+// ExpressionRuleRequest request para criação de regra de expressão
+type ExpressionRuleRequest struct {
+	ID          string `json:"id" binding:"required"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Weight      int    `json:"weight" binding:"gte=0,lte=100"`
+	Priority    int    `json:"priority" binding:"gte=0"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+	Expression  string `json:"expression" binding:"required"`
+	Comment     string `json:"comment,omitempty"`
+}
+
+// ExpressionErrorResponse erro de validação com a posição na expressão
+type ExpressionErrorResponse struct {
+	Error    string         `json:"error"`
+	Message  string         `json:"message"`
+	Position *expr.Position `json:"position,omitempty"`
+}
+
+// ListExpressionRules lista as regras de expressão ativas
+// @Summary Lista regras de expressão
+// @Tags rules
+// @Produce json
+// @Success 200 {array} rules.ExpressionRuleConfig
+// @Router /api/v1/rules/expressions [get]
+func (h *RuleHandler) ListExpressionRules(c *gin.Context) {
+	config := h.fraudService.RuleConfig()
+
+	expressions := config.Expressions
+	if expressions == nil {
+		expressions = []rules.ExpressionRuleConfig{}
+	}
+
+	c.JSON(http.StatusOK, expressions)
+}
+
+// GetExpressionVariables lista as variáveis disponíveis nas expressões
+// @Summary Lista variáveis das expressões e seus tipos
+// @Tags rules
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /api/v1/rules/expressions/variables [get]
+func (h *RuleHandler) GetExpressionVariables(c *gin.Context) {
+	c.JSON(http.StatusOK, rules.ExpressionVariables())
+}
+
+// ValidateExpressionRule valida uma regra de expressão sem aplicá-la
+// @Summary Valida uma regra de expressão
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param rule body ExpressionRuleRequest true "Regra"
+// @Success 200 {object} map[string]bool
+// @Failure 400 {object} ExpressionErrorResponse
+// @Router /api/v1/rules/expressions/validate [post]
+func (h *RuleHandler) ValidateExpressionRule(c *gin.Context) {
+	var req ExpressionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if err := h.fraudService.ValidateExpressionRule(req.toConfig()); err != nil {
+		respondExpressionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": true})
+}
+
+// SaveExpressionRule cria ou substitui uma regra de expressão sem redeploy
+// @Summary Cria ou substitui uma regra de expressão
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param rule body ExpressionRuleRequest true "Regra"
+// @Success 201 {object} rules.ExpressionRuleConfig
+// @Failure 400 {object} ExpressionErrorResponse
+// @Param X-Author header string false "Autor da alteração"
+// @Router /api/v1/rules/expressions [post]
+func (h *RuleHandler) SaveExpressionRule(c *gin.Context) {
+	var req ExpressionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	config := req.toConfig()
+	version, err := h.fraudService.SaveExpressionRule(config, ruleChange(c, req.Comment))
+	if err != nil {
+		respondExpressionError(c, err)
+		return
+	}
+
+	c.Header(ruleSetVersionHeader, strconv.Itoa(version.Version))
+	c.JSON(http.StatusCreated, config)
+}
+
+// DeleteExpressionRule remove uma regra de expressão
+// @Summary Remove uma regra de expressão
+// @Tags rules
+// @Param id path string true "Rule ID"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Param X-Author header string false "Autor da alteração"
+// @Router /api/v1/rules/expressions/{id} [delete]
+func (h *RuleHandler) DeleteExpressionRule(c *gin.Context) {
+	version, err := h.fraudService.DeleteExpressionRule(c.Param("id"), ruleChange(c, ""))
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, services.ErrRuleNotFound) {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, ErrorResponse{
+			Error:   "Failed to delete rule",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.Header(ruleSetVersionHeader, strconv.Itoa(version.Version))
+	c.Status(http.StatusNoContent)
+}
+
+func (r ExpressionRuleRequest) toConfig() rules.ExpressionRuleConfig {
+	return rules.ExpressionRuleConfig{
+		ID:          r.ID,
+		Name:        r.Name,
+		Description: r.Description,
+		Weight:      r.Weight,
+		Priority:    r.Priority,
+		Enabled:     r.Enabled,
+		Expression:  r.Expression,
+	}
+}
+
+// respondExpressionError responde erros de validação com a posição do erro
+func respondExpressionError(c *gin.Context, err error) {
+	response := ExpressionErrorResponse{
+		Error:   "Invalid expression rule",
+		Message: err.Error(),
+	}
+
+	var exprErr *expr.Error
+	if errors.As(err, &exprErr) {
+		response.Position = &exprErr.Pos
+	}
+
+	c.JSON(http.StatusBadRequest, response)
+}
+
+// ruleChange identifica o autor de uma alteração pelo cabeçalho X-Author
+func ruleChange(c *gin.Context, comment string) services.RuleChange {
+	return services.RuleChange{
+		Author:  c.GetHeader(authorHeader),
+		Comment: comment,
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/anti-fraud-golang/internal/rules"
+	"github.com/anti-fraud-golang/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RuleConfigResponse configuração de regras em uso e sua versão
+type RuleConfigResponse struct {
+	Version int                `json:"version"`
+	Config  rules.EngineConfig `json:"config"`
+}
+
+// UpdateRuleConfigRequest request para substituir a configuração de regras
+type UpdateRuleConfigRequest struct {
+	Config  rules.EngineConfig `json:"config"`
+	Comment string             `json:"comment,omitempty"`
+}
+
+// RollbackRequest request de rollback para uma versão anterior
+type RollbackRequest struct {
+	Comment string `json:"comment,omitempty"`
+}
+
+// GetRuleConfig retorna a configuração de regras em uso
+// @Summary Retorna a configuração de regras em uso
+// @Tags rules
+// @Produce json
+// @Success 200 {object} RuleConfigResponse
+// @Router /api/v1/rules/config [get]
+func (h *RuleHandler) GetRuleConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, RuleConfigResponse{
+		Version: h.fraudService.RuleSetVersion(),
+		Config:  h.fraudService.RuleConfig(),
+	})
+}
+
+// UpdateRuleConfig substitui a configuração de regras, gerando uma nova versão
+// @Summary Substitui a configuração de regras
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param X-Author header string false "Autor da alteração"
+// @Param request body UpdateRuleConfigRequest true "Nova configuração"
+// @Success 200 {object} services.RuleSetVersion
+// @Failure 400 {object} ErrorResponse
+// @Router /api/v1/rules/config [put]
+func (h *RuleHandler) UpdateRuleConfig(c *gin.Context) {
+	var req UpdateRuleConfigRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	version, err := h.fraudService.ApplyRuleConfig(req.Config, ruleChange(c, req.Comment))
+	if err != nil {
+		respondExpressionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// ListRuleVersions lista o histórico de versões das regras
+// @Summary Lista as versões da configuração de regras
+// @Tags rules
+// @Produce json
+// @Success 200 {array} services.RuleSetVersion
+// @Failure 500 {object} ErrorResponse
+// @Router /api/v1/rules/versions [get]
+func (h *RuleHandler) ListRuleVersions(c *gin.Context) {
+	versions, err := h.fraudService.ListRuleVersions()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Failed to list rule versions",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, versions)
+}
+
+// GetRuleVersion retorna uma versão da configuração de regras
+// @Summary Retorna uma versão da configuração de regras
+// @Tags rules
+// @Produce json
+// @Param version path int true "Versão"
+// @Success 200 {object} services.RuleSetVersion
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/rules/versions/{version} [get]
+func (h *RuleHandler) GetRuleVersion(c *gin.Context) {
+	number, ok := versionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	version, err := h.fraudService.GetRuleVersion(number)
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// DiffRuleVersions compara duas versões da configuração de regras
+// @Summary Compara duas versões da configuração de regras
+// @Tags rules
+// @Produce json
+// @Param from query int true "Versão de origem"
+// @Param to query int true "Versão de destino"
+// @Success 200 {array} rules.ConfigChange
+// @Failure 400 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/rules/versions/diff [get]
+func (h *RuleHandler) DiffRuleVersions(c *gin.Context) {
+	from, ok := versionParam(c, c.Query("from"))
+	if !ok {
+		return
+	}
+	to, ok := versionParam(c, c.Query("to"))
+	if !ok {
+		return
+	}
+
+	changes, err := h.fraudService.DiffRuleVersions(from, to)
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":    from,
+		"to":      to,
+		"changes": changes,
+	})
+}
+
+// RollbackRuleVersion restaura a configuração de uma versão anterior
+// @Summary Restaura uma versão anterior da configuração de regras
+// @Tags rules
+// @Accept json
+// @Produce json
+// @Param X-Author header string false "Autor da alteração"
+// @Param version path int true "Versão a restaurar"
+// @Param request body RollbackRequest false "Comentário"
+// @Success 200 {object} services.RuleSetVersion
+// @Failure 404 {object} ErrorResponse
+// @Router /api/v1/rules/versions/{version}/rollback [post]
+func (h *RuleHandler) RollbackRuleVersion(c *gin.Context) {
+	number, ok := versionParam(c, c.Param("version"))
+	if !ok {
+		return
+	}
+
+	var req RollbackRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid request",
+				Message: err.Error(),
+			})
+			return
+		}
+	}
+
+	version, err := h.fraudService.RollbackRuleConfig(number, ruleChange(c, req.Comment))
+	if err != nil {
+		respondVersionError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, version)
+}
+
+// versionParam converte um número de versão, respondendo 400 se inválido
+func versionParam(c *gin.Context, value string) (int, bool) {
+	number, err := strconv.Atoi(value)
+	if err != nil || number < 1 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid version",
+			Message: "version must be a positive integer",
+		})
+		return 0, false
+	}
+	return number, true
+}
+
+// respondVersionError responde 404 para versões inexistentes
+func respondVersionError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrVersionNotFound) {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Version not found",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	respondExpressionError(c, err)
+}
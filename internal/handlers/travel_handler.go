@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+	"github.com/anti-fraud-golang/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// travelDateLayout formato das datas dos avisos de viagem
+const travelDateLayout = "2006-01-02"
+
+// TravelHandler handler para os avisos de viagem dos clientes
+type TravelHandler struct {
+	fraudService *services.FraudDetectionService
+}
+
+// NewTravelHandler cria uma nova instância do handler
+func NewTravelHandler(fraudService *services.FraudDetectionService) *TravelHandler {
+	return &TravelHandler{
+		fraudService: fraudService,
+	}
+}
+
+// TravelNoticeRequest request para registro de aviso de viagem
+type TravelNoticeRequest struct {
+	Countries []string `json:"countries" binding:"required,min=1"`
+	Cities    []string `json:"cities,omitempty"`
+	StartDate string   `json:"start_date" binding:"required"`
+	EndDate   string   `json:"end_date" binding:"required"`
+}
+
+// RegisterTravelNotice registra um aviso de viagem do usuário
+// @Summary Registra um aviso de viagem
+// @Description Países (e cidades opcionais) de destino entre duas datas (AAAA-MM-DD, inclusivas)
+// @Tags travel
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param notice body TravelNoticeRequest true "Aviso de viagem"
+// @Success 201 {object} models.TravelNotice
+// @Failure 400 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/users/{user_id}/travel-notices [post]
+func (h *TravelHandler) RegisterTravelNotice(c *gin.Context) {
+	var req TravelNoticeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	start, err := time.Parse(travelDateLayout, req.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "start_date must be YYYY-MM-DD",
+		})
+		return
+	}
+	end, err := time.Parse(travelDateLayout, req.EndDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request",
+			Message: "end_date must be YYYY-MM-DD",
+		})
+		return
+	}
+
+	notice, err := h.fraudService.RegisterTravelNotice(&models.TravelNotice{
+		UserID:    c.Param("user_id"),
+		Countries: req.Countries,
+		Cities:    req.Cities,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		respondServiceError(c, "Failed to register travel notice", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, notice)
+}
+
+// ListTravelNotices lista os avisos de viagem do usuário
+// @Summary Lista os avisos de viagem
+// @Tags travel
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {array} models.TravelNotice
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/users/{user_id}/travel-notices [get]
+func (h *TravelHandler) ListTravelNotices(c *gin.Context) {
+	notices, err := h.fraudService.ListTravelNotices(c.Param("user_id"))
+	if err != nil {
+		respondServiceError(c, "Failed to list travel notices", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notices)
+}
+
+// DeleteTravelNotice remove um aviso de viagem do usuário
+// @Summary Remove um aviso de viagem
+// @Tags travel
+// @Param user_id path string true "User ID"
+// @Param id path string true "ID do aviso"
+// @Success 204
+// @Failure 404 {object} ErrorResponse
+// @Failure 503 {object} ErrorResponse
+// @Router /api/v1/users/{user_id}/travel-notices/{id} [delete]
+func (h *TravelHandler) DeleteTravelNotice(c *gin.Context) {
+	if err := h.fraudService.DeleteTravelNotice(c.Param("user_id"), c.Param("id")); err != nil {
+		respondServiceError(c, "Failed to delete travel notice", err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,201 @@
+package ipintel
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// geoRange faixa contínua de endereços com a mesma localização
+type geoRange struct {
+	start    netip.Addr
+	end      netip.Addr
+	location models.IPGeolocation
+}
+
+// GeoDatabase base de geolocalização de IPs carregada em memória, com faixas
+// ordenadas e sem sobreposição para busca binária
+type GeoDatabase struct {
+	ranges []geoRange
+}
+
+// LoadGeoDatabase carrega a base de um arquivo CSV de faixas
+func LoadGeoDatabase(path string) (*GeoDatabase, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	db, err := ParseGeoDatabase(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ip geolocation database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// ParseGeoDatabase lê faixas em CSV com cabeçalho. Cada faixa é dada por
+// network (CIDR) ou por start_ip e end_ip; country é obrigatório e city,
+// latitude, longitude, asn e organization são opcionais. Linhas iniciadas
+// por # são ignoradas.
+func ParseGeoDatabase(r io.Reader) (*GeoDatabase, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("missing header")
+		}
+		return nil, err
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	_, hasNetwork := columns["network"]
+	_, hasStart := columns["start_ip"]
+	_, hasEnd := columns["end_ip"]
+	if !hasNetwork && !(hasStart && hasEnd) {
+		return nil, fmt.Errorf("header must have network or start_ip and end_ip")
+	}
+	if _, ok := columns["country"]; !ok {
+		return nil, fmt.Errorf("header must have country")
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	db := &GeoDatabase{ranges: make([]geoRange, 0)}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		var start, end netip.Addr
+		if network := field(record, "network"); network != "" {
+			prefix, err := netip.ParsePrefix(network)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			start, end = prefixRange(prefix)
+		} else {
+			if start, err = netip.ParseAddr(field(record, "start_ip")); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			if end, err = netip.ParseAddr(field(record, "end_ip")); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			start, end = start.Unmap(), end.Unmap()
+			if start.BitLen() != end.BitLen() || end.Less(start) {
+				return nil, fmt.Errorf("line %d: invalid range %s-%s", line, start, end)
+			}
+		}
+
+		location := models.IPGeolocation{
+			Country:      strings.ToUpper(field(record, "country")),
+			City:         field(record, "city"),
+			Organization: field(record, "organization"),
+		}
+		if location.Country == "" {
+			return nil, fmt.Errorf("line %d: country is required", line)
+		}
+		for name, target := range map[string]*float64{"latitude": &location.Latitude, "longitude": &location.Longitude} {
+			if value := field(record, name); value != "" {
+				if *target, err = strconv.ParseFloat(value, 64); err != nil {
+					return nil, fmt.Errorf("line %d: invalid %s %q", line, name, value)
+				}
+			}
+		}
+		if asn := strings.TrimPrefix(strings.ToUpper(field(record, "asn")), "AS"); asn != "" {
+			if location.ASN, err = strconv.Atoi(asn); err != nil {
+				return nil, fmt.Errorf("line %d: invalid asn %q", line, asn)
+			}
+		}
+
+		db.ranges = append(db.ranges, geoRange{start: start, end: end, location: location})
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+	for i := 1; i < len(db.ranges); i++ {
+		previous, current := db.ranges[i-1], db.ranges[i]
+		if previous.start.BitLen() == current.start.BitLen() && !previous.end.Less(current.start) {
+			return nil, fmt.Errorf("overlapping ranges %s-%s and %s-%s", previous.start, previous.end, current.start, current.end)
+		}
+	}
+
+	return db, nil
+}
+
+// Lookup localiza um endereço IPv4 ou IPv6
+func (db *GeoDatabase) Lookup(ip string) (*models.IPGeolocation, bool) {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return nil, false
+	}
+	addr = addr.Unmap()
+
+	// Primeira faixa que começa depois do endereço; a candidata é a anterior
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return addr.Less(db.ranges[i].start)
+	})
+	if i == 0 {
+		return nil, false
+	}
+	candidate := db.ranges[i-1]
+	if candidate.start.BitLen() != addr.BitLen() || candidate.end.Less(addr) {
+		return nil, false
+	}
+
+	location := candidate.location
+	return &location, true
+}
+
+// Len número de faixas da base
+func (db *GeoDatabase) Len() int {
+	return len(db.ranges)
+}
+
+// prefixRange primeiro e último endereço de um CIDR
+func prefixRange(prefix netip.Prefix) (netip.Addr, netip.Addr) {
+	prefix = prefix.Masked()
+	start, bits := prefix.Addr(), prefix.Bits()
+	if start.Is4In6() {
+		start, bits = start.Unmap(), max(bits-96, 0)
+	}
+
+	bytes := start.AsSlice()
+	hostBits := start.BitLen() - bits
+	for i := len(bytes) - 1; hostBits > 0; i-- {
+		if hostBits >= 8 {
+			bytes[i] = 0xff
+			hostBits -= 8
+		} else {
+			bytes[i] |= byte(1<<hostBits - 1)
+			hostBits = 0
+		}
+	}
+	end, _ := netip.AddrFromSlice(bytes)
+	return start, end
+}
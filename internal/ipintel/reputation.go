@@ -0,0 +1,261 @@
+package ipintel
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// Categorias de anonimizadores
+const (
+	CategoryTor     = "tor"
+	CategoryProxy   = "proxy"
+	CategoryVPN     = "vpn"
+	CategoryHosting = "hosting"
+)
+
+// ReputationSource arquivo de lista de uma categoria. Cada linha traz um IP,
+// um CIDR ou um ASN (AS64500); linhas vazias e iniciadas por # são ignoradas.
+type ReputationSource struct {
+	Category string `json:"category"`
+	Path     string `json:"path"`
+}
+
+// ReputationConfig listas de reputação e intervalo de recarga
+type ReputationConfig struct {
+	ReloadIntervalS int                `json:"reload_interval_s"`
+	Sources         []ReputationSource `json:"sources"`
+}
+
+// LoadReputationConfig carrega a configuração das listas de um arquivo JSON.
+// Caminhos relativos são resolvidos a partir do diretório de trabalho.
+func LoadReputationConfig(path string) (*ReputationConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := ReputationConfig{ReloadIntervalS: 600}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid ip reputation config %s: %w", path, err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// Validate verifica categorias e caminhos das listas
+func (c *ReputationConfig) Validate() error {
+	if c.ReloadIntervalS < 0 {
+		return fmt.Errorf("ip reputation: reload interval must not be negative")
+	}
+	for i, source := range c.Sources {
+		switch source.Category {
+		case CategoryTor, CategoryProxy, CategoryVPN, CategoryHosting:
+		default:
+			return fmt.Errorf("ip reputation: source %d has unknown category %q", i, source.Category)
+		}
+		if source.Path == "" {
+			return fmt.Errorf("ip reputation: source %d: path is required", i)
+		}
+	}
+	return nil
+}
+
+// reputationLists conteúdo carregado das listas, por categoria
+type reputationLists struct {
+	addresses map[netip.Addr][]string
+	prefixes  []categoryPrefix
+	asns      map[int][]string
+}
+
+type categoryPrefix struct {
+	prefix   netip.Prefix
+	category string
+}
+
+// Reputation classifica IPs como anonimizadores a partir das listas locais.
+// As listas são trocadas por inteiro na recarga, sem bloquear as consultas.
+type Reputation struct {
+	sources  []ReputationSource
+	mu       sync.RWMutex
+	lists    *reputationLists
+	modTimes map[string]time.Time
+}
+
+// NewReputation carrega as listas informadas
+func NewReputation(sources []ReputationSource) (*Reputation, error) {
+	reputation := &Reputation{sources: sources}
+	if _, err := reputation.Reload(); err != nil {
+		return nil, err
+	}
+	return reputation, nil
+}
+
+// Reload relê as listas se algum arquivo mudou desde a última carga. Em caso
+// de erro as listas anteriores continuam em uso.
+func (r *Reputation) Reload() (bool, error) {
+	modTimes := make(map[string]time.Time, len(r.sources))
+	for _, source := range r.sources {
+		info, err := os.Stat(source.Path)
+		if err != nil {
+			return false, fmt.Errorf("ip reputation %s: %w", source.Category, err)
+		}
+		modTimes[source.Path] = info.ModTime()
+	}
+
+	r.mu.RLock()
+	changed := r.lists == nil || len(modTimes) != len(r.modTimes)
+	for path, modTime := range modTimes {
+		if !r.modTimes[path].Equal(modTime) {
+			changed = true
+		}
+	}
+	r.mu.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	lists := &reputationLists{
+		addresses: make(map[netip.Addr][]string),
+		prefixes:  make([]categoryPrefix, 0),
+		asns:      make(map[int][]string),
+	}
+	for _, source := range r.sources {
+		if err := lists.load(source); err != nil {
+			return false, fmt.Errorf("ip reputation %s: %w", source.Category, err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lists = lists
+	r.modTimes = modTimes
+	return true, nil
+}
+
+// Watch recarrega as listas periodicamente até o contexto terminar. Falhas
+// de recarga são informadas a onError e não interrompem a verificação.
+func (r *Reputation) Watch(ctx context.Context, interval time.Duration, onReload func(), onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil && onError != nil {
+				onError(err)
+			}
+			if reloaded && onReload != nil {
+				onReload()
+			}
+		}
+	}
+}
+
+// Check retorna as categorias de anonimizador do IP ou do seu ASN; nil se o
+// IP não consta em nenhuma lista
+func (r *Reputation) Check(ip string, asn int) *models.IPReputation {
+	r.mu.RLock()
+	lists := r.lists
+	r.mu.RUnlock()
+
+	categories := make(map[string]bool)
+	if addr, err := netip.ParseAddr(strings.TrimSpace(ip)); err == nil {
+		addr = addr.Unmap()
+		for _, category := range lists.addresses[addr] {
+			categories[category] = true
+		}
+		for _, p := range lists.prefixes {
+			if p.prefix.Contains(addr) {
+				categories[p.category] = true
+			}
+		}
+	}
+	if asn != 0 {
+		for _, category := range lists.asns[asn] {
+			categories[category] = true
+		}
+	}
+
+	if len(categories) == 0 {
+		return nil
+	}
+
+	reputation := &models.IPReputation{Categories: make([]string, 0, len(categories))}
+	for category := range categories {
+		reputation.Categories = append(reputation.Categories, category)
+	}
+	sort.Strings(reputation.Categories)
+	return reputation
+}
+
+// Size número de entradas carregadas
+func (r *Reputation) Size() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.lists.addresses) + len(r.lists.prefixes) + len(r.lists.asns)
+}
+
+// load lê uma lista de IPs, CIDRs e ASNs
+func (l *reputationLists) load(source ReputationSource) error {
+	file, err := os.Open(source.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		entry := strings.TrimSpace(scanner.Text())
+		if i := strings.IndexByte(entry, '#'); i >= 0 {
+			entry = strings.TrimSpace(entry[:i])
+		}
+		if entry == "" {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(strings.ToUpper(entry), "AS"):
+			asn, err := strconv.Atoi(entry[2:])
+			if err != nil || asn <= 0 {
+				return fmt.Errorf("%s:%d: invalid asn %q", source.Path, line, entry)
+			}
+			l.asns[asn] = append(l.asns[asn], source.Category)
+		case strings.Contains(entry, "/"):
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", source.Path, line, err)
+			}
+			prefix = prefix.Masked()
+			if prefix.Addr().Is4In6() {
+				prefix = netip.PrefixFrom(prefix.Addr().Unmap(), max(prefix.Bits()-96, 0))
+			}
+			l.prefixes = append(l.prefixes, categoryPrefix{prefix: prefix, category: source.Category})
+		default:
+			addr, err := netip.ParseAddr(entry)
+			if err != nil {
+				return fmt.Errorf("%s:%d: %w", source.Path, line, err)
+			}
+			addr = addr.Unmap()
+			l.addresses[addr] = append(l.addresses[addr], source.Category)
+		}
+	}
+
+	return scanner.Err()
+}
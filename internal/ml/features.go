@@ -0,0 +1,119 @@
+package ml
+
+import (
+	"math"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// FeatureNames nomes das features, na mesma ordem do vetor gerado por ExtractFeatures.
+// Qualquer alteração aqui invalida modelos treinados anteriormente; uma feature
+// cujo cálculo muda ganha um nome novo, para que LoadModel recuse o modelo antigo.
+var FeatureNames = []string{
+	"log_amount",
+	"amount_to_avg_ratio",
+	"is_new_user",
+	"account_age_days",
+	"log_total_transactions",
+	"minutes_since_last_transaction",
+	"local_night_hour",
+	"known_country",
+	"known_merchant",
+	"trusted_device",
+	"round_amount",
+	"fraud_history_count",
+	"distance_from_common_location_km",
+}
+
+// ExtractFeatures calcula o vetor de features de uma transação.
+// É usado tanto no treinamento offline quanto na pontuação online, garantindo
+// que as features nunca divirjam. Todos os tempos são relativos ao Timestamp
+// da transação, nunca ao relógio atual.
+func ExtractFeatures(transaction *models.Transaction, profile *models.UserProfile) []float64 {
+	features := make([]float64, len(FeatureNames))
+
+	base := transaction.AmountInBase()
+	amount := base.Float64()
+	features[0] = math.Log1p(math.Max(amount, 0))
+
+	local, _ := transaction.LocalTime()
+	if hour := local.Hour(); hour >= 23 || hour <= 5 {
+		features[6] = 1
+	}
+
+	if amount >= 1000 && base.IsMultipleOf(1000) {
+		features[10] = 1
+	}
+
+	if profile == nil || profile.TotalTransactions == 0 {
+		features[2] = 1
+		features[1] = 1
+		return features
+	}
+
+	if profile.AvgTransactionValue.IsPositive() {
+		features[1] = amount / profile.AvgTransactionValue.Float64()
+	} else {
+		features[1] = 1
+	}
+
+	if !profile.FirstTransactionAt.IsZero() {
+		features[3] = math.Max(transaction.Timestamp.Sub(profile.FirstTransactionAt).Hours()/24, 0)
+	}
+	features[4] = math.Log1p(float64(profile.TotalTransactions))
+
+	if !profile.LastTransactionAt.IsZero() {
+		minutes := transaction.Timestamp.Sub(profile.LastTransactionAt).Minutes()
+		// Limita a uma semana para não dominar o modelo
+		features[5] = math.Min(math.Max(minutes, 0), 7*24*60)
+	} else {
+		features[5] = 7 * 24 * 60
+	}
+
+	minDistance := -1.0
+	for _, location := range profile.CommonLocations {
+		if location.Country == transaction.Location.Country {
+			features[7] = 1
+		}
+		distance := haversine(
+			location.Latitude, location.Longitude,
+			transaction.Location.Latitude, transaction.Location.Longitude,
+		)
+		if minDistance < 0 || distance < minDistance {
+			minDistance = distance
+		}
+	}
+	if minDistance > 0 {
+		features[12] = minDistance
+	}
+
+	for _, merchant := range profile.CommonMerchants {
+		if merchant == transaction.Merchant {
+			features[8] = 1
+			break
+		}
+	}
+
+	if profile.IsTrustedDevice(transaction.DeviceInfo) {
+		features[9] = 1
+	}
+
+	features[11] = float64(len(profile.FraudHistory))
+
+	return features
+}
+
+// haversine calcula a distância entre dois pontos geográficos em km
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371.0 // km
+
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dLat := toRad(lat2 - lat1)
+	dLon := toRad(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*
+			math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,36 @@
+package ml
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+func TestNightHourUsesLocalTime(t *testing.T) {
+	// 4h UTC: madrugada em Londres, 1h da manhã em São Paulo e 13h em Tóquio
+	at := time.Date(2026, 3, 10, 4, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		country string
+		night   float64
+	}{
+		{"GB", 1},
+		{"BR", 1},
+		{"JP", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.country, func(t *testing.T) {
+			transaction := &models.Transaction{
+				Amount:    models.NewMoney(10000, "BRL"),
+				Currency:  "BRL",
+				Timestamp: at,
+				Location:  models.Location{Country: tt.country},
+			}
+			if got := ExtractFeatures(transaction, nil)[6]; got != tt.night {
+				t.Errorf("%s = %v, want %v", FeatureNames[6], got, tt.night)
+			}
+		})
+	}
+}
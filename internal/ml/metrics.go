@@ -0,0 +1,126 @@
+package ml
+
+import "sort"
+
+// EvaluationReport métricas de avaliação de um modelo
+type EvaluationReport struct {
+	Examples         int                `json:"examples"`
+	Positives        int                `json:"positives"`
+	AUC              float64            `json:"auc"`
+	AveragePrecision float64            `json:"average_precision"`
+	Thresholds       []ThresholdMetrics `json:"thresholds"`
+}
+
+// ThresholdMetrics precisão e recall para um limiar de decisão
+type ThresholdMetrics struct {
+	Threshold      float64 `json:"threshold"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+}
+
+// ReportThresholds limiares reportados na curva precisão/recall
+var ReportThresholds = []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9}
+
+type scoredExample struct {
+	score   float64
+	isFraud bool
+}
+
+// Evaluate calcula AUC ROC, precisão média e precisão/recall por limiar
+func Evaluate(model *Model, examples []LabeledTransaction) EvaluationReport {
+	scored := make([]scoredExample, len(examples))
+	report := EvaluationReport{Examples: len(examples)}
+
+	for i := range examples {
+		scored[i] = scoredExample{
+			score:   model.Score(&examples[i].Transaction, examples[i].Profile),
+			isFraud: examples[i].IsFraud,
+		}
+		if examples[i].IsFraud {
+			report.Positives++
+		}
+	}
+
+	report.AUC = rocAUC(scored, report.Positives)
+	report.AveragePrecision = averagePrecision(scored, report.Positives)
+
+	for _, threshold := range ReportThresholds {
+		metrics := ThresholdMetrics{Threshold: threshold}
+		for _, example := range scored {
+			predicted := example.score >= threshold
+			switch {
+			case predicted && example.isFraud:
+				metrics.TruePositives++
+			case predicted && !example.isFraud:
+				metrics.FalsePositives++
+			case !predicted && example.isFraud:
+				metrics.FalseNegatives++
+			}
+		}
+		if metrics.TruePositives+metrics.FalsePositives > 0 {
+			metrics.Precision = float64(metrics.TruePositives) / float64(metrics.TruePositives+metrics.FalsePositives)
+		}
+		if report.Positives > 0 {
+			metrics.Recall = float64(metrics.TruePositives) / float64(report.Positives)
+		}
+		report.Thresholds = append(report.Thresholds, metrics)
+	}
+
+	return report
+}
+
+// rocAUC calcula a área sob a curva ROC pela estatística de Mann-Whitney
+func rocAUC(scored []scoredExample, positives int) float64 {
+	negatives := len(scored) - positives
+	if positives == 0 || negatives == 0 {
+		return 0
+	}
+
+	sorted := make([]scoredExample, len(scored))
+	copy(sorted, scored)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].score < sorted[j].score })
+
+	// Soma dos ranks dos positivos, com média de ranks em empates
+	rankSum := 0.0
+	for i := 0; i < len(sorted); {
+		j := i
+		for j < len(sorted) && sorted[j].score == sorted[i].score {
+			j++
+		}
+		avgRank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if sorted[k].isFraud {
+				rankSum += avgRank
+			}
+		}
+		i = j
+	}
+
+	p, n := float64(positives), float64(negatives)
+	return (rankSum - p*(p+1)/2) / (p * n)
+}
+
+// averagePrecision calcula a área sob a curva precisão/recall
+func averagePrecision(scored []scoredExample, positives int) float64 {
+	if positives == 0 {
+		return 0
+	}
+
+	sorted := make([]scoredExample, len(scored))
+	copy(sorted, scored)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].score > sorted[j].score })
+
+	truePositives := 0
+	sum := 0.0
+	for i, example := range sorted {
+		if example.isFraud {
+			truePositives++
+			sum += float64(truePositives) / float64(i+1)
+		}
+	}
+
+	return sum / float64(positives)
+}
@@ -0,0 +1,93 @@
+package ml
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// Model modelo de regressão logística para probabilidade de fraude
+type Model struct {
+	Version      string    `json:"version"`
+	FeatureNames []string  `json:"feature_names"`
+	Weights      []float64 `json:"weights"`
+	Bias         float64   `json:"bias"`
+	Means        []float64 `json:"means"`
+	StdDevs      []float64 `json:"std_devs"`
+	TrainedAt    time.Time `json:"trained_at"`
+}
+
+// Predict retorna a probabilidade de fraude para um vetor de features
+func (m *Model) Predict(features []float64) float64 {
+	z := m.Bias
+	for i, weight := range m.Weights {
+		z += weight * m.standardize(i, features[i])
+	}
+	return sigmoid(z)
+}
+
+// Score extrai as features da transação e retorna a probabilidade de fraude
+func (m *Model) Score(transaction *models.Transaction, profile *models.UserProfile) float64 {
+	return m.Predict(ExtractFeatures(transaction, profile))
+}
+
+// standardize normaliza uma feature com a média e desvio do treinamento
+func (m *Model) standardize(i int, value float64) float64 {
+	if m.StdDevs[i] == 0 {
+		return 0
+	}
+	return (value - m.Means[i]) / m.StdDevs[i]
+}
+
+// validate garante que o modelo foi treinado com as features atuais
+func (m *Model) validate() error {
+	if len(m.FeatureNames) != len(FeatureNames) {
+		return fmt.Errorf("model has %d features, scorer expects %d", len(m.FeatureNames), len(FeatureNames))
+	}
+	for i, name := range FeatureNames {
+		if m.FeatureNames[i] != name {
+			return fmt.Errorf("feature %d mismatch: model has %q, scorer expects %q", i, m.FeatureNames[i], name)
+		}
+	}
+	n := len(FeatureNames)
+	if len(m.Weights) != n || len(m.Means) != n || len(m.StdDevs) != n {
+		return fmt.Errorf("model parameters do not match %d features", n)
+	}
+	return nil
+}
+
+// Save grava o modelo em um arquivo JSON
+func (m *Model) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// LoadModel carrega um modelo de um arquivo JSON
+func LoadModel(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var model Model
+	if err := json.Unmarshal(data, &model); err != nil {
+		return nil, fmt.Errorf("invalid model file %s: %w", path, err)
+	}
+
+	if err := model.validate(); err != nil {
+		return nil, fmt.Errorf("invalid model file %s: %w", path, err)
+	}
+
+	return &model, nil
+}
+
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
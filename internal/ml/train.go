@@ -0,0 +1,187 @@
+package ml
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// LabeledTransaction transação histórica rotulada usada no treinamento.
+// Profile é o perfil do usuário no momento da transação.
+type LabeledTransaction struct {
+	Transaction models.Transaction  `json:"transaction"`
+	Profile     *models.UserProfile `json:"profile,omitempty"`
+	IsFraud     bool                `json:"is_fraud"`
+}
+
+// TrainConfig parâmetros de treinamento
+type TrainConfig struct {
+	LearningRate   float64
+	Epochs         int
+	L2             float64
+	ClassWeighting bool
+}
+
+// DefaultTrainConfig retorna os parâmetros padrão de treinamento
+func DefaultTrainConfig() TrainConfig {
+	return TrainConfig{
+		LearningRate:   0.1,
+		Epochs:         500,
+		L2:             0.01,
+		ClassWeighting: true,
+	}
+}
+
+// ReadLabeledTransactions lê transações rotuladas em formato JSON Lines
+func ReadLabeledTransactions(r io.Reader) ([]LabeledTransaction, error) {
+	examples := make([]LabeledTransaction, 0)
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var example LabeledTransaction
+		if err := json.Unmarshal(scanner.Bytes(), &example); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		examples = append(examples, example)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return examples, nil
+}
+
+// TimeSplit separa os exemplos por tempo: os mais antigos para treino e a
+// fração mais recente para validação, evitando vazamento do futuro.
+func TimeSplit(examples []LabeledTransaction, validationFraction float64) (train, validation []LabeledTransaction) {
+	sorted := make([]LabeledTransaction, len(examples))
+	copy(sorted, examples)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Transaction.Timestamp.Before(sorted[j].Transaction.Timestamp)
+	})
+
+	cut := int(float64(len(sorted)) * (1 - validationFraction))
+	return sorted[:cut], sorted[cut:]
+}
+
+// Train treina uma regressão logística com regularização L2 e, opcionalmente,
+// pesos por classe para compensar o desbalanceamento entre fraude e legítimas.
+func Train(examples []LabeledTransaction, config TrainConfig) (*Model, error) {
+	if len(examples) == 0 {
+		return nil, errors.New("no training examples")
+	}
+
+	n := len(FeatureNames)
+	rows := make([][]float64, len(examples))
+	labels := make([]float64, len(examples))
+	positives := 0
+
+	for i := range examples {
+		rows[i] = ExtractFeatures(&examples[i].Transaction, examples[i].Profile)
+		if examples[i].IsFraud {
+			labels[i] = 1
+			positives++
+		}
+	}
+
+	if positives == 0 || positives == len(examples) {
+		return nil, errors.New("training data must contain both fraud and legitimate examples")
+	}
+
+	model := &Model{
+		Version:      time.Now().UTC().Format("20060102150405"),
+		FeatureNames: append([]string(nil), FeatureNames...),
+		Weights:      make([]float64, n),
+		Means:        make([]float64, n),
+		StdDevs:      make([]float64, n),
+		TrainedAt:    time.Now().UTC(),
+	}
+
+	// Calcula média e desvio padrão para normalização
+	for _, row := range rows {
+		for j, value := range row {
+			model.Means[j] += value
+		}
+	}
+	for j := range model.Means {
+		model.Means[j] /= float64(len(rows))
+	}
+	for _, row := range rows {
+		for j, value := range row {
+			d := value - model.Means[j]
+			model.StdDevs[j] += d * d
+		}
+	}
+	for j := range model.StdDevs {
+		model.StdDevs[j] = math.Sqrt(model.StdDevs[j] / float64(len(rows)))
+	}
+
+	// Pesos por classe
+	positiveWeight, negativeWeight := 1.0, 1.0
+	if config.ClassWeighting {
+		total := float64(len(examples))
+		positiveWeight = total / (2 * float64(positives))
+		negativeWeight = total / (2 * float64(len(examples)-positives))
+	}
+
+	standardized := make([][]float64, len(rows))
+	for i, row := range rows {
+		standardized[i] = make([]float64, n)
+		for j, value := range row {
+			standardized[i][j] = model.standardize(j, value)
+		}
+	}
+
+	// Gradiente descendente em lote
+	gradient := make([]float64, n)
+	for epoch := 0; epoch < config.Epochs; epoch++ {
+		for j := range gradient {
+			gradient[j] = 0
+		}
+		biasGradient := 0.0
+		weightSum := 0.0
+
+		for i, row := range standardized {
+			z := model.Bias
+			for j, value := range row {
+				z += model.Weights[j] * value
+			}
+
+			sampleWeight := negativeWeight
+			if labels[i] == 1 {
+				sampleWeight = positiveWeight
+			}
+
+			err := (sigmoid(z) - labels[i]) * sampleWeight
+			for j, value := range row {
+				gradient[j] += err * value
+			}
+			biasGradient += err
+			weightSum += sampleWeight
+		}
+
+		for j := range model.Weights {
+			g := gradient[j]/weightSum + config.L2*model.Weights[j]
+			model.Weights[j] -= config.LearningRate * g
+		}
+		model.Bias -= config.LearningRate * biasGradient / weightSum
+	}
+
+	return model, nil
+}
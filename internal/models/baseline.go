@@ -0,0 +1,70 @@
+package models
+
+import "math"
+
+// BehaviorBaseline estatísticas comportamentais do usuário, mantidas de forma
+// incremental a cada transação aceita
+type BehaviorBaseline struct {
+	Count          int            `json:"count"`
+	AmountMean     float64        `json:"amount_mean"`
+	AmountM2       float64        `json:"amount_m2"`   // soma dos quadrados dos desvios (Welford)
+	HourCounts     [24]int        `json:"hour_counts"` // por hora local da transação
+	MerchantCounts map[string]int `json:"merchant_counts"`
+	CountryCounts  map[string]int `json:"country_counts"`
+}
+
+// NewBehaviorBaseline cria um baseline vazio
+func NewBehaviorBaseline() *BehaviorBaseline {
+	return &BehaviorBaseline{
+		MerchantCounts: make(map[string]int),
+		CountryCounts:  make(map[string]int),
+	}
+}
+
+// Observe incorpora uma transação ao baseline
+func (b *BehaviorBaseline) Observe(transaction *Transaction) {
+	b.Count++
+	amount := transaction.AmountInBase().Float64()
+	delta := amount - b.AmountMean
+	b.AmountMean += delta / float64(b.Count)
+	b.AmountM2 += delta * (amount - b.AmountMean)
+
+	local, _ := transaction.LocalTime()
+	b.HourCounts[local.Hour()]++
+
+	if b.MerchantCounts == nil {
+		b.MerchantCounts = make(map[string]int)
+	}
+	if transaction.Merchant != "" {
+		b.MerchantCounts[transaction.Merchant]++
+	}
+
+	if b.CountryCounts == nil {
+		b.CountryCounts = make(map[string]int)
+	}
+	if transaction.Location.Country != "" {
+		b.CountryCounts[transaction.Location.Country]++
+	}
+}
+
+// AmountStdDev desvio padrão amostral dos valores observados
+func (b *BehaviorBaseline) AmountStdDev() float64 {
+	if b.Count < 2 {
+		return 0
+	}
+	return math.Sqrt(b.AmountM2 / float64(b.Count-1))
+}
+
+// Clone retorna uma cópia independente do baseline
+func (b *BehaviorBaseline) Clone() *BehaviorBaseline {
+	clone := *b
+	clone.MerchantCounts = make(map[string]int, len(b.MerchantCounts))
+	for k, v := range b.MerchantCounts {
+		clone.MerchantCounts[k] = v
+	}
+	clone.CountryCounts = make(map[string]int, len(b.CountryCounts))
+	for k, v := range b.CountryCounts {
+		clone.CountryCounts[k] = v
+	}
+	return &clone
+}
@@ -0,0 +1,39 @@
+package models
+
+// Tipos de produto do cartão
+const (
+	CardProductCredit  = "credit"
+	CardProductDebit   = "debit"
+	CardProductPrepaid = "prepaid"
+)
+
+// CardIssuer dados do emissor do cartão obtidos pela tabela de BINs
+type CardIssuer struct {
+	BIN         string `json:"bin"`
+	Country     string `json:"country"`
+	Bank        string `json:"bank,omitempty"`
+	Brand       string `json:"brand,omitempty"`
+	ProductType string `json:"product_type,omitempty"`
+	Commercial  bool   `json:"commercial"`
+}
+
+// BINVelocity cartões distintos e transações de um BIN em uma janela
+type BINVelocity struct {
+	BIN              string `json:"bin"`
+	DistinctCards    int    `json:"distinct_cards"`
+	TransactionCount int    `json:"transaction_count"`
+	TimeWindow       int    `json:"time_window_minutes"`
+}
+
+// CardKey identifica o cartão da transação sem o número completo: a
+// impressão digital ou, na falta dela, BIN e últimos dígitos; vazio se não
+// houver dados do cartão
+func (t *Transaction) CardKey() string {
+	if t.CardFingerprint != "" {
+		return t.CardFingerprint
+	}
+	if t.CardBIN == "" && t.CardLast4 == "" {
+		return ""
+	}
+	return t.CardBIN + "*" + t.CardLast4
+}
@@ -0,0 +1,32 @@
+package models
+
+// DeviceUsage contas distintas que usaram a mesma impressão digital de
+// dispositivo em uma janela
+type DeviceUsage struct {
+	Fingerprint   string `json:"fingerprint"`
+	DistinctUsers int    `json:"distinct_users"`
+	TimeWindow    int    `json:"time_window_minutes"`
+}
+
+// Key identifica o dispositivo: o DeviceID ou, na falta dele, a impressão
+// digital; vazio se o cliente não enviou nenhum dos dois
+func (d DeviceInfo) Key() string {
+	if d.DeviceID != "" {
+		return d.DeviceID
+	}
+	return d.Fingerprint
+}
+
+// IsTrustedDevice indica se o dispositivo da transação foi promovido a confiável
+func (p *UserProfile) IsTrustedDevice(device DeviceInfo) bool {
+	key := device.Key()
+	if key == "" {
+		return false
+	}
+	for _, trusted := range p.TrustedDevices {
+		if trusted == key {
+			return true
+		}
+	}
+	return false
+}
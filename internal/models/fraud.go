@@ -5,7 +5,7 @@ import "time"
 // BlacklistEntry entrada da lista negra
 type BlacklistEntry struct {
 	ID         string    `json:"id"`
-	Type       string    `json:"type"` // "card", "user", "device", "ip"
+	Type       string    `json:"type"` // "card" (impressão digital), "user", "device", "ip"
 	Value      string    `json:"value"`
 	Reason     string    `json:"reason"`
 	AddedAt    time.Time `json:"added_at"`
@@ -16,8 +16,10 @@ type BlacklistEntry struct {
 // VelocityCheck verifica velocidade de transações
 type VelocityCheck struct {
 	UserID             string    `json:"user_id"`
+	CardFingerprint    string    `json:"card_fingerprint,omitempty"`
 	TransactionCount   int       `json:"transaction_count"`
-	TotalAmount        float64   `json:"total_amount"`
+	DistinctUsers      int       `json:"distinct_users,omitempty"`
+	TotalAmount        Money     `json:"total_amount"` // na moeda base
 	TimeWindow         int       `json:"time_window_minutes"`
 	LastTransactionAt  time.Time `json:"last_transaction_at"`
 }
@@ -27,21 +29,35 @@ type GeoVelocity struct {
 	UserID           string    `json:"user_id"`
 	PreviousLocation Location  `json:"previous_location"`
 	CurrentLocation  Location  `json:"current_location"`
+	PreviousAt       time.Time `json:"previous_at"`
+	CurrentAt        time.Time `json:"current_at"`
 	Distance         float64   `json:"distance_km"`
 	TimeDiff         int       `json:"time_diff_minutes"`
 	Speed            float64   `json:"speed_kmh"`
+	MaxSpeed         float64   `json:"max_speed_kmh,omitempty"`
+	MinTravelTime    int       `json:"min_travel_minutes,omitempty"`
+	Basis            string    `json:"basis"` // "distance", "speed", "airport" ou "flight_route"
 	IsPossible       bool      `json:"is_possible"`
 }
 
+// FlightRoute duração mínima de voo entre duas cidades, nos dois sentidos
+type FlightRoute struct {
+	From            string `json:"from"`
+	To              string `json:"to"`
+	DurationMinutes int    `json:"duration_minutes"`
+}
+
 // AlertConfig configuração de alertas
 type AlertConfig struct {
-	ID                  string  `json:"id"`
-	MaxAmountThreshold  float64 `json:"max_amount_threshold"`
-	VelocityThreshold   int     `json:"velocity_threshold"`
-	GeoVelocityLimit    float64 `json:"geo_velocity_limit_kmh"`
-	NightHourStart      int     `json:"night_hour_start"`
-	NightHourEnd        int     `json:"night_hour_end"`
-	HighRiskCountries   []string `json:"high_risk_countries"`
+	ID                 string        `json:"id"`
+	MaxAmountThreshold float64       `json:"max_amount_threshold"`
+	VelocityThreshold  int           `json:"velocity_threshold"`
+	GeoVelocityLimit   float64       `json:"geo_velocity_limit_kmh"`
+	NightHourStart     *int          `json:"night_hour_start,omitempty"` // ausente usa 23h
+	NightHourEnd       *int          `json:"night_hour_end,omitempty"`   // ausente usa 5h
+	HighRiskCountries  []string      `json:"high_risk_countries"`
+	AirportCities      []string      `json:"airport_cities,omitempty"`
+	FlightRoutes       []FlightRoute `json:"flight_routes,omitempty"`
 }
 
 // TransactionPattern padrão de transação
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// FXConversion conversão do valor da transação para a moeda base
+type FXConversion struct {
+	Currency     string     `json:"currency"`
+	BaseCurrency string     `json:"base_currency"`
+	Rate         float64    `json:"rate,omitempty"`
+	RateDate     *time.Time `json:"rate_date,omitempty"`
+	BaseAmount   Money      `json:"base_amount"`
+	Stale        bool       `json:"stale,omitempty"` // cotação mais antiga que o limite configurado
+}
+
+// UnmarshalJSON lê o valor convertido nas casas decimais da moeda base
+func (c *FXConversion) UnmarshalJSON(data []byte) error {
+	type plain FXConversion
+	aux := struct {
+		*plain
+		BaseAmount json.RawMessage `json:"base_amount"`
+	}{plain: (*plain)(c)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	amount, err := decodeMoney(aux.BaseAmount, c.BaseCurrency)
+	if err != nil {
+		return err
+	}
+	c.BaseAmount = amount
+	return nil
+}
+
+// AmountInBase valor da transação na moeda base; sem conversão, o valor informado
+func (t *Transaction) AmountInBase() Money {
+	if t.FXConversion != nil {
+		return t.FXConversion.BaseAmount
+	}
+	return t.Amount
+}
@@ -0,0 +1,22 @@
+package models
+
+// IPGeolocation localização derivada do endereço IP pela base local
+type IPGeolocation struct {
+	Country      string  `json:"country"`
+	City         string  `json:"city,omitempty"`
+	Latitude     float64 `json:"latitude,omitempty"`
+	Longitude    float64 `json:"longitude,omitempty"`
+	ASN          int     `json:"asn,omitempty"`
+	Organization string  `json:"organization,omitempty"`
+}
+
+// HasCoordinates indica se a base informou coordenadas para o IP
+func (g *IPGeolocation) HasCoordinates() bool {
+	return g != nil && (g.Latitude != 0 || g.Longitude != 0)
+}
+
+// IPReputation categorias de anonimizador (tor, proxy, vpn, hosting) em que o
+// IP ou o seu ASN aparecem
+type IPReputation struct {
+	Categories []string `json:"categories"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import (
+	"time"
+
+	"github.com/anti-fraud-golang/internal/geo"
+)
+
+// LocalTime horário local da transação, resolvido pelo país e pelas
+// coordenadas da localização, e a origem do fuso usado
+func (t *Transaction) LocalTime() (time.Time, string) {
+	return geo.LocalTime(t.Timestamp, t.Location.Country, t.Location.Latitude, t.Location.Longitude)
+}
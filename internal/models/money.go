@@ -0,0 +1,237 @@
+package models
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/anti-fraud-golang/internal/currency"
+)
+
+// defaultExponent casas decimais de valores sem moeda, como os de perfis antigos
+const defaultExponent = 2
+
+// ErrInvalidAmount valor que não é um decimal representável na moeda
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// Money valor monetário exato em unidades menores da moeda (centavos para BRL,
+// ienes para JPY). No JSON é um número decimal nas unidades da moeda.
+type Money struct {
+	Minor    int64
+	Currency string
+}
+
+// NewMoney cria um valor a partir das unidades menores da moeda
+func NewMoney(minor int64, code string) Money {
+	return Money{Minor: minor, Currency: currency.Normalize(code)}
+}
+
+// MoneyFromFloat converte um float para a moeda, arredondando nas casas
+// decimais dela. Usado apenas para valores calculados, como médias e conversões.
+func MoneyFromFloat(value float64, code string) Money {
+	money := NewMoney(0, code)
+	money.Minor = int64(math.Round(value * float64(money.MinorPerUnit())))
+	return money
+}
+
+// ParseMoney lê um decimal ("1234.56", "1e3") sem passar por float. Casas além
+// das permitidas pela moeda só são aceitas se forem zeros.
+func ParseMoney(value, code string) (Money, error) {
+	money := NewMoney(0, code)
+	if money.Currency != "" && !currency.Valid(money.Currency) {
+		return Money{}, fmt.Errorf("%w: unknown currency %q", ErrInvalidAmount, code)
+	}
+
+	digits, scale, err := parseDecimal(value)
+	if err != nil {
+		return Money{}, err
+	}
+
+	exponent := money.Exponent()
+	negative := strings.HasPrefix(digits, "-")
+	digits = strings.TrimPrefix(digits, "-")
+	if scale > exponent {
+		cut := len(digits) - (scale - exponent)
+		if cut < 0 {
+			cut = 0
+		}
+		if strings.Trim(digits[cut:], "0") != "" {
+			return Money{}, fmt.Errorf("%w: %s has more than %d decimal places for %s", ErrInvalidAmount, value, exponent, money.currencyLabel())
+		}
+		digits = digits[:cut]
+	} else {
+		digits += strings.Repeat("0", exponent-scale)
+	}
+	if digits == "" {
+		digits = "0"
+	}
+
+	minor, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, fmt.Errorf("%w: %s is out of range", ErrInvalidAmount, value)
+	}
+	if negative {
+		minor = -minor
+	}
+	money.Minor = minor
+	return money, nil
+}
+
+// parseDecimal separa um número JSON em dígitos (com sinal) e número de casas decimais
+func parseDecimal(value string) (string, int, error) {
+	invalid := fmt.Errorf("%w: %q is not a decimal number", ErrInvalidAmount, value)
+
+	mantissa, shift := value, 0
+	if i := strings.IndexAny(value, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(value[i+1:])
+		if err != nil || exp > 18 || exp < -18 {
+			return "", 0, invalid
+		}
+		mantissa, shift = value[:i], exp
+	}
+
+	sign := ""
+	if strings.HasPrefix(mantissa, "-") {
+		sign, mantissa = "-", mantissa[1:]
+	}
+	integer, fraction, _ := strings.Cut(mantissa, ".")
+	if integer == "" || !isDigits(integer) || !isDigits(fraction) || (strings.Contains(mantissa, ".") && fraction == "") {
+		return "", 0, invalid
+	}
+
+	digits := strings.TrimLeft(integer+fraction, "0")
+	scale := len(fraction) - shift
+	if scale < 0 {
+		digits += strings.Repeat("0", -scale)
+		scale = 0
+	}
+	return sign + digits, scale, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Exponent casas decimais da moeda; sem moeda, duas
+func (m Money) Exponent() int {
+	if exponent, ok := currency.Exponent(m.Currency); ok {
+		return exponent
+	}
+	return defaultExponent
+}
+
+// MinorPerUnit quantas unidades menores formam uma unidade da moeda
+func (m Money) MinorPerUnit() int64 {
+	unit := int64(1)
+	for i := 0; i < m.Exponent(); i++ {
+		unit *= 10
+	}
+	return unit
+}
+
+// IsPositive indica se o valor é maior que zero
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+// IsMultipleOf indica se o valor é múltiplo exato de units unidades da moeda
+func (m Money) IsMultipleOf(units int64) bool {
+	step := units * m.MinorPerUnit()
+	return step != 0 && m.Minor%step == 0
+}
+
+// Float64 valor aproximado nas unidades da moeda, para estatísticas e scores
+func (m Money) Float64() float64 {
+	return float64(m.Minor) / float64(m.MinorPerUnit())
+}
+
+// Convert converte para outra moeda pela cotação, arredondando nas casas dela
+func (m Money) Convert(rate float64, code string) Money {
+	return MoneyFromFloat(m.Float64()*rate, code)
+}
+
+// String valor decimal exato nas unidades da moeda, como "1234.56"
+func (m Money) String() string {
+	exponent := m.Exponent()
+	sign, minor := "", m.Minor
+	if minor < 0 {
+		sign = "-"
+	}
+	digits := strconv.FormatUint(absMinor(minor), 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	cut := len(digits) - exponent
+	return sign + digits[:cut] + "." + digits[cut:]
+}
+
+func absMinor(minor int64) uint64 {
+	if minor < 0 {
+		return uint64(-(minor + 1)) + 1
+	}
+	return uint64(minor)
+}
+
+func (m Money) currencyLabel() string {
+	if m.Currency == "" {
+		return "amounts without currency"
+	}
+	return m.Currency
+}
+
+// MarshalJSON escreve o valor como número decimal exato
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON aceita número ou string decimal nas casas da moeda já
+// definida no valor; a moeda em si vem do campo irmão ("currency")
+func (m *Money) UnmarshalJSON(data []byte) error {
+	value := string(bytes.TrimSpace(data))
+	if value == "null" {
+		return nil
+	}
+	if strings.HasPrefix(value, `"`) {
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseMoney(value, m.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+// decodeMoney decodifica um valor cuja moeda está em outro campo do objeto
+func decodeMoney(raw json.RawMessage, code string) (Money, error) {
+	money := NewMoney(0, code)
+	if len(raw) == 0 {
+		return money, nil
+	}
+	if !currency.Valid(money.Currency) {
+		// Moeda inválida é rejeitada pela validação do serviço
+		money.Currency = ""
+	}
+	if err := money.UnmarshalJSON(raw); err != nil {
+		return Money{}, err
+	}
+	if money.Currency == "" {
+		money.Currency = currency.Normalize(code)
+	}
+	return money, nil
+}
@@ -0,0 +1,170 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		value, code string
+		want        Money
+		err         error
+	}{
+		{"1234.56", "BRL", NewMoney(123456, "BRL"), nil},
+		{"1234.5", "BRL", NewMoney(123450, "BRL"), nil},
+		{"1234", "BRL", NewMoney(123400, "BRL"), nil},
+		{"0.01", "BRL", NewMoney(1, "BRL"), nil},
+		{"-12.34", "BRL", NewMoney(-1234, "BRL"), nil},
+		{"450", "JPY", NewMoney(450, "JPY"), nil},
+		{"450.00", "JPY", NewMoney(450, "JPY"), nil},
+		{"1.234", "KWD", NewMoney(1234, "KWD"), nil},
+		{"1.2345", "CLF", NewMoney(12345, "CLF"), nil},
+		{"12.340", "BRL", NewMoney(1234, "BRL"), nil},
+		{"1e3", "BRL", NewMoney(100000, "BRL"), nil},
+		{"1.5E2", "JPY", NewMoney(150, "JPY"), nil},
+		{"125e-2", "BRL", NewMoney(125, "BRL"), nil},
+		{"10.5", "", Money{Minor: 1050}, nil},
+		{"100.5", "JPY", Money{}, ErrInvalidAmount},
+		{"12.345", "BRL", Money{}, ErrInvalidAmount},
+		{"1.23456", "CLF", Money{}, ErrInvalidAmount},
+		{"1e-3", "BRL", Money{}, ErrInvalidAmount},
+		{"99999999999999999999", "BRL", Money{}, ErrInvalidAmount},
+		{"1e19", "BRL", Money{}, ErrInvalidAmount},
+		{"10", "XYZ", Money{}, ErrInvalidAmount},
+		{"", "BRL", Money{}, ErrInvalidAmount},
+		{"1.", "BRL", Money{}, ErrInvalidAmount},
+		{".5", "BRL", Money{}, ErrInvalidAmount},
+		{"1,5", "BRL", Money{}, ErrInvalidAmount},
+		{"+1", "BRL", Money{}, ErrInvalidAmount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+" "+tt.code, func(t *testing.T) {
+			got, err := ParseMoney(tt.value, tt.code)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{NewMoney(123456, "BRL"), "1234.56"},
+		{NewMoney(5, "BRL"), "0.05"},
+		{NewMoney(-5, "BRL"), "-0.05"},
+		{NewMoney(0, "BRL"), "0.00"},
+		{NewMoney(450, "JPY"), "450"},
+		{NewMoney(1234, "KWD"), "1.234"},
+		{NewMoney(12345, "CLF"), "1.2345"},
+		{Money{Minor: 1050}, "10.50"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.String(); got != tt.want {
+			t.Errorf("%+v: got %s, want %s", tt.money, got, tt.want)
+		}
+	}
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	tests := []struct {
+		name string
+		got  Money
+		want Money
+	}{
+		{"float rounds half away from zero", MoneyFromFloat(0.125, "BRL"), NewMoney(13, "BRL")},
+		{"float without minor unit", MoneyFromFloat(449.5, "JPY"), NewMoney(450, "JPY")},
+		{"float with three decimals", MoneyFromFloat(1.2345, "KWD"), NewMoney(1235, "KWD")},
+		{"convert to more decimals", NewMoney(10000, "JPY").Convert(0.036, "BRL"), NewMoney(36000, "BRL")},
+		{"convert to fewer decimals", NewMoney(100, "BRL").Convert(27.5, "JPY"), NewMoney(28, "JPY")},
+		{"convert from three decimals", NewMoney(1234, "KWD").Convert(17.65, "BRL"), NewMoney(2178, "BRL")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %+v, want %+v", tt.got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoneyIsMultipleOf(t *testing.T) {
+	tests := []struct {
+		money Money
+		units int64
+		want  bool
+	}{
+		{NewMoney(100000, "BRL"), 1000, true},
+		{NewMoney(100001, "BRL"), 1000, false},
+		{NewMoney(5000, "JPY"), 1000, true},
+		{NewMoney(5000, "KWD"), 1, true},
+		{NewMoney(5500, "KWD"), 1, false},
+		{NewMoney(100000, "BRL"), 0, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.money.IsMultipleOf(tt.units); got != tt.want {
+			t.Errorf("%s %s multiple of %d = %v, want %v", tt.money, tt.money.Currency, tt.units, got, tt.want)
+		}
+	}
+}
+
+func TestProfileAverageJSONRoundTrip(t *testing.T) {
+	tests := []Money{
+		NewMoney(450, "JPY"),
+		NewMoney(1234, "KWD"),
+		NewMoney(12345, "CLF"),
+		NewMoney(123456, "BRL"),
+	}
+
+	for _, average := range tests {
+		t.Run(average.Currency, func(t *testing.T) {
+			data, err := json.Marshal(UserProfile{UserID: "U1", AvgTransactionValue: average})
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var profile UserProfile
+			if err := json.Unmarshal(data, &profile); err != nil {
+				t.Fatalf("Unmarshal(%s): %v", data, err)
+			}
+			if profile.AvgTransactionValue != average {
+				t.Errorf("%s decoded as %+v, want %+v", data, profile.AvgTransactionValue, average)
+			}
+		})
+	}
+}
+
+func TestLegacyProfileAverageUsesDefaultExponent(t *testing.T) {
+	var profile UserProfile
+	if err := json.Unmarshal([]byte(`{"user_id": "U1", "avg_transaction_value": 500}`), &profile); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if want := (Money{Minor: 50000}); profile.AvgTransactionValue != want {
+		t.Errorf("average = %+v, want %+v", profile.AvgTransactionValue, want)
+	}
+}
+
+func TestFXConversionJSONRoundTrip(t *testing.T) {
+	conversion := FXConversion{Currency: "USD", BaseCurrency: "JPY", Rate: 150, BaseAmount: NewMoney(15075, "JPY")}
+	data, err := json.Marshal(conversion)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	var decoded FXConversion
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal(%s): %v", data, err)
+	}
+	if decoded.BaseAmount != conversion.BaseAmount {
+		t.Errorf("%s decoded as %+v, want %+v", data, decoded.BaseAmount, conversion.BaseAmount)
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Dimensões de agrupamento de pares
+const (
+	PeerDimensionCountry    = "country"
+	PeerDimensionCardType   = "card_type"
+	PeerDimensionDeviceType = "device_type"
+	PeerDimensionMerchant   = "merchant"
+)
+
+// PeerGroupKey identifica um grupo de pares (ex.: country=BR)
+type PeerGroupKey struct {
+	Dimension string `json:"dimension"`
+	Value     string `json:"value"`
+}
+
+// PeerGroupStats estatísticas agregadas de comportamento de um grupo de pares
+type PeerGroupStats struct {
+	PeerGroupKey
+	Count       int       `json:"count"`
+	AmountMean  float64   `json:"amount_mean"`
+	AmountM2    float64   `json:"-"`
+	AmountStd   float64   `json:"amount_std_dev"`
+	LastUpdated time.Time `json:"last_updated"`
+}
+
+// Observe incorpora o valor de uma transação às estatísticas do grupo
+func (p *PeerGroupStats) Observe(amount float64, at time.Time) {
+	p.Count++
+	delta := amount - p.AmountMean
+	p.AmountMean += delta / float64(p.Count)
+	p.AmountM2 += delta * (amount - p.AmountMean)
+	p.AmountStd = p.AmountStdDev()
+	p.LastUpdated = at
+}
+
+// AmountStdDev desvio padrão amostral dos valores do grupo
+func (p *PeerGroupStats) AmountStdDev() float64 {
+	if p.Count < 2 {
+		return 0
+	}
+	return math.Sqrt(p.AmountM2 / float64(p.Count-1))
+}
+
+// PeerGroupKeys retorna os grupos de pares aos quais uma transação pertence
+func PeerGroupKeys(transaction *Transaction) []PeerGroupKey {
+	candidates := []PeerGroupKey{
+		{Dimension: PeerDimensionCountry, Value: transaction.Location.Country},
+		{Dimension: PeerDimensionCardType, Value: transaction.CardType},
+		{Dimension: PeerDimensionDeviceType, Value: transaction.DeviceInfo.DeviceType},
+		{Dimension: PeerDimensionMerchant, Value: transaction.Merchant},
+	}
+
+	keys := make([]PeerGroupKey, 0, len(candidates))
+	for _, key := range candidates {
+		if key.Value != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,222 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Tipos de chave PIX do DICT
+const (
+	PIXKeyCPF   = "CPF"
+	PIXKeyCNPJ  = "CNPJ"
+	PIXKeyEmail = "EMAIL"
+	PIXKeyPhone = "PHONE"
+	PIXKeyEVP   = "EVP" // chave aleatória
+)
+
+// Tipos de marcação de recebedor usado como conta laranja
+const (
+	MuleFlagKey     = "key"
+	MuleFlagAccount = "account"
+)
+
+// Período noturno do PIX (Resolução BCB nº 142/2021): começa às 20h ou, por
+// escolha do cliente, às 22h e termina às 6h. O limite noturno padrão é R$ 1.000.
+const (
+	DefaultPIXNightStart = 20
+	PIXNightEnd          = 6
+)
+
+// pixLocation fuso dos períodos do PIX; a base de fusos vem embutida pelo
+// pacote geo, e sem ela vale UTC-3, já que o Brasil não tem mais horário de verão
+var pixLocation = func() *time.Location {
+	location, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		return time.FixedZone("BRT", -3*60*60)
+	}
+	return location
+}()
+
+// DefaultPIXNightLimit limite padrão do período noturno
+var DefaultPIXNightLimit = NewMoney(100000, "BRL")
+
+// PIXTransfer recebedor de uma transferência PIX
+type PIXTransfer struct {
+	KeyType          string     `json:"key_type"`
+	Key              string     `json:"key"`
+	KeyCreatedAt     *time.Time `json:"key_created_at,omitempty"` // registro da chave no DICT
+	RecipientISPB    string     `json:"recipient_ispb,omitempty"` // participante do recebedor
+	RecipientBranch  string     `json:"recipient_branch,omitempty"`
+	RecipientAccount string     `json:"recipient_account,omitempty"`
+}
+
+// KeyID identifica a chave junto com o tipo, como "EMAIL:fulano@exemplo.com"
+func (p *PIXTransfer) KeyID() string {
+	if p.Key == "" {
+		return ""
+	}
+	return p.KeyType + ":" + p.Key
+}
+
+// AccountID identifica a conta do recebedor como "ISPB:agência:conta";
+// vazio se a conta não foi informada
+func (p *PIXTransfer) AccountID() string {
+	if p.RecipientISPB == "" || p.RecipientAccount == "" {
+		return ""
+	}
+	return p.RecipientISPB + ":" + p.RecipientBranch + ":" + p.RecipientAccount
+}
+
+// NormalizePIXKey padroniza a chave no formato do DICT e indica se ela é
+// válida para o tipo: CPF e CNPJ só com dígitos, e-mail em minúsculas,
+// telefone em E.164 (+5511999998888) e chave aleatória como UUID minúsculo
+func NormalizePIXKey(keyType, key string) (string, bool) {
+	key = strings.TrimSpace(key)
+	switch keyType {
+	case PIXKeyCPF:
+		key = stripChars(key, ".-")
+		return key, len(key) == 11 && isDigits(key)
+	case PIXKeyCNPJ:
+		key = stripChars(key, ".-/")
+		return key, len(key) == 14 && isDigits(key)
+	case PIXKeyEmail:
+		key = strings.ToLower(key)
+		at := strings.LastIndex(key, "@")
+		return key, at > 0 && at < len(key)-1 && len(key) <= 77 && !strings.ContainsAny(key, " \t")
+	case PIXKeyPhone:
+		key = stripChars(key, " -()")
+		digits := strings.TrimPrefix(key, "+")
+		return key, strings.HasPrefix(key, "+") && len(digits) >= 8 && len(digits) <= 15 && isDigits(digits) && digits[0] != '0'
+	case PIXKeyEVP:
+		key = strings.ToLower(key)
+		return key, isUUID(key)
+	}
+	return key, false
+}
+
+func stripChars(s, chars string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(chars, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i, c := range s {
+		switch i {
+		case 8, 13, 18, 23:
+			if c != '-' {
+				return false
+			}
+		default:
+			if !strings.ContainsRune("0123456789abcdef", c) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MuleFlag marcação de chave ou conta recebedora usada como conta laranja
+type MuleFlag struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`  // "key" ou "account"
+	Value     string    `json:"value"` // KeyID ou AccountID do recebedor
+	Reason    string    `json:"reason,omitempty"`
+	FlaggedAt time.Time `json:"flagged_at"`
+}
+
+// PIXUsage total transferido via PIX por um usuário em um período
+type PIXUsage struct {
+	UserID string    `json:"user_id"`
+	Count  int       `json:"count"`
+	Total  Money     `json:"total"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+}
+
+// PIXLimits limites PIX do usuário para o total transferido em cada período.
+// Limite zero significa sem limite no período.
+type PIXLimits struct {
+	UserID     string          `json:"user_id"`
+	Daytime    Money           `json:"daytime_limit"`
+	Nighttime  Money           `json:"nighttime_limit"`
+	NightStart int             `json:"night_start_hour"`  // 20 ou 22
+	Pending    *PIXLimitChange `json:"pending,omitempty"` // aumento aguardando o prazo mínimo
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// PIXLimitChange aumento de limite solicitado que só vale a partir de EffectiveAt
+type PIXLimitChange struct {
+	Daytime     Money     `json:"daytime_limit"`
+	Nighttime   Money     `json:"nighttime_limit"`
+	NightStart  int       `json:"night_start_hour"`
+	RequestedAt time.Time `json:"requested_at"`
+	EffectiveAt time.Time `json:"effective_at"`
+}
+
+// DefaultPIXLimits limites de quem nunca configurou os seus: sem limite
+// diurno e o limite noturno padrão a partir das 20h
+func DefaultPIXLimits(userID string) PIXLimits {
+	return PIXLimits{
+		UserID:     userID,
+		Daytime:    NewMoney(0, "BRL"),
+		Nighttime:  DefaultPIXNightLimit,
+		NightStart: DefaultPIXNightStart,
+	}
+}
+
+// At limites em vigor no instante, aplicando o aumento pendente já liberado
+func (l PIXLimits) At(at time.Time) PIXLimits {
+	if l.Pending != nil && !at.Before(l.Pending.EffectiveAt) {
+		l.Daytime = l.Pending.Daytime
+		l.Nighttime = l.Pending.Nighttime
+		l.NightStart = l.Pending.NightStart
+		l.Pending = nil
+	}
+	return l
+}
+
+// Period período (diurno ou noturno) que contém o horário informado, com
+// início e fim no mesmo fuso
+func (l PIXLimits) Period(local time.Time) (from, to time.Time, night bool) {
+	nightStart := l.NightStart
+	if nightStart == 0 {
+		nightStart = DefaultPIXNightStart
+	}
+	year, month, day := local.Date()
+	at := func(offset, hour int) time.Time {
+		return time.Date(year, month, day+offset, hour, 0, 0, 0, local.Location())
+	}
+
+	switch hour := local.Hour(); {
+	case hour >= nightStart:
+		return at(0, nightStart), at(1, PIXNightEnd), true
+	case hour < PIXNightEnd:
+		return at(-1, nightStart), at(0, PIXNightEnd), true
+	default:
+		return at(0, PIXNightEnd), at(0, nightStart), false
+	}
+}
+
+// Limit limite do período; night indica o período noturno
+func (l PIXLimits) Limit(night bool) Money {
+	if night {
+		return l.Nighttime
+	}
+	return l.Daytime
+}
+
+// Window limites em vigor no horário da transferência e o período que ela
+// consome, com o limite desse período. Os períodos seguem sempre o horário de
+// Brasília: a localização vem do cliente e não pode mudar o limite noturno.
+func (l PIXLimits) Window(transaction *Transaction) (from, to time.Time, night bool, limit Money) {
+	current := l.At(transaction.Timestamp)
+	from, to, night = current.Period(transaction.Timestamp.In(pixLocation))
+	return from, to, night, current.Limit(night)
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPIXLimitsPeriod(t *testing.T) {
+	brt := time.FixedZone("BRT", -3*60*60)
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, 3, day, hour, minute, 0, 0, brt)
+	}
+
+	tests := []struct {
+		name       string
+		nightStart int
+		local      time.Time
+		from, to   time.Time
+		night      bool
+	}{
+		{"day", 20, at(10, 12, 0), at(10, 6, 0), at(10, 20, 0), false},
+		{"day starts at 6h", 20, at(10, 6, 0), at(10, 6, 0), at(10, 20, 0), false},
+		{"night from 20h", 20, at(10, 20, 0), at(10, 20, 0), at(11, 6, 0), true},
+		{"21h is still day with night at 22h", 22, at(10, 21, 0), at(10, 6, 0), at(10, 22, 0), false},
+		{"night from 22h", 22, at(10, 23, 30), at(10, 22, 0), at(11, 6, 0), true},
+		{"after midnight belongs to the previous night", 20, at(11, 2, 0), at(10, 20, 0), at(11, 6, 0), true},
+		{"after midnight with night at 22h", 22, at(11, 5, 59), at(10, 22, 0), at(11, 6, 0), true},
+		{"night start not set", 0, at(10, 20, 30), at(10, 20, 0), at(11, 6, 0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, night := PIXLimits{NightStart: tt.nightStart}.Period(tt.local)
+			if !from.Equal(tt.from) || !to.Equal(tt.to) || night != tt.night {
+				t.Errorf("got [%s, %s) night=%v, want [%s, %s) night=%v", from, to, night, tt.from, tt.to, tt.night)
+			}
+		})
+	}
+}
+
+func TestPIXLimitsAt(t *testing.T) {
+	requested := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	limits := PIXLimits{
+		Daytime:    NewMoney(500000, "BRL"),
+		Nighttime:  NewMoney(50000, "BRL"),
+		NightStart: 20,
+		Pending: &PIXLimitChange{
+			Daytime:     NewMoney(1000000, "BRL"),
+			Nighttime:   NewMoney(100000, "BRL"),
+			NightStart:  22,
+			RequestedAt: requested,
+			EffectiveAt: requested.Add(24 * time.Hour),
+		},
+	}
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		daytime   int64
+		nighttime int64
+		start     int
+	}{
+		{"right after the request", requested, 500000, 50000, 20},
+		{"one minute before the delay", requested.Add(24*time.Hour - time.Minute), 500000, 50000, 20},
+		{"when the delay ends", requested.Add(24 * time.Hour), 1000000, 100000, 22},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := limits.At(tt.at)
+			if got.Daytime.Minor != tt.daytime || got.Nighttime.Minor != tt.nighttime || got.NightStart != tt.start {
+				t.Errorf("got %s/%s from %dh, want %d/%d from %dh", got.Daytime, got.Nighttime, got.NightStart, tt.daytime, tt.nighttime, tt.start)
+			}
+			if applied := got.Pending == nil; applied != !tt.at.Before(limits.Pending.EffectiveAt) {
+				t.Errorf("pending change kept = %v", !applied)
+			}
+		})
+	}
+}
+
+func TestPIXLimitsWindowUsesBrasiliaTime(t *testing.T) {
+	limits := DefaultPIXLimits("U1")
+	at := time.Date(2026, 3, 10, 7, 0, 0, 0, time.UTC) // 4h em Brasília
+
+	tests := []struct {
+		name     string
+		location Location
+		at       time.Time
+	}{
+		{"brazil", Location{Country: "BR"}, at},
+		{"no country", Location{}, at},
+		{"foreign country", Location{Country: "JP", Latitude: 35.68, Longitude: 139.69}, at},
+		{"timestamp with a foreign offset", Location{}, at.In(time.FixedZone("JST", 9*60*60))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &Transaction{Timestamp: tt.at, Location: tt.location}
+			from, to, night, limit := limits.Window(transaction)
+			if !night || limit != DefaultPIXNightLimit {
+				t.Fatalf("night = %v, limit = %s, want night limit %s", night, limit, DefaultPIXNightLimit)
+			}
+			wantFrom := time.Date(2026, 3, 9, 23, 0, 0, 0, time.UTC)
+			wantTo := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+			if !from.Equal(wantFrom) || !to.Equal(wantTo) {
+				t.Errorf("period = [%s, %s), want [%s, %s)", from, to, wantFrom, wantTo)
+			}
+		})
+	}
+}
@@ -1,20 +1,53 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // Transaction representa uma transação financeira
 type Transaction struct {
-	ID          string     `json:"transaction_id"`
-	UserID      string     `json:"user_id"`
-	Amount      float64    `json:"amount"`
-	Currency    string     `json:"currency"`
-	Merchant    string     `json:"merchant"`
-	Location    Location   `json:"location"`
-	DeviceInfo  DeviceInfo `json:"device_info,omitempty"`
-	Timestamp   time.Time  `json:"timestamp"`
-	CardLast4   string     `json:"card_last4,omitempty"`
-	CardType    string     `json:"card_type,omitempty"`
-	Description string     `json:"description,omitempty"`
+	ID              string         `json:"transaction_id"`
+	UserID          string         `json:"user_id"`
+	Amount          Money          `json:"amount"` // exato, nas casas decimais de Currency
+	Currency        string         `json:"currency"`
+	Merchant        string         `json:"merchant"`
+	Location        Location       `json:"location"`
+	DeviceInfo      DeviceInfo     `json:"device_info,omitempty"`
+	Timestamp       time.Time      `json:"timestamp"`                  // horário do evento
+	ReceivedAt      time.Time      `json:"received_at"`                // horário de recebimento pelo serviço
+	CardBIN         string         `json:"card_bin,omitempty"`         // primeiros 6 a 8 dígitos do cartão
+	CardLast4       string         `json:"card_last4,omitempty"`       // apenas para exibição
+	CardFingerprint string         `json:"card_fingerprint,omitempty"` // token de rede ou hash com chave do PAN
+	CardType        string         `json:"card_type,omitempty"`
+	CardCountry     string         `json:"card_country,omitempty"`   // país emissor do cartão
+	IPCountry       string         `json:"ip_country,omitempty"`     // país de origem do IP
+	IPGeolocation   *IPGeolocation `json:"ip_geolocation,omitempty"` // preenchido pela base de geolocalização
+	IPReputation    *IPReputation  `json:"ip_reputation,omitempty"`  // preenchido pelas listas de anonimizadores
+	CardIssuer      *CardIssuer    `json:"card_issuer,omitempty"`    // preenchido pela tabela de BINs
+	PIX             *PIXTransfer   `json:"pix,omitempty"`            // recebedor, apenas em transferências PIX
+	TravelNotice    *TravelNotice  `json:"travel_notice,omitempty"`  // aviso de viagem ativo que cobre a transação
+	FXConversion    *FXConversion  `json:"fx,omitempty"`             // valor na moeda base, preenchido pelas cotações
+	Description     string         `json:"description,omitempty"`
+}
+
+// UnmarshalJSON lê o valor nas casas decimais da moeda da transação
+func (t *Transaction) UnmarshalJSON(data []byte) error {
+	type plain Transaction
+	aux := struct {
+		*plain
+		Amount json.RawMessage `json:"amount"`
+	}{plain: (*plain)(t)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	amount, err := decodeMoney(aux.Amount, t.Currency)
+	if err != nil {
+		return err
+	}
+	t.Amount = amount
+	return nil
 }
 
 // Location representa a localização geográfica
@@ -26,6 +59,11 @@ type Location struct {
 	IPAddress string  `json:"ip_address,omitempty"`
 }
 
+// HasCoordinates indica se a localização tem latitude e longitude informadas
+func (l Location) HasCoordinates() bool {
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
 // DeviceInfo contém informações do dispositivo
 type DeviceInfo struct {
 	DeviceID     string `json:"device_id"`
@@ -44,7 +82,16 @@ type FraudAnalysisResult struct {
 	Decision        Decision            `json:"decision"`
 	Reasons         []string            `json:"reasons"`
 	RulesTriggered  []string            `json:"rules_triggered"`
+	TriggeredRuleIDs []string           `json:"triggered_rule_ids"`
+	RuleSetVersion  int                 `json:"rule_set_version"`
+	SkippedRules    []string            `json:"skipped_rules,omitempty"`
+	TimedOutRules   []string            `json:"timed_out_rules,omitempty"`
+	ErroredRules    []string            `json:"errored_rules,omitempty"`
+	Degraded        bool                `json:"degraded"`
+	DegradedReasons []string            `json:"degraded_reasons,omitempty"`
 	Details         map[string]interface{} `json:"details,omitempty"`
+	EventTime       time.Time           `json:"event_time"`
+	ReceivedAt      time.Time           `json:"received_at"`
 	AnalyzedAt      time.Time           `json:"analyzed_at"`
 	ProcessingTime  int64               `json:"processing_time_ms"`
 }
@@ -69,15 +116,52 @@ const (
 
 // UserProfile perfil do usuário com histórico
 type UserProfile struct {
-	UserID              string               `json:"user_id"`
-	AvgTransactionValue float64              `json:"avg_transaction_value"`
-	TotalTransactions   int                  `json:"total_transactions"`
-	FirstTransactionAt  time.Time            `json:"first_transaction_at"`
-	LastTransactionAt   time.Time            `json:"last_transaction_at"`
-	CommonLocations     []Location           `json:"common_locations"`
-	CommonMerchants     []string             `json:"common_merchants"`
-	FraudHistory        []FraudIncident      `json:"fraud_history"`
-	TrustedDevices      []string             `json:"trusted_devices"`
+	UserID              string            `json:"user_id"`
+	AvgTransactionValue Money             `json:"avg_transaction_value"` // na moeda base
+	TotalTransactions   int               `json:"total_transactions"`
+	FirstTransactionAt  time.Time         `json:"first_transaction_at"`
+	LastTransactionAt   time.Time         `json:"last_transaction_at"`
+	LastLocation        *Location         `json:"last_location,omitempty"` // local da transação mais recente com coordenadas
+	LastLocationAt      time.Time         `json:"last_location_at"`
+	CommonLocations     []Location        `json:"common_locations"`
+	CommonMerchants     []string          `json:"common_merchants"`
+	FraudHistory        []FraudIncident   `json:"fraud_history"`
+	TrustedDevices      []string          `json:"trusted_devices"`
+	DeviceApprovals     map[string]int    `json:"device_approvals,omitempty"` // aprovações por dispositivo ainda não confiável
+	KnownCards          []string          `json:"known_cards"`                // impressões digitais dos cartões usados
+	KnownPIXKeys        []string          `json:"known_pix_keys,omitempty"`   // chaves PIX que já receberam transferências aprovadas
+	Baseline            *BehaviorBaseline `json:"baseline,omitempty"`
+}
+
+// MarshalJSON grava a moeda da média ao lado dela, para que seja lida de
+// volta nas casas decimais certas mesmo quando a moeda base não usa duas
+func (p UserProfile) MarshalJSON() ([]byte, error) {
+	type plain UserProfile
+	return json.Marshal(struct {
+		plain
+		AvgTransactionCurrency string `json:"avg_transaction_currency,omitempty"`
+	}{plain: plain(p), AvgTransactionCurrency: p.AvgTransactionValue.Currency})
+}
+
+// UnmarshalJSON lê a média nas casas decimais da moeda gravada; perfis antigos,
+// sem a moeda, continuam com duas casas
+func (p *UserProfile) UnmarshalJSON(data []byte) error {
+	type plain UserProfile
+	aux := struct {
+		*plain
+		AvgTransactionValue    json.RawMessage `json:"avg_transaction_value"`
+		AvgTransactionCurrency string          `json:"avg_transaction_currency"`
+	}{plain: (*plain)(p)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	average, err := decodeMoney(aux.AvgTransactionValue, aux.AvgTransactionCurrency)
+	if err != nil {
+		return err
+	}
+	p.AvgTransactionValue = average
+	return nil
 }
 
 // FraudIncident representa um incidente de fraude
@@ -86,10 +170,30 @@ type FraudIncident struct {
 	TransactionID  string    `json:"transaction_id"`
 	DetectedAt     time.Time `json:"detected_at"`
 	ConfirmedFraud bool      `json:"confirmed_fraud"`
-	Amount         float64   `json:"amount"`
+	Amount         Money     `json:"amount"`
+	Currency       string    `json:"currency,omitempty"`
 	Description    string    `json:"description"`
 }
 
+// UnmarshalJSON lê o valor nas casas decimais da moeda do incidente
+func (f *FraudIncident) UnmarshalJSON(data []byte) error {
+	type plain FraudIncident
+	aux := struct {
+		*plain
+		Amount json.RawMessage `json:"amount"`
+	}{plain: (*plain)(f)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	amount, err := decodeMoney(aux.Amount, f.Currency)
+	if err != nil {
+		return err
+	}
+	f.Amount = amount
+	return nil
+}
+
 // Rule representa uma regra de detecção de fraude
 type Rule struct {
 	ID          string   `json:"id"`
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// TravelNotice aviso de viagem informado pelo cliente: países e, opcionalmente,
+// cidades de destino entre as datas de início e fim, inclusivas
+type TravelNotice struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Countries []string  `json:"countries"`
+	Cities    []string  `json:"cities,omitempty"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Maiores diferenças de fuso em relação a UTC (UTC+14 e UTC-12)
+const (
+	maxTimezoneAhead  = 14 * time.Hour
+	maxTimezoneBehind = 12 * time.Hour
+)
+
+// Covers indica se o instante está no período do aviso. As datas valem no
+// fuso do destino, desconhecido aqui, então o período é estendido pelas
+// maiores diferenças de fuso.
+func (n *TravelNotice) Covers(at time.Time) bool {
+	start := n.StartDate.Add(-maxTimezoneAhead)
+	end := n.EndDate.AddDate(0, 0, 1).Add(maxTimezoneBehind)
+	return !at.Before(start) && at.Before(end)
+}
@@ -0,0 +1,116 @@
+package resilience
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen chamada recusada porque o circuito está aberto
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// Estados do circuit breaker
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// CircuitBreaker interrompe chamadas a uma dependência após falhas
+// consecutivas. Depois do cooldown, uma chamada de teste é liberada
+// (meio-aberto): sucesso fecha o circuito, falha o reabre.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	state     string
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	mu        sync.Mutex
+}
+
+// NewCircuitBreaker cria um circuit breaker que abre após threshold falhas
+// consecutivas e permanece aberto por cooldown
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		state:     StateClosed,
+	}
+}
+
+// Execute executa a chamada se o circuito permitir, registrando o resultado.
+// Erros permanentes mostram que a dependência respondeu e contam como sucesso.
+func (b *CircuitBreaker) Execute(call func() error) error {
+	if err := b.allow(); err != nil {
+		return err
+	}
+
+	err := call()
+	if err != nil && !IsPermanent(err) {
+		b.recordFailure()
+		return err
+	}
+
+	b.recordSuccess()
+	return err
+}
+
+// State retorna o estado atual do circuito
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.cooldown {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+func (b *CircuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.state = StateHalfOpen
+		b.probing = true
+		return nil
+	case StateHalfOpen:
+		// Apenas uma chamada de teste por vez
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (b *CircuitBreaker) recordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.probing = false
+}
+
+func (b *CircuitBreaker) recordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+}
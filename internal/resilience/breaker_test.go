@@ -0,0 +1,58 @@
+package resilience
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	failure := errors.New("connection refused")
+	breaker := NewCircuitBreaker(3, 20*time.Millisecond)
+
+	for i := 0; i < 2; i++ {
+		if err := breaker.Execute(func() error { return failure }); err != failure {
+			t.Fatalf("call %d: error = %v", i, err)
+		}
+	}
+	if state := breaker.State(); state != StateClosed {
+		t.Fatalf("state after 2 failures = %s, want %s", state, StateClosed)
+	}
+
+	// Erros permanentes mostram que a dependência respondeu e zeram a contagem
+	if err := breaker.Execute(func() error { return Permanent(errors.New("not found")) }); !IsPermanent(err) {
+		t.Fatalf("permanent error = %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		breaker.Execute(func() error { return failure })
+	}
+	if state := breaker.State(); state != StateClosed {
+		t.Fatalf("state after a permanent error and 2 failures = %s, want %s", state, StateClosed)
+	}
+
+	breaker.Execute(func() error { return failure })
+	if state := breaker.State(); state != StateOpen {
+		t.Fatalf("state after 3 failures = %s, want %s", state, StateOpen)
+	}
+	called := false
+	if err := breaker.Execute(func() error { called = true; return nil }); err != ErrCircuitOpen || called {
+		t.Fatalf("open circuit: error = %v, called = %v", err, called)
+	}
+
+	// Depois do cooldown, uma chamada de teste: falha reabre, sucesso fecha
+	time.Sleep(25 * time.Millisecond)
+	if state := breaker.State(); state != StateHalfOpen {
+		t.Fatalf("state after cooldown = %s, want %s", state, StateHalfOpen)
+	}
+	breaker.Execute(func() error { return failure })
+	if state := breaker.State(); state != StateOpen {
+		t.Fatalf("state after failed probe = %s, want %s", state, StateOpen)
+	}
+	time.Sleep(25 * time.Millisecond)
+	if err := breaker.Execute(func() error { return nil }); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if state := breaker.State(); state != StateClosed {
+		t.Errorf("state after successful probe = %s, want %s", state, StateClosed)
+	}
+}
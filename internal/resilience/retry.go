@@ -0,0 +1,69 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// permanentError erro que não adianta repetir: a dependência respondeu, e a
+// resposta foi uma recusa (registro inexistente, dado inválido)
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marca o erro como permanente: Retry não o repete e o circuit
+// breaker não o conta como falha. errors.Is e errors.As continuam vendo o
+// erro original.
+func Permanent(err error) error {
+	if err == nil || IsPermanent(err) {
+		return err
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent indica se o erro foi marcado com Permanent
+func IsPermanent(err error) bool {
+	var permanent *permanentError
+	return errors.As(err, &permanent)
+}
+
+// RetryPolicy tentativas adicionais com backoff exponencial
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Retry executa a chamada até ter sucesso, esgotar as tentativas ou o
+// contexto expirar. Só falhas transitórias são repetidas: erros permanentes,
+// circuito aberto e contexto encerrado voltam na hora.
+func Retry(ctx context.Context, policy RetryPolicy, call func() error) error {
+	backoff := policy.InitialBackoff
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		if err = call(); err == nil {
+			return nil
+		}
+		if IsPermanent(err) || errors.Is(err, ErrCircuitOpen) || ctx.Err() != nil || attempt >= policy.MaxRetries {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+			backoff = policy.MaxBackoff
+		}
+	}
+}
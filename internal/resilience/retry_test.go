@@ -0,0 +1,78 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	transient := errors.New("connection refused")
+	notFound := errors.New("not found")
+
+	tests := []struct {
+		name     string
+		failures []error
+		calls    int
+		err      error
+	}{
+		{"success", nil, 1, nil},
+		{"transient then success", []error{transient, transient}, 3, nil},
+		{"transient until exhausted", []error{transient, transient, transient, transient}, 3, transient},
+		{"permanent is not retried", []error{Permanent(notFound)}, 1, notFound},
+		{"wrapped permanent is not retried", []error{fmt.Errorf("loading: %w", Permanent(notFound))}, 1, notFound},
+		{"open circuit is not retried", []error{ErrCircuitOpen}, 1, ErrCircuitOpen},
+	}
+
+	policy := RetryPolicy{MaxRetries: 2, InitialBackoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Retry(context.Background(), policy, func() error {
+				calls++
+				if calls <= len(tt.failures) {
+					return tt.failures[calls-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error = %v, want %v", err, tt.err)
+			}
+			if calls != tt.calls {
+				t.Errorf("calls = %d, want %d", calls, tt.calls)
+			}
+		})
+	}
+}
+
+func TestRetryStopsWhenContextEnds(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := Retry(ctx, RetryPolicy{MaxRetries: 5, InitialBackoff: time.Hour}, func() error {
+		calls++
+		cancel()
+		return errors.New("timeout")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("calls = %d, error = %v; want one call and the error", calls, err)
+	}
+}
+
+func TestPermanent(t *testing.T) {
+	base := errors.New("invalid")
+	if Permanent(nil) != nil {
+		t.Error("Permanent(nil) is not nil")
+	}
+	if IsPermanent(base) {
+		t.Error("plain error reported as permanent")
+	}
+	err := Permanent(base)
+	if !IsPermanent(err) || !errors.Is(err, base) || err.Error() != base.Error() {
+		t.Errorf("Permanent(%v) = %v, permanent %v", base, err, IsPermanent(err))
+	}
+	if Permanent(err) != err {
+		t.Error("permanent error wrapped twice")
+	}
+}
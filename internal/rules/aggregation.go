@@ -0,0 +1,139 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+)
+
+// Estratégias de agregação de score disponíveis
+const (
+	AggregationSum          = "sum"
+	AggregationNoisyOr      = "noisy_or"
+	AggregationMaxPlusBonus = "max_plus_bonus"
+	AggregationLogistic     = "logistic"
+)
+
+// ScoreAggregator combina os scores das regras acionadas em um score de 0 a 100
+type ScoreAggregator interface {
+	Name() string
+	Aggregate(results []RuleResult) int
+}
+
+// SumAggregator soma os scores e limita em 100 (comportamento original)
+type SumAggregator struct{}
+
+func (a *SumAggregator) Name() string { return AggregationSum }
+
+func (a *SumAggregator) Aggregate(results []RuleResult) int {
+	total := 0
+	for _, result := range results {
+		total += result.Score
+	}
+	return clampScore(total)
+}
+
+// NoisyOrAggregator trata cada score como a probabilidade independente de
+// fraude e combina com 1 - Π(1 - p). Duas regras fracas somam menos que uma forte.
+type NoisyOrAggregator struct{}
+
+func (a *NoisyOrAggregator) Name() string { return AggregationNoisyOr }
+
+func (a *NoisyOrAggregator) Aggregate(results []RuleResult) int {
+	notFraud := 1.0
+	for _, result := range results {
+		p := math.Min(math.Max(float64(result.Score)/100, 0), 1)
+		notFraud *= 1 - p
+	}
+	return clampScore(int(math.Round((1 - notFraud) * 100)))
+}
+
+// MaxPlusBonusAggregator usa o maior score e adiciona um bônus fixo por
+// regra adicional acionada
+type MaxPlusBonusAggregator struct {
+	BonusPerRule int
+}
+
+func (a *MaxPlusBonusAggregator) Name() string { return AggregationMaxPlusBonus }
+
+func (a *MaxPlusBonusAggregator) Aggregate(results []RuleResult) int {
+	if len(results) == 0 {
+		return 0
+	}
+
+	max := 0
+	for _, result := range results {
+		if result.Score > max {
+			max = result.Score
+		}
+	}
+	return clampScore(max + a.BonusPerRule*(len(results)-1))
+}
+
+// LogisticAggregator combina os scores com pesos por regra em uma função
+// logística: 100 * sigmoid(bias + Σ peso * score)
+type LogisticAggregator struct {
+	Bias          float64
+	DefaultWeight float64
+	Weights       map[string]float64
+}
+
+func (a *LogisticAggregator) Name() string { return AggregationLogistic }
+
+func (a *LogisticAggregator) Aggregate(results []RuleResult) int {
+	if len(results) == 0 {
+		return 0
+	}
+
+	z := a.Bias
+	for _, result := range results {
+		weight, exists := a.Weights[result.RuleID]
+		if !exists {
+			weight = a.DefaultWeight
+		}
+		z += weight * float64(result.Score)
+	}
+	return clampScore(int(math.Round(100 / (1 + math.Exp(-z)))))
+}
+
+// NewScoreAggregator cria o agregador descrito na configuração
+func NewScoreAggregator(config AggregationConfig) (ScoreAggregator, error) {
+	switch config.Strategy {
+	case "", AggregationSum:
+		return &SumAggregator{}, nil
+	case AggregationNoisyOr:
+		return &NoisyOrAggregator{}, nil
+	case AggregationMaxPlusBonus:
+		bonus := 5
+		if config.BonusPerRule != nil {
+			bonus = *config.BonusPerRule
+		}
+		return &MaxPlusBonusAggregator{BonusPerRule: bonus}, nil
+	case AggregationLogistic:
+		bias := -4.0
+		if config.Bias != nil {
+			bias = *config.Bias
+		}
+		defaultWeight := 0.1
+		if config.DefaultWeight != nil {
+			defaultWeight = *config.DefaultWeight
+		}
+		return &LogisticAggregator{
+			Bias:          bias,
+			DefaultWeight: defaultWeight,
+			Weights:       config.Weights,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown aggregation strategy %q", config.Strategy)
+	}
+}
+
+// clampScore limita o score entre 0 e 100
+func clampScore(score int) int {
+	if score > 100 {
+		return 100
+	}
+	if score < 0 {
+		return 0
+	}
+	return score
+}
@@ -0,0 +1,140 @@
+package rules
+
+import (
+	"context"
+	"math"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// Pesos de cada dimensão no score de anomalia
+const (
+	anomalyAmountWeight   = 0.40
+	anomalyHourWeight     = 0.20
+	anomalyMerchantWeight = 0.15
+	anomalyCountryWeight  = 0.25
+)
+
+// BehaviorAnomalyRule detecta transações incomuns para o próprio usuário,
+// comparando-as com o baseline estatístico mantido no perfil
+type BehaviorAnomalyRule struct {
+	Weight            int
+	MinObservations   int
+	ZScoreThreshold   float64
+	MinHourLikelihood float64
+	TriggerThreshold  float64
+}
+
+func (r *BehaviorAnomalyRule) GetID() string   { return "behavior_anomaly_rule" }
+func (r *BehaviorAnomalyRule) GetName() string { return "User Behavior Anomaly" }
+func (r *BehaviorAnomalyRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 20
+	}
+	return r.Weight
+}
+func (r *BehaviorAnomalyRule) GetPriority() int { return PriorityLookup }
+func (r *BehaviorAnomalyRule) IsEnabled() bool  { return true }
+
+func (r *BehaviorAnomalyRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	minObservations := 10
+	if r.MinObservations > 0 {
+		minObservations = r.MinObservations
+	}
+	zThreshold := 3.0
+	if r.ZScoreThreshold > 0 {
+		zThreshold = r.ZScoreThreshold
+	}
+	minHourLikelihood := 0.05
+	if r.MinHourLikelihood > 0 {
+		minHourLikelihood = r.MinHourLikelihood
+	}
+	triggerThreshold := 0.4
+	if r.TriggerThreshold > 0 {
+		triggerThreshold = r.TriggerThreshold
+	}
+
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Transação foge do comportamento habitual do usuário",
+		Details:     map[string]interface{}{},
+	}
+
+	if profile == nil || profile.Baseline == nil || profile.Baseline.Count < minObservations {
+		return result
+	}
+
+	baseline := profile.Baseline
+	total := float64(baseline.Count)
+	anomaly := 0.0
+	dimensions := make([]map[string]interface{}, 0)
+
+	// Valor: z-score em relação à média do usuário
+	if stdDev := baseline.AmountStdDev(); stdDev > 0 {
+		z := (transaction.AmountInBase().Float64() - baseline.AmountMean) / stdDev
+		if z > zThreshold {
+			deviation := math.Min(z/(2*zThreshold), 1)
+			anomaly += anomalyAmountWeight * deviation
+			dimensions = append(dimensions, map[string]interface{}{
+				"dimension": "amount",
+				"value":     transaction.AmountInBase(),
+				"mean":      baseline.AmountMean,
+				"std_dev":   stdDev,
+				"z_score":   z,
+				"deviation": deviation,
+			})
+		}
+	}
+
+	// Horário: frequência suavizada da hora local e das horas vizinhas
+	local, _ := transaction.LocalTime()
+	hour := local.Hour()
+	hourCount := baseline.HourCounts[hour] +
+		baseline.HourCounts[(hour+23)%24] +
+		baseline.HourCounts[(hour+1)%24]
+	hourLikelihood := (float64(hourCount) + 1) / (total + 24)
+	if hourLikelihood < minHourLikelihood {
+		deviation := 1 - hourLikelihood/minHourLikelihood
+		anomaly += anomalyHourWeight * deviation
+		dimensions = append(dimensions, map[string]interface{}{
+			"dimension":  "hour",
+			"value":      hour,
+			"likelihood": hourLikelihood,
+			"deviation":  deviation,
+		})
+	}
+
+	// Estabelecimento nunca utilizado pelo usuário
+	if transaction.Merchant != "" && baseline.MerchantCounts[transaction.Merchant] == 0 {
+		anomaly += anomalyMerchantWeight
+		dimensions = append(dimensions, map[string]interface{}{
+			"dimension":  "merchant",
+			"value":      transaction.Merchant,
+			"likelihood": 1 / (total + float64(len(baseline.MerchantCounts)) + 1),
+			"deviation":  1.0,
+		})
+	}
+
+	// País nunca utilizado pelo usuário
+	country := transaction.Location.Country
+	if country != "" && baseline.CountryCounts[country] == 0 {
+		anomaly += anomalyCountryWeight
+		dimensions = append(dimensions, map[string]interface{}{
+			"dimension":  "country",
+			"value":      country,
+			"likelihood": 1 / (total + float64(len(baseline.CountryCounts)) + 1),
+			"deviation":  1.0,
+		})
+	}
+
+	result.Details["anomaly_score"] = anomaly
+	result.Details["dimensions"] = dimensions
+
+	if anomaly >= triggerThreshold {
+		result.Triggered = true
+		result.Score = int(math.Round(float64(r.GetWeight()) * anomaly))
+	}
+
+	return result
+}
@@ -0,0 +1,240 @@
+package rules
+
+import (
+	"context"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// PrepaidCardRule detecta cartões pré-pagos, preferidos em fraudes por não
+// exigirem vínculo bancário
+type PrepaidCardRule struct {
+	Weight int
+}
+
+func (r *PrepaidCardRule) GetID() string   { return "prepaid_card_rule" }
+func (r *PrepaidCardRule) GetName() string { return "Prepaid Card" }
+func (r *PrepaidCardRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 15
+	}
+	return r.Weight
+}
+func (r *PrepaidCardRule) GetPriority() int { return PriorityCheap }
+func (r *PrepaidCardRule) IsEnabled() bool  { return true }
+
+func (r *PrepaidCardRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Transação com cartão pré-pago",
+		Details:     map[string]interface{}{},
+	}
+
+	issuer := transaction.CardIssuer
+	if issuer == nil || issuer.ProductType != models.CardProductPrepaid {
+		return result
+	}
+
+	result.Triggered = true
+	result.Score = r.GetWeight()
+	result.Details["bin"] = issuer.BIN
+	result.Details["bank"] = issuer.Bank
+	result.Details["commercial"] = issuer.Commercial
+
+	return result
+}
+
+// ForeignCardRule detecta cartões emitidos fora do país de origem do usuário
+// ou, sem histórico, fora do país da transação
+type ForeignCardRule struct {
+	Weight int
+}
+
+func (r *ForeignCardRule) GetID() string   { return "foreign_card_rule" }
+func (r *ForeignCardRule) GetName() string { return "Foreign Issued Card" }
+func (r *ForeignCardRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 15
+	}
+	return r.Weight
+}
+func (r *ForeignCardRule) GetPriority() int { return PriorityCheap }
+func (r *ForeignCardRule) IsEnabled() bool  { return true }
+
+func (r *ForeignCardRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Cartão emitido em outro país",
+		Details:     map[string]interface{}{},
+	}
+
+	cardCountry := normalizeCountry(transaction.CardCountry)
+	if cardCountry == "" {
+		return result
+	}
+
+	reference, source := homeCountry(profile), CountrySourceHome
+	if reference == "" {
+		reference, source = normalizeCountry(transaction.Location.Country), CountrySourceDeclared
+	}
+	if reference == "" {
+		return result
+	}
+
+	result.Details["card_country"] = cardCountry
+	result.Details["reference_country"] = reference
+	result.Details["reference"] = source
+	if transaction.CardIssuer != nil {
+		result.Details["bank"] = transaction.CardIssuer.Bank
+	}
+
+	if cardCountry != reference {
+		result.Triggered = true
+		result.Score = r.GetWeight()
+	}
+
+	return result
+}
+
+// BINVelocityRule detecta ataques de BIN: muitos cartões distintos do mesmo
+// BIN testados em poucos minutos, geralmente números gerados em sequência
+type BINVelocityRule struct {
+	Velocity BINVelocityProvider
+	Weight   int
+	Window   time.Duration
+	MaxCards int
+}
+
+func (r *BINVelocityRule) GetID() string   { return "bin_velocity_rule" }
+func (r *BINVelocityRule) GetName() string { return "BIN Velocity" }
+func (r *BINVelocityRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 35
+	}
+	return r.Weight
+}
+func (r *BINVelocityRule) GetPriority() int { return PriorityLookup }
+func (r *BINVelocityRule) IsEnabled() bool  { return true }
+
+func (r *BINVelocityRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	window := 10 * time.Minute
+	if r.Window > 0 {
+		window = r.Window
+	}
+	maxCards := 5
+	if r.MaxCards > 0 {
+		maxCards = r.MaxCards
+	}
+
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Muitos cartões distintos do mesmo BIN em poucos minutos",
+		Details:     map[string]interface{}{},
+	}
+
+	if r.Velocity == nil || transaction.CardBIN == "" {
+		return result
+	}
+
+	// A transação atual ainda não foi registrada: o cartão dela entra na contagem
+	velocity, err := r.Velocity.GetBINVelocity(ctx, transaction.CardBIN, transaction.CardKey(), window, transaction.Timestamp)
+	if err != nil {
+		result.Details["error"] = err.Error()
+		return result
+	}
+	distinctCards := velocity.DistinctCards
+
+	result.Details["bin"] = transaction.CardBIN
+	result.Details["distinct_cards"] = distinctCards
+	result.Details["transactions"] = velocity.TransactionCount
+	result.Details["window_minutes"] = velocity.TimeWindow
+	result.Details["max_cards"] = maxCards
+
+	if distinctCards > maxCards {
+		result.Triggered = true
+		result.Score = r.GetWeight()
+	}
+
+	return result
+}
+
+// CardVelocityRule detecta uso intenso do mesmo cartão, identificado pela
+// impressão digital, e cartões compartilhados entre várias contas
+type CardVelocityRule struct {
+	Velocity        VelocityProvider
+	Weight          int
+	MaxTransactions int
+	MaxUsers        int
+}
+
+func (r *CardVelocityRule) GetID() string   { return "card_velocity_rule" }
+func (r *CardVelocityRule) GetName() string { return "Card Velocity" }
+func (r *CardVelocityRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 25
+	}
+	return r.Weight
+}
+func (r *CardVelocityRule) GetPriority() int { return PriorityLookup }
+func (r *CardVelocityRule) IsEnabled() bool  { return true }
+
+func (r *CardVelocityRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	maxTransactions := 5
+	if r.MaxTransactions > 0 {
+		maxTransactions = r.MaxTransactions
+	}
+	maxUsers := 2
+	if r.MaxUsers > 0 {
+		maxUsers = r.MaxUsers
+	}
+
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Cartão usado muitas vezes ou por várias contas",
+		Details:     map[string]interface{}{},
+	}
+
+	if r.Velocity == nil || transaction.CardFingerprint == "" {
+		return result
+	}
+
+	hourly, err := r.Velocity.GetCardVelocity(ctx, transaction.CardFingerprint, transaction.UserID, time.Hour, transaction.Timestamp)
+	if err != nil {
+		result.Details["error"] = err.Error()
+		return result
+	}
+	daily, err := r.Velocity.GetCardVelocity(ctx, transaction.CardFingerprint, transaction.UserID, 24*time.Hour, transaction.Timestamp)
+	if err != nil {
+		result.Details["error"] = err.Error()
+		return result
+	}
+
+	// A transação atual ainda não foi registrada
+	transactions := hourly.TransactionCount + 1
+
+	result.Details["transactions_1h"] = transactions
+	result.Details["users_24h"] = daily.DistinctUsers
+	result.Details["max_transactions"] = maxTransactions
+	result.Details["max_users"] = maxUsers
+
+	reasons := make([]string, 0, 2)
+	if transactions > maxTransactions {
+		reasons = append(reasons, "velocity")
+	}
+	if daily.DistinctUsers > maxUsers {
+		reasons = append(reasons, "shared_card")
+	}
+	result.Details["reasons"] = reasons
+
+	if len(reasons) > 0 {
+		result.Triggered = true
+		result.Score = r.GetWeight()
+	}
+
+	return result
+}
@@ -0,0 +1,290 @@
+package rules
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Operadores das condições de regras compostas
+const (
+	ConditionRule = "rule"
+	ConditionAnd  = "and"
+	ConditionOr   = "or"
+	ConditionNot  = "not"
+	ConditionNOfM = "n_of_m"
+)
+
+// CompositeRuleConfig regra composta definida em configuração, que combina o
+// resultado de outras regras com lógica booleana
+type CompositeRuleConfig struct {
+	ID              string          `json:"id"`
+	Name            string          `json:"name"`
+	Description     string          `json:"description"`
+	Weight          int             `json:"weight"`
+	Enabled         *bool           `json:"enabled,omitempty"`
+	ReplaceChildren bool            `json:"replace_children,omitempty"`
+	Condition       ConditionConfig `json:"condition"`
+}
+
+// ConditionConfig nó da árvore de condição de uma regra composta.
+// Folhas (op "rule") verificam se a regra foi acionada ou, quando Detail é
+// informado, comparam um valor de RuleResult.Details com Value.
+type ConditionConfig struct {
+	Op       string            `json:"op"`
+	Rule     string            `json:"rule,omitempty"`
+	Detail   string            `json:"detail,omitempty"`
+	Compare  string            `json:"compare,omitempty"`
+	Value    interface{}       `json:"value,omitempty"`
+	N        int               `json:"n,omitempty"`
+	Children []ConditionConfig `json:"children,omitempty"`
+}
+
+// CompositeRule regra composta avaliada pelo motor após suas regras filhas
+type CompositeRule struct {
+	config CompositeRuleConfig
+}
+
+func (r *CompositeRule) GetID() string { return r.config.ID }
+func (r *CompositeRule) GetName() string {
+	if r.config.Name == "" {
+		return r.config.ID
+	}
+	return r.config.Name
+}
+func (r *CompositeRule) GetWeight() int { return r.config.Weight }
+func (r *CompositeRule) IsEnabled() bool {
+	return r.config.Enabled == nil || *r.config.Enabled
+}
+
+// Children retorna os IDs das regras referenciadas pela condição
+func (r *CompositeRule) Children() []string {
+	seen := make(map[string]bool)
+	collectRuleRefs(r.config.Condition, seen)
+
+	children := make([]string, 0, len(seen))
+	for id := range seen {
+		children = append(children, id)
+	}
+	sort.Strings(children)
+	return children
+}
+
+// EvaluateComposite avalia a condição sobre os resultados já calculados das regras filhas
+func (r *CompositeRule) EvaluateComposite(results map[string]RuleResult) RuleResult {
+	triggered := evaluateCondition(r.config.Condition, results)
+
+	children := make(map[string]bool)
+	for _, id := range r.Children() {
+		children[id] = results[id].Triggered
+	}
+
+	score := 0
+	if triggered {
+		score = r.GetWeight()
+	}
+
+	description := r.config.Description
+	if description == "" {
+		description = "Regra composta acionada: " + r.GetName()
+	}
+
+	return RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Triggered:   triggered,
+		Score:       score,
+		Description: description,
+		Details: map[string]interface{}{
+			"children": children,
+		},
+	}
+}
+
+// evaluateCondition avalia recursivamente um nó da condição
+func evaluateCondition(condition ConditionConfig, results map[string]RuleResult) bool {
+	switch condition.Op {
+	case ConditionRule:
+		result, exists := results[condition.Rule]
+		if !exists {
+			return false
+		}
+		if condition.Detail == "" {
+			return result.Triggered
+		}
+		value, exists := result.Details[condition.Detail]
+		if !exists {
+			return false
+		}
+		return compareValues(value, condition.Compare, condition.Value)
+	case ConditionAnd:
+		for _, child := range condition.Children {
+			if !evaluateCondition(child, results) {
+				return false
+			}
+		}
+		return true
+	case ConditionOr:
+		for _, child := range condition.Children {
+			if evaluateCondition(child, results) {
+				return true
+			}
+		}
+		return false
+	case ConditionNot:
+		return !evaluateCondition(condition.Children[0], results)
+	case ConditionNOfM:
+		matched := 0
+		for _, child := range condition.Children {
+			if evaluateCondition(child, results) {
+				matched++
+			}
+		}
+		return matched >= condition.N
+	default:
+		return false
+	}
+}
+
+// compareValues compara um detalhe de regra com o valor esperado.
+// Números são comparados como float64; demais tipos apenas por igualdade.
+func compareValues(actual interface{}, operator string, expected interface{}) bool {
+	actualNumber, actualIsNumber := toFloat(actual)
+	expectedNumber, expectedIsNumber := toFloat(expected)
+
+	if actualIsNumber && expectedIsNumber {
+		switch operator {
+		case ">":
+			return actualNumber > expectedNumber
+		case ">=":
+			return actualNumber >= expectedNumber
+		case "<":
+			return actualNumber < expectedNumber
+		case "<=":
+			return actualNumber <= expectedNumber
+		case "!=":
+			return actualNumber != expectedNumber
+		default:
+			return actualNumber == expectedNumber
+		}
+	}
+
+	equal := fmt.Sprint(actual) == fmt.Sprint(expected)
+	if operator == "!=" {
+		return !equal
+	}
+	return equal
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+func collectRuleRefs(condition ConditionConfig, refs map[string]bool) {
+	if condition.Op == ConditionRule {
+		refs[condition.Rule] = true
+	}
+	for _, child := range condition.Children {
+		collectRuleRefs(child, refs)
+	}
+}
+
+// validateCondition verifica a estrutura de um nó da condição
+func validateCondition(condition ConditionConfig, path string) error {
+	switch condition.Op {
+	case ConditionRule:
+		if condition.Rule == "" {
+			return fmt.Errorf("%s: rule is required", path)
+		}
+		if len(condition.Children) > 0 {
+			return fmt.Errorf("%s: rule condition cannot have children", path)
+		}
+		switch condition.Compare {
+		case "", "==", "!=", ">", ">=", "<", "<=":
+		default:
+			return fmt.Errorf("%s: invalid compare operator %q", path, condition.Compare)
+		}
+		if condition.Detail == "" && condition.Compare != "" {
+			return fmt.Errorf("%s: compare requires detail", path)
+		}
+		return nil
+	case ConditionAnd, ConditionOr:
+		if len(condition.Children) == 0 {
+			return fmt.Errorf("%s: %s requires at least one child", path, condition.Op)
+		}
+	case ConditionNot:
+		if len(condition.Children) != 1 {
+			return fmt.Errorf("%s: not requires exactly one child", path)
+		}
+	case ConditionNOfM:
+		if condition.N < 1 || condition.N > len(condition.Children) {
+			return fmt.Errorf("%s: n_of_m requires 1 <= n <= %d", path, len(condition.Children))
+		}
+	default:
+		return fmt.Errorf("%s: unknown operator %q", path, condition.Op)
+	}
+
+	for i, child := range condition.Children {
+		if err := validateCondition(child, fmt.Sprintf("%s.children[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// orderComposites ordena as regras compostas para que cada uma seja avaliada
+// depois das compostas das quais depende, rejeitando ciclos
+func orderComposites(composites []*CompositeRule) ([]*CompositeRule, error) {
+	byID := make(map[string]*CompositeRule, len(composites))
+	for _, composite := range composites {
+		byID[composite.GetID()] = composite
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+	ordered := make([]*CompositeRule, 0, len(composites))
+
+	var visit func(composite *CompositeRule) error
+	visit = func(composite *CompositeRule) error {
+		switch state[composite.GetID()] {
+		case visiting:
+			return fmt.Errorf("composite rule %q has a cyclic dependency", composite.GetID())
+		case done:
+			return nil
+		}
+
+		state[composite.GetID()] = visiting
+		for _, child := range composite.Children() {
+			if dependency, isComposite := byID[child]; isComposite {
+				if err := visit(dependency); err != nil {
+					return err
+				}
+			}
+		}
+		state[composite.GetID()] = done
+		ordered = append(ordered, composite)
+		return nil
+	}
+
+	for _, composite := range composites {
+		if err := visit(composite); err != nil {
+			return nil, err
+		}
+	}
+
+	return ordered, nil
+}
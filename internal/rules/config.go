@@ -0,0 +1,188 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// EngineConfig configuração do motor de regras
+type EngineConfig struct {
+	Aggregation AggregationConfig      `json:"aggregation"`
+	Vetoes      []VetoConfig           `json:"vetoes,omitempty"`
+	Composites  []CompositeRuleConfig  `json:"composites,omitempty"`
+	Expressions []ExpressionRuleConfig `json:"expressions,omitempty"`
+	Lists       map[string][]string    `json:"lists,omitempty"`
+	Evaluation  EvaluationConfig       `json:"evaluation"`
+	Alerts      models.AlertConfig     `json:"alerts"`
+}
+
+// EvaluationConfig ordem de avaliação das regras, critérios de parada
+// antecipada e prazos de execução
+type EvaluationConfig struct {
+	// Priorities sobrescreve a prioridade padrão das regras (menor avalia primeiro)
+	Priorities   map[string]int     `json:"priorities,omitempty"`
+	ShortCircuit ShortCircuitConfig `json:"short_circuit"`
+	// RuleTimeoutMs prazo de cada regra; RuleTimeoutsMs sobrescreve por regra
+	RuleTimeoutMs  int            `json:"rule_timeout_ms,omitempty"`
+	RuleTimeoutsMs map[string]int `json:"rule_timeouts_ms,omitempty"`
+	// TotalTimeoutMs prazo de toda a avaliação
+	TotalTimeoutMs int                 `json:"total_timeout_ms,omitempty"`
+	TimeoutPolicy  TimeoutPolicyConfig `json:"timeout_policy"`
+	// PanicPolicy efeito de uma regra que entra em pânico, com os mesmos modos
+	PanicPolicy TimeoutPolicyConfig `json:"panic_policy"`
+}
+
+// Políticas para regras que não terminam no prazo
+const (
+	TimeoutFailOpen   = "fail_open"
+	TimeoutFailClosed = "fail_closed"
+	TimeoutPenalty    = "penalty"
+)
+
+// TimeoutPolicyConfig define como uma regra que estourou o prazo afeta o score
+type TimeoutPolicyConfig struct {
+	// Mode fail_open (padrão) ignora a regra, fail_closed a considera acionada
+	// com o peso cheio e penalty a considera acionada com score Penalty
+	Mode    string `json:"mode,omitempty"`
+	Penalty int    `json:"penalty,omitempty"`
+}
+
+// validate verifica o modo e a penalidade de uma política de timeout ou pânico
+func (p TimeoutPolicyConfig) validate(name string) error {
+	switch p.Mode {
+	case "", TimeoutFailOpen, TimeoutFailClosed:
+	case TimeoutPenalty:
+		if p.Penalty <= 0 || p.Penalty > 100 {
+			return fmt.Errorf("evaluation: %s penalty must be between 1 and 100", name)
+		}
+	default:
+		return fmt.Errorf("evaluation: unknown %s policy %q", name, p.Mode)
+	}
+	return nil
+}
+
+// ShortCircuitConfig condições que interrompem a avaliação das regras restantes
+type ShortCircuitConfig struct {
+	// OnVeto para assim que uma regra de veto com decisão BLOCKED é acionada
+	OnVeto bool `json:"on_veto"`
+	// OnMaxScore para assim que o score agregado atinge 100
+	OnMaxScore bool `json:"on_max_score"`
+}
+
+// AggregationConfig seleciona e parametriza a estratégia de agregação de score
+type AggregationConfig struct {
+	Strategy      string             `json:"strategy"`
+	BonusPerRule  *int               `json:"bonus_per_rule,omitempty"`
+	Bias          *float64           `json:"bias,omitempty"`
+	DefaultWeight *float64           `json:"default_weight,omitempty"`
+	Weights       map[string]float64 `json:"weights,omitempty"`
+}
+
+// VetoConfig regra que, quando acionada, força uma decisão independente do score
+type VetoConfig struct {
+	RuleID   string          `json:"rule_id"`
+	Decision models.Decision `json:"decision"`
+}
+
+// LoadEngineConfig carrega a configuração do motor de um arquivo JSON
+func LoadEngineConfig(path string) (*EngineConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var config EngineConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid engine config %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// Validate verifica a consistência da configuração
+func (c *EngineConfig) Validate() error {
+	if _, err := NewScoreAggregator(c.Aggregation); err != nil {
+		return err
+	}
+
+	for i, veto := range c.Vetoes {
+		if veto.RuleID == "" {
+			return fmt.Errorf("veto %d: rule_id is required", i)
+		}
+		switch veto.Decision {
+		case models.DecisionApproved, models.DecisionReview, models.DecisionBlocked:
+		default:
+			return fmt.Errorf("veto %d: invalid decision %q", i, veto.Decision)
+		}
+	}
+
+	for ruleID, priority := range c.Evaluation.Priorities {
+		if priority < 0 {
+			return fmt.Errorf("evaluation: priority of %q must not be negative", ruleID)
+		}
+	}
+	if c.Evaluation.RuleTimeoutMs < 0 || c.Evaluation.TotalTimeoutMs < 0 {
+		return fmt.Errorf("evaluation: timeouts must not be negative")
+	}
+	for ruleID, timeout := range c.Evaluation.RuleTimeoutsMs {
+		if timeout <= 0 {
+			return fmt.Errorf("evaluation: timeout of %q must be positive", ruleID)
+		}
+	}
+	if err := c.Evaluation.TimeoutPolicy.validate("timeout"); err != nil {
+		return err
+	}
+	if err := c.Evaluation.PanicPolicy.validate("panic"); err != nil {
+		return err
+	}
+
+	if (c.Alerts.NightHourStart == nil) != (c.Alerts.NightHourEnd == nil) {
+		return fmt.Errorf("alerts: night_hour_start and night_hour_end must be set together")
+	}
+	for _, hour := range []*int{c.Alerts.NightHourStart, c.Alerts.NightHourEnd} {
+		if hour != nil && (*hour < 0 || *hour > 23) {
+			return fmt.Errorf("alerts: night hours must be between 0 and 23")
+		}
+	}
+	for _, country := range c.Alerts.HighRiskCountries {
+		if len(normalizeCountry(country)) != 2 {
+			return fmt.Errorf("alerts: invalid high risk country %q, expected ISO 3166-1 alpha-2", country)
+		}
+	}
+	if c.Alerts.GeoVelocityLimit < 0 {
+		return fmt.Errorf("alerts: geo velocity limit must not be negative")
+	}
+	for _, city := range c.Alerts.AirportCities {
+		if normalizeCity(city) == "" {
+			return fmt.Errorf("alerts: airport city must not be empty")
+		}
+	}
+	for i, route := range c.Alerts.FlightRoutes {
+		if normalizeCity(route.From) == "" || normalizeCity(route.To) == "" {
+			return fmt.Errorf("alerts: flight route %d: from and to are required", i)
+		}
+		if normalizeCity(route.From) == normalizeCity(route.To) {
+			return fmt.Errorf("alerts: flight route %d: from and to must differ", i)
+		}
+		if route.DurationMinutes <= 0 {
+			return fmt.Errorf("alerts: flight route %d: duration must be positive", i)
+		}
+	}
+
+	for i, composite := range c.Composites {
+		if composite.ID == "" {
+			return fmt.Errorf("composite %d: id is required", i)
+		}
+		if composite.Weight < 0 {
+			return fmt.Errorf("composite %q: weight must not be negative", composite.ID)
+		}
+		if err := validateCondition(composite.Condition, composite.ID+".condition"); err != nil {
+			return fmt.Errorf("composite %q: %w", composite.ID, err)
+		}
+	}
+
+	return nil
+}
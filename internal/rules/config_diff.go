@@ -0,0 +1,106 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Tipos de alteração entre duas configurações
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeUpdated = "updated"
+)
+
+// ConfigChange alteração de um campo entre duas configurações
+type ConfigChange struct {
+	Path     string      `json:"path"`
+	Type     string      `json:"type"`
+	OldValue interface{} `json:"old_value,omitempty"`
+	NewValue interface{} `json:"new_value,omitempty"`
+}
+
+// DiffConfigs compara duas configurações campo a campo. Listas de regras são
+// comparadas pelo id da regra, de modo que reordenar não gera alterações.
+func DiffConfigs(from, to EngineConfig) ([]ConfigChange, error) {
+	before, err := flattenConfig(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := flattenConfig(to)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]ConfigChange, 0)
+	for path, oldValue := range before {
+		newValue, exists := after[path]
+		switch {
+		case !exists:
+			changes = append(changes, ConfigChange{Path: path, Type: ChangeRemoved, OldValue: oldValue})
+		case !reflect.DeepEqual(oldValue, newValue):
+			changes = append(changes, ConfigChange{Path: path, Type: ChangeUpdated, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	for path, newValue := range after {
+		if _, exists := before[path]; !exists {
+			changes = append(changes, ConfigChange{Path: path, Type: ChangeAdded, NewValue: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes, nil
+}
+
+// flattenConfig converte a configuração em um mapa caminho -> valor escalar
+func flattenConfig(config EngineConfig) (map[string]interface{}, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return nil, err
+	}
+
+	flat := make(map[string]interface{})
+	flatten("", generic, flat)
+	return flat, nil
+}
+
+func flatten(prefix string, value interface{}, flat map[string]interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			flatten(joinPath(prefix, key), child, flat)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(fmt.Sprintf("%s[%s]", prefix, elementKey(child, i)), child, flat)
+		}
+	default:
+		flat[prefix] = v
+	}
+}
+
+// elementKey identifica um elemento de lista pelo id da regra, quando houver
+func elementKey(element interface{}, index int) string {
+	if object, ok := element.(map[string]interface{}); ok {
+		for _, field := range []string{"id", "rule_id"} {
+			if id, ok := object[field].(string); ok && id != "" {
+				return id
+			}
+		}
+	}
+	return fmt.Sprint(index)
+}
+
+func joinPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,259 @@
+package rules
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// Origens do país de uma transação
+const (
+	CountrySourceDeclared = "declared"
+	CountrySourceIP       = "ip"
+	CountrySourceCard     = "card"
+	CountrySourceHome     = "home"
+)
+
+// HighRiskCountryRule detecta transações envolvendo países de alto risco,
+// seja o país declarado, o país do IP ou o país emissor do cartão
+type HighRiskCountryRule struct {
+	Countries []string
+	Weight    int
+}
+
+func (r *HighRiskCountryRule) GetID() string   { return "high_risk_country_rule" }
+func (r *HighRiskCountryRule) GetName() string { return "High Risk Country" }
+func (r *HighRiskCountryRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 30
+	}
+	return r.Weight
+}
+func (r *HighRiskCountryRule) GetPriority() int { return PriorityCheap }
+func (r *HighRiskCountryRule) IsEnabled() bool  { return true }
+
+func (r *HighRiskCountryRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	highRisk := make(map[string]bool, len(r.Countries))
+	for _, country := range r.Countries {
+		highRisk[normalizeCountry(country)] = true
+	}
+
+	matches := make(map[string]interface{})
+	for source, country := range transactionCountries(transaction) {
+		if highRisk[country] {
+			matches[source] = country
+		}
+	}
+
+	triggered := len(matches) > 0
+	score := 0
+	if triggered {
+		score = r.GetWeight()
+	}
+
+	return RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Triggered:   triggered,
+		Score:       score,
+		Description: "Transação envolvendo país de alto risco",
+		Details: map[string]interface{}{
+			"countries": matches,
+		},
+	}
+}
+
+// CountryMismatchRule detecta divergência entre o país do IP, o país
+// declarado e o país de cobrança (emissor do cartão ou, na falta dele, o
+// país de origem do usuário)
+type CountryMismatchRule struct {
+	Weight int
+}
+
+func (r *CountryMismatchRule) GetID() string   { return "country_mismatch_rule" }
+func (r *CountryMismatchRule) GetName() string { return "Country Mismatch" }
+func (r *CountryMismatchRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 25
+	}
+	return r.Weight
+}
+func (r *CountryMismatchRule) GetPriority() int { return PriorityCheap }
+func (r *CountryMismatchRule) IsEnabled() bool  { return true }
+
+func (r *CountryMismatchRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	countries := transactionCountries(transaction)
+
+	billingSource := CountrySourceCard
+	if countries[CountrySourceCard] == "" {
+		billingSource = CountrySourceHome
+		if home := homeCountry(profile); home != "" {
+			countries[CountrySourceHome] = home
+		}
+	}
+
+	// Pares comparados; países desconhecidos não contam como divergência
+	pairs := [][2]string{
+		{CountrySourceIP, CountrySourceDeclared},
+		{CountrySourceIP, billingSource},
+		{CountrySourceDeclared, CountrySourceCard},
+	}
+	mismatches := make([]string, 0)
+	for _, pair := range pairs {
+		a, b := countries[pair[0]], countries[pair[1]]
+		if a != "" && b != "" && a != b {
+			mismatches = append(mismatches, pair[0]+"!="+pair[1])
+		}
+	}
+
+	triggered := len(mismatches) > 0
+	score := 0
+	if len(mismatches) == 1 {
+		score = int(float64(r.GetWeight()) * 0.6)
+	} else if triggered {
+		score = r.GetWeight()
+	}
+
+	known := make(map[string]interface{}, len(countries))
+	for source, country := range countries {
+		known[source] = country
+	}
+
+	return RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Triggered:   triggered,
+		Score:       score,
+		Description: "País do IP, declarado e de cobrança não coincidem",
+		Details: map[string]interface{}{
+			"countries":  known,
+			"mismatches": mismatches,
+		},
+	}
+}
+
+// NewCountryRule detecta a primeira transação do usuário em um país
+type NewCountryRule struct {
+	Weight     int
+	MinHistory int
+}
+
+func (r *NewCountryRule) GetID() string   { return "new_country_rule" }
+func (r *NewCountryRule) GetName() string { return "First Transaction From Country" }
+func (r *NewCountryRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 15
+	}
+	return r.Weight
+}
+func (r *NewCountryRule) GetPriority() int { return PriorityCheap }
+func (r *NewCountryRule) IsEnabled() bool  { return true }
+
+func (r *NewCountryRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	minHistory := 5
+	if r.MinHistory > 0 {
+		minHistory = r.MinHistory
+	}
+
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Primeira transação do usuário neste país",
+		Details:     map[string]interface{}{},
+	}
+
+	country := normalizeCountry(transaction.Location.Country)
+	// Sem histórico suficiente, todo país seria novo; usuários novos já são
+	// tratados pela regra de usuário novo
+	if country == "" || profile == nil || profile.TotalTransactions < minHistory {
+		return result
+	}
+
+	seen := knownCountries(profile)
+	known := make([]string, 0, len(seen))
+	for c := range seen {
+		known = append(known, c)
+	}
+	sort.Strings(known)
+
+	result.Details["country"] = country
+	result.Details["known_countries"] = known
+
+	if !seen[country] {
+		result.Triggered = true
+		result.Score = r.GetWeight()
+	}
+
+	return result
+}
+
+// transactionCountries países conhecidos da transação, por origem
+func transactionCountries(transaction *models.Transaction) map[string]string {
+	countries := make(map[string]string)
+	for source, country := range map[string]string{
+		CountrySourceDeclared: transaction.Location.Country,
+		CountrySourceIP:       transaction.IPCountry,
+		CountrySourceCard:     transaction.CardCountry,
+	} {
+		if country = normalizeCountry(country); country != "" {
+			countries[source] = country
+		}
+	}
+	return countries
+}
+
+// knownCountries países em que o usuário já transacionou
+func knownCountries(profile *models.UserProfile) map[string]bool {
+	seen := make(map[string]bool)
+	if profile == nil {
+		return seen
+	}
+	if profile.Baseline != nil {
+		for country, count := range profile.Baseline.CountryCounts {
+			if count > 0 {
+				seen[normalizeCountry(country)] = true
+			}
+		}
+	}
+	for _, location := range profile.CommonLocations {
+		if country := normalizeCountry(location.Country); country != "" {
+			seen[country] = true
+		}
+	}
+	return seen
+}
+
+// homeCountry país mais frequente do usuário; sem baseline, o primeiro país
+// registrado no perfil
+func homeCountry(profile *models.UserProfile) string {
+	if profile == nil {
+		return ""
+	}
+
+	home, best := "", 0
+	if profile.Baseline != nil {
+		for country, count := range profile.Baseline.CountryCounts {
+			country = normalizeCountry(country)
+			if country == "" {
+				continue
+			}
+			if count > best || (count == best && country < home) {
+				home, best = country, count
+			}
+		}
+	}
+	if home == "" {
+		for _, location := range profile.CommonLocations {
+			if country := normalizeCountry(location.Country); country != "" {
+				return country
+			}
+		}
+	}
+	return home
+}
+
+func normalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
@@ -0,0 +1,64 @@
+package rules
+
+import (
+	"context"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/ml"
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// PeerStatsProvider fornece estatísticas agregadas de grupos de pares
+type PeerStatsProvider interface {
+	GetPeerGroupStats(key models.PeerGroupKey) (*models.PeerGroupStats, error)
+}
+
+// VelocityProvider fornece contagem e valor das transações recentes de um
+// usuário ou de um cartão em uma janela que termina no instante informado.
+// GetCardVelocity inclui o usuário informado na contagem de usuários distintos.
+type VelocityProvider interface {
+	GetVelocity(ctx context.Context, userID string, window time.Duration, at time.Time) (*models.VelocityCheck, error)
+	GetCardVelocity(ctx context.Context, fingerprint, userID string, window time.Duration, at time.Time) (*models.VelocityCheck, error)
+}
+
+// BINVelocityProvider fornece a contagem de cartões distintos de um BIN em
+// uma janela que termina no instante informado, incluindo o cartão informado
+type BINVelocityProvider interface {
+	GetBINVelocity(ctx context.Context, bin, card string, window time.Duration, at time.Time) (*models.BINVelocity, error)
+}
+
+// DeviceUsageProvider fornece a contagem de usuários distintos de uma
+// impressão digital de dispositivo, incluindo o usuário informado
+type DeviceUsageProvider interface {
+	GetDeviceUsage(ctx context.Context, fingerprint, userID string, window time.Duration, at time.Time) (*models.DeviceUsage, error)
+}
+
+// PIXLimitProvider fornece os limites PIX configurados pelo usuário; nil
+// se ele nunca configurou os seus
+type PIXLimitProvider interface {
+	GetPIXLimits(ctx context.Context, userID string) (*models.PIXLimits, error)
+}
+
+// PIXUsageProvider fornece o total transferido via PIX pelo usuário em [from, to)
+type PIXUsageProvider interface {
+	GetPIXUsage(ctx context.Context, userID string, from, to time.Time) (*models.PIXUsage, error)
+}
+
+// MuleAccountProvider fornece a marcação de conta laranja da chave ou da
+// conta do recebedor; nil se nenhuma das duas estiver marcada
+type MuleAccountProvider interface {
+	GetMuleFlag(ctx context.Context, pix *models.PIXTransfer) (*models.MuleFlag, error)
+}
+
+// Dependencies fontes de dados externas consultadas pelas regras.
+// Campos nulos desativam os comportamentos que dependem deles.
+type Dependencies struct {
+	PeerStats   PeerStatsProvider
+	Velocity    VelocityProvider
+	BINVelocity BINVelocityProvider
+	DeviceUsage DeviceUsageProvider
+	PIXLimits   PIXLimitProvider
+	PIXUsage    PIXUsageProvider
+	Mules       MuleAccountProvider
+	Model       *ml.Model
+}
@@ -0,0 +1,312 @@
+package rules
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// Famílias de sistema operacional e navegador reconhecidas no user agent
+const (
+	osIOS      = "ios"
+	osAndroid  = "android"
+	osWindows  = "windows"
+	osMacOS    = "macos"
+	osLinux    = "linux"
+	osChromeOS = "chromeos"
+
+	browserEdge    = "edge"
+	browserOpera   = "opera"
+	browserSamsung = "samsung"
+	browserFirefox = "firefox"
+	browserChrome  = "chrome"
+	browserSafari  = "safari"
+)
+
+// UntrustedDeviceRule detecta transações de usuários com histórico feitas em
+// um dispositivo que ainda não foi promovido a confiável
+type UntrustedDeviceRule struct {
+	Weight     int
+	MinHistory int
+}
+
+func (r *UntrustedDeviceRule) GetID() string   { return "untrusted_device_rule" }
+func (r *UntrustedDeviceRule) GetName() string { return "Untrusted Device" }
+func (r *UntrustedDeviceRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 20
+	}
+	return r.Weight
+}
+func (r *UntrustedDeviceRule) GetPriority() int { return PriorityCheap }
+func (r *UntrustedDeviceRule) IsEnabled() bool  { return true }
+
+func (r *UntrustedDeviceRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	minHistory := 3
+	if r.MinHistory > 0 {
+		minHistory = r.MinHistory
+	}
+
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Transação em dispositivo não confiável",
+		Details:     map[string]interface{}{},
+	}
+
+	// Sem histórico todo dispositivo é novo; a regra de usuário novo cobre esse caso
+	device := transaction.DeviceInfo.Key()
+	if device == "" || profile == nil || profile.TotalTransactions < minHistory {
+		return result
+	}
+	if profile.IsTrustedDevice(transaction.DeviceInfo) {
+		return result
+	}
+
+	// Dispositivo já aprovado antes está em aprendizado e pesa metade
+	approvals := profile.DeviceApprovals[device]
+	result.Triggered = true
+	result.Details["device"] = device
+	result.Details["approvals"] = approvals
+	if approvals > 0 {
+		result.Score = r.GetWeight() / 2
+		result.Details["status"] = "learning"
+	} else {
+		result.Score = r.GetWeight()
+		result.Details["status"] = "new"
+	}
+
+	return result
+}
+
+// SharedDeviceRule detecta a mesma impressão digital de dispositivo usada por
+// muitas contas, típico de fazendas de contas e fraudadores com várias identidades
+type SharedDeviceRule struct {
+	Usage    DeviceUsageProvider
+	Weight   int
+	Window   time.Duration
+	MaxUsers int
+}
+
+func (r *SharedDeviceRule) GetID() string   { return "shared_device_rule" }
+func (r *SharedDeviceRule) GetName() string { return "Shared Device" }
+func (r *SharedDeviceRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 30
+	}
+	return r.Weight
+}
+func (r *SharedDeviceRule) GetPriority() int { return PriorityLookup }
+func (r *SharedDeviceRule) IsEnabled() bool  { return true }
+
+func (r *SharedDeviceRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	window := 24 * time.Hour
+	if r.Window > 0 {
+		window = r.Window
+	}
+	maxUsers := 3
+	if r.MaxUsers > 0 {
+		maxUsers = r.MaxUsers
+	}
+
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Dispositivo usado por várias contas",
+		Details:     map[string]interface{}{},
+	}
+
+	fingerprint := transaction.DeviceInfo.Fingerprint
+	if r.Usage == nil || fingerprint == "" {
+		return result
+	}
+
+	usage, err := r.Usage.GetDeviceUsage(ctx, fingerprint, transaction.UserID, window, transaction.Timestamp)
+	if err != nil {
+		result.Details["error"] = err.Error()
+		return result
+	}
+
+	result.Details["fingerprint"] = fingerprint
+	result.Details["distinct_users"] = usage.DistinctUsers
+	result.Details["window_minutes"] = usage.TimeWindow
+	result.Details["max_users"] = maxUsers
+
+	if usage.DistinctUsers > maxUsers {
+		result.Triggered = true
+		result.Score = r.GetWeight()
+	}
+
+	return result
+}
+
+// DeviceConsistencyRule detecta atributos do dispositivo que se contradizem,
+// como user agent de iPhone com sistema operacional Android, sinal de
+// emuladores e de dados de dispositivo forjados
+type DeviceConsistencyRule struct {
+	Weight int
+}
+
+func (r *DeviceConsistencyRule) GetID() string   { return "device_consistency_rule" }
+func (r *DeviceConsistencyRule) GetName() string { return "Device Inconsistency" }
+func (r *DeviceConsistencyRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 25
+	}
+	return r.Weight
+}
+func (r *DeviceConsistencyRule) GetPriority() int { return PriorityCheap }
+func (r *DeviceConsistencyRule) IsEnabled() bool  { return true }
+
+func (r *DeviceConsistencyRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Atributos do dispositivo inconsistentes entre si",
+		Details:     map[string]interface{}{},
+	}
+
+	device := transaction.DeviceInfo
+	if device.UserAgent == "" {
+		return result
+	}
+
+	uaOS := userAgentOS(device.UserAgent)
+	uaBrowser := userAgentBrowser(device.UserAgent)
+
+	// Atributos desconhecidos não contam como contradição
+	contradictions := make([]string, 0, 3)
+	if declared := declaredOS(device.OS); uaOS != "" && declared != "" && !compatibleOS(uaOS, declared) {
+		contradictions = append(contradictions, "os")
+	}
+	if declared := declaredBrowser(device.Browser); uaBrowser != "" && declared != "" && uaBrowser != declared {
+		contradictions = append(contradictions, "browser")
+	}
+	if !compatibleDeviceType(uaOS, device.DeviceType) {
+		contradictions = append(contradictions, "device_type")
+	}
+
+	if len(contradictions) == 0 {
+		return result
+	}
+
+	result.Triggered = true
+	result.Score = r.GetWeight()
+	result.Details["contradictions"] = contradictions
+	result.Details["user_agent_os"] = uaOS
+	result.Details["user_agent_browser"] = uaBrowser
+	result.Details["os"] = device.OS
+	result.Details["browser"] = device.Browser
+	result.Details["device_type"] = device.DeviceType
+
+	return result
+}
+
+// userAgentOS identifica a família do sistema operacional no user agent;
+// vazio se não reconhecida
+func userAgentOS(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	// iOS também anuncia "like Mac OS X" e Android também anuncia "Linux"
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return osIOS
+	case strings.Contains(ua, "android"):
+		return osAndroid
+	case strings.Contains(ua, "cros"):
+		return osChromeOS
+	case strings.Contains(ua, "windows"):
+		return osWindows
+	case strings.Contains(ua, "macintosh"), strings.Contains(ua, "mac os x"):
+		return osMacOS
+	case strings.Contains(ua, "linux"):
+		return osLinux
+	}
+	return ""
+}
+
+// declaredOS normaliza o sistema operacional informado pelo cliente
+func declaredOS(os string) string {
+	value := strings.ToLower(os)
+	switch {
+	case strings.Contains(value, "ios"), strings.Contains(value, "iphone"), strings.Contains(value, "ipad"):
+		return osIOS
+	case strings.Contains(value, "android"):
+		return osAndroid
+	case strings.Contains(value, "chrome os"), strings.Contains(value, "chromeos"):
+		return osChromeOS
+	case strings.Contains(value, "windows"):
+		return osWindows
+	case strings.Contains(value, "mac"), strings.Contains(value, "os x"):
+		return osMacOS
+	case strings.Contains(value, "linux"), strings.Contains(value, "ubuntu"):
+		return osLinux
+	}
+	return ""
+}
+
+// compatibleOS aceita os pares que aparecem em dispositivos legítimos: iPad
+// e "versão para computador" do Safari anunciam macOS, e Android e ChromeOS
+// anunciam Linux no mesmo modo
+func compatibleOS(uaOS, declared string) bool {
+	if uaOS == declared {
+		return true
+	}
+	pair := map[string]bool{uaOS: true, declared: true}
+	return (pair[osIOS] && pair[osMacOS]) || (pair[osLinux] && (pair[osAndroid] || pair[osChromeOS]))
+}
+
+// userAgentBrowser identifica a família do navegador no user agent. A ordem
+// importa: Edge e Opera anunciam Chrome, e Chrome anuncia Safari.
+func userAgentBrowser(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	switch {
+	case strings.Contains(ua, "edg/"), strings.Contains(ua, "edga/"), strings.Contains(ua, "edgios/"):
+		return browserEdge
+	case strings.Contains(ua, "opr/"), strings.Contains(ua, "opera"):
+		return browserOpera
+	case strings.Contains(ua, "samsungbrowser"):
+		return browserSamsung
+	case strings.Contains(ua, "firefox/"), strings.Contains(ua, "fxios/"):
+		return browserFirefox
+	case strings.Contains(ua, "chrome/"), strings.Contains(ua, "crios/"), strings.Contains(ua, "chromium/"):
+		return browserChrome
+	case strings.Contains(ua, "safari/"):
+		return browserSafari
+	}
+	return ""
+}
+
+// declaredBrowser normaliza o navegador informado pelo cliente
+func declaredBrowser(browser string) string {
+	value := strings.ToLower(browser)
+	switch {
+	case strings.Contains(value, "edge"):
+		return browserEdge
+	case strings.Contains(value, "opera"):
+		return browserOpera
+	case strings.Contains(value, "samsung"):
+		return browserSamsung
+	case strings.Contains(value, "firefox"):
+		return browserFirefox
+	case strings.Contains(value, "chrome"), strings.Contains(value, "chromium"):
+		return browserChrome
+	case strings.Contains(value, "safari"):
+		return browserSafari
+	}
+	return ""
+}
+
+// compatibleDeviceType compara o tipo declarado com o sistema do user agent.
+// Celular com macOS ou Linux é aceito por causa da "versão para computador"
+// dos navegadores móveis.
+func compatibleDeviceType(uaOS, deviceType string) bool {
+	switch strings.ToLower(deviceType) {
+	case "desktop", "computer", "pc":
+		return uaOS != osIOS && uaOS != osAndroid
+	case "mobile", "smartphone", "phone":
+		return uaOS != osWindows && uaOS != osChromeOS
+	}
+	return true
+}
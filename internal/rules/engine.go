@@ -1,23 +1,85 @@
 package rules
 
 import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+	
 	"github.com/anti-fraud-golang/internal/models"
 )
 
 // RuleEngine motor de regras para detecção de fraude
 type RuleEngine struct {
-	rules []FraudRule
+	rules         []FraudRule
+	composites    []*CompositeRule
+	aggregator    ScoreAggregator
+	vetoes        []VetoConfig
+	priorities    map[string]int
+	shortCircuit  ShortCircuitConfig
+	ruleTimeout   time.Duration
+	ruleTimeouts  map[string]time.Duration
+	totalTimeout  time.Duration
+	timeoutPolicy TimeoutPolicyConfig
+	panicPolicy   TimeoutPolicyConfig
+	version       int
 }
 
 // FraudRule interface para regras de fraude
 type FraudRule interface {
-	Evaluate(transaction *models.Transaction, profile *models.UserProfile) RuleResult
+	Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult
 	GetID() string
 	GetName() string
 	GetWeight() int
+	GetPriority() int
 	IsEnabled() bool
 }
 
+// Prioridades padrão das regras, como em models.Rule: valores menores são
+// avaliados primeiro, deixando as regras mais caras para o final
+const (
+	PriorityCheap     = 10 // usa apenas a transação e o perfil
+	PriorityStandard  = 20 // cálculos simples sobre o histórico
+	PriorityLookup    = 30 // consulta stores externos
+	PriorityExpensive = 50 // modelos e consultas pesadas
+)
+
+// Prazos padrão de avaliação das regras
+const (
+	DefaultRuleTimeout       = 200 * time.Millisecond
+	DefaultEvaluationTimeout = 500 * time.Millisecond
+)
+
+// Motivos de interrupção antecipada da avaliação
+const (
+	ShortCircuitVeto     = "veto"
+	ShortCircuitMaxScore = "max_score"
+)
+
+// Evaluation resultado da avaliação do conjunto de regras
+type Evaluation struct {
+	// Results regras acionadas
+	Results []RuleResult
+	// Skipped regras não avaliadas por causa da interrupção antecipada
+	Skipped []string
+	// ShortCircuit motivo da interrupção, vazio se todas as regras rodaram
+	ShortCircuit string
+	// ShortCircuitRule regra que provocou a interrupção por veto
+	ShortCircuitRule string
+	// TimedOut regras que não terminaram no prazo
+	TimedOut []string
+	// Errored regras que entraram em pânico ou concluíram sem avaliar por
+	// erro de uma dependência
+	Errored []string
+	// TimeoutPolicy política aplicada às regras que estouraram o prazo
+	TimeoutPolicy string
+	// PanicPolicy política aplicada às regras que entraram em pânico
+	PanicPolicy string
+	// TravelAdjustments regras geográficas atenuadas por aviso de viagem
+	TravelAdjustments []TravelAdjustment
+}
+
 // RuleResult resultado da avaliação de uma regra
 type RuleResult struct {
 	RuleID      string
@@ -30,58 +92,518 @@ type RuleResult struct {
 
 // NewRuleEngine cria uma nova instância do motor de regras
 func NewRuleEngine() *RuleEngine {
+	return NewRuleEngineWithDependencies(Dependencies{})
+}
+
+// NewRuleEngineWithDependencies cria o motor de regras com acesso às fontes
+// de dados externas usadas pelas regras
+func NewRuleEngineWithDependencies(deps Dependencies) *RuleEngine {
+	return newRuleEngine(deps, models.AlertConfig{})
+}
+
+// newRuleEngine registra as regras compiladas com os limites de alerta informados
+func newRuleEngine(deps Dependencies, alerts models.AlertConfig) *RuleEngine {
 	engine := &RuleEngine{
-		rules: make([]FraudRule, 0),
+		rules:         make([]FraudRule, 0),
+		aggregator:    &SumAggregator{},
+		ruleTimeout:   DefaultRuleTimeout,
+		totalTimeout:  DefaultEvaluationTimeout,
+		timeoutPolicy: TimeoutPolicyConfig{Mode: TimeoutFailOpen},
+		panicPolicy:   TimeoutPolicyConfig{Mode: TimeoutFailOpen},
+		vetoes:        mandatoryVetoes(),
 	}
 	
 	// Registra todas as regras
 	engine.RegisterRule(&HighAmountRule{})
 	engine.RegisterRule(&VelocityRule{})
-	engine.RegisterRule(&GeoVelocityRule{})
-	engine.RegisterRule(&UnusualHourRule{})
-	engine.RegisterRule(&NewUserRule{})
+	engine.RegisterRule(&GeoVelocityRule{
+		MaxSpeedKmh:   alerts.GeoVelocityLimit,
+		AirportCities: alerts.AirportCities,
+		FlightRoutes:  alerts.FlightRoutes,
+	})
+	engine.RegisterRule(&UnusualHourRule{
+		NightHourStart: alerts.NightHourStart,
+		NightHourEnd:   alerts.NightHourEnd,
+	})
+	engine.RegisterRule(&NewUserRule{Peers: deps.PeerStats})
 	engine.RegisterRule(&RoundAmountRule{})
 	engine.RegisterRule(&MultipleFailedAttemptsRule{})
+	engine.RegisterRule(&BehaviorAnomalyRule{})
+	engine.RegisterRule(&HighRiskCountryRule{Countries: alerts.HighRiskCountries})
+	engine.RegisterRule(&CountryMismatchRule{})
+	engine.RegisterRule(&NewCountryRule{})
+	engine.RegisterRule(&IPLocationMismatchRule{})
+	engine.RegisterRule(&AnonymizedIPRule{})
+	engine.RegisterRule(&PrepaidCardRule{})
+	engine.RegisterRule(&ForeignCardRule{})
+	engine.RegisterRule(&BINVelocityRule{Velocity: deps.BINVelocity})
+	engine.RegisterRule(&CardVelocityRule{Velocity: deps.Velocity})
+	engine.RegisterRule(&UntrustedDeviceRule{})
+	engine.RegisterRule(&SharedDeviceRule{Usage: deps.DeviceUsage})
+	engine.RegisterRule(&DeviceConsistencyRule{})
+	engine.RegisterRule(&PIXLimitRule{Limits: deps.PIXLimits, Usage: deps.PIXUsage})
+	engine.RegisterRule(&PIXNewKeyRule{})
+	engine.RegisterRule(&PIXRecentKeyRule{})
+	engine.RegisterRule(&PIXMuleAccountRule{Mules: deps.Mules})
+	
+	if deps.Model != nil {
+		engine.RegisterRule(&ModelRule{Model: deps.Model})
+	}
 	
 	return engine
 }
 
-// RegisterRule registra uma nova regra
+// NewRuleEngineFromConfig cria o motor de regras aplicando a configuração
+func NewRuleEngineFromConfig(config EngineConfig, deps Dependencies) (*RuleEngine, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	
+	engine := newRuleEngine(deps, config.Alerts)
+	
+	aggregator, err := NewScoreAggregator(config.Aggregation)
+	if err != nil {
+		return nil, err
+	}
+	engine.aggregator = aggregator
+	
+	for _, expressionConfig := range config.Expressions {
+		rule, err := NewExpressionRule(expressionConfig, config.Lists, deps)
+		if err != nil {
+			return nil, err
+		}
+		if engine.hasRule(rule.GetID()) {
+			return nil, fmt.Errorf("expression rule %q: duplicated rule id", rule.GetID())
+		}
+		engine.RegisterRule(rule)
+	}
+	
+	if err := engine.registerComposites(config.Composites); err != nil {
+		return nil, err
+	}
+	
+	for _, veto := range config.Vetoes {
+		if !engine.hasRule(veto.RuleID) {
+			return nil, fmt.Errorf("veto references unknown rule %q", veto.RuleID)
+		}
+	}
+	engine.vetoes = append(mandatoryVetoes(), config.Vetoes...)
+	
+	for ruleID := range config.Evaluation.Priorities {
+		if !engine.hasRule(ruleID) {
+			return nil, fmt.Errorf("priority references unknown rule %q", ruleID)
+		}
+	}
+	engine.priorities = config.Evaluation.Priorities
+	engine.shortCircuit = config.Evaluation.ShortCircuit
+	engine.sortRules()
+	
+	if err := engine.configureTimeouts(config.Evaluation); err != nil {
+		return nil, err
+	}
+	
+	return engine, nil
+}
+
+// registerComposites valida as referências das regras compostas e as
+// registra na ordem em que devem ser avaliadas
+func (e *RuleEngine) registerComposites(configs []CompositeRuleConfig) error {
+	composites := make([]*CompositeRule, 0, len(configs))
+	ids := make(map[string]bool)
+	
+	for _, config := range configs {
+		if e.hasRule(config.ID) || ids[config.ID] {
+			return fmt.Errorf("composite rule %q: duplicated rule id", config.ID)
+		}
+		ids[config.ID] = true
+		composites = append(composites, &CompositeRule{config: config})
+	}
+	
+	for _, composite := range composites {
+		for _, child := range composite.Children() {
+			if !e.hasRule(child) && !ids[child] {
+				return fmt.Errorf("composite rule %q references unknown rule %q", composite.GetID(), child)
+			}
+		}
+	}
+	
+	ordered, err := orderComposites(composites)
+	if err != nil {
+		return err
+	}
+	
+	e.composites = ordered
+	return nil
+}
+
+// GeoVelocity calcula o deslocamento desde a última localização do usuário com
+// a configuração da regra de velocidade geográfica registrada; nil se a regra
+// não estiver registrada ou não houver posição anterior comparável
+func (e *RuleEngine) GeoVelocity(transaction *models.Transaction, profile *models.UserProfile) *models.GeoVelocity {
+	for _, rule := range e.rules {
+		if geoRule, ok := rule.(*GeoVelocityRule); ok {
+			return geoRule.geoVelocity(transaction, profile)
+		}
+	}
+	return nil
+}
+
+// hasRule verifica se uma regra com o ID informado está registrada
+func (e *RuleEngine) hasRule(ruleID string) bool {
+	for _, rule := range e.rules {
+		if rule.GetID() == ruleID {
+			return true
+		}
+	}
+	for _, composite := range e.composites {
+		if composite.GetID() == ruleID {
+			return true
+		}
+	}
+	return false
+}
+
+// SetVersion define a versão do conjunto de regras carregado no motor
+func (e *RuleEngine) SetVersion(version int) {
+	e.version = version
+}
+
+// Version retorna a versão do conjunto de regras carregado no motor
+func (e *RuleEngine) Version() int {
+	return e.version
+}
+
+// RegisterRule registra uma nova regra, mantendo a ordem de prioridade
 func (e *RuleEngine) RegisterRule(rule FraudRule) {
 	e.rules = append(e.rules, rule)
+	e.sortRules()
 }
 
-// Evaluate avalia todas as regras contra uma transação
-func (e *RuleEngine) Evaluate(transaction *models.Transaction, profile *models.UserProfile) []RuleResult {
-	results := make([]RuleResult, 0)
+// priority retorna a prioridade efetiva da regra, considerando a configuração
+func (e *RuleEngine) priority(rule FraudRule) int {
+	if priority, exists := e.priorities[rule.GetID()]; exists {
+		return priority
+	}
+	return rule.GetPriority()
+}
+
+// sortRules ordena as regras por prioridade; empates mantêm a ordem de registro
+func (e *RuleEngine) sortRules() {
+	sort.SliceStable(e.rules, func(i, j int) bool {
+		return e.priority(e.rules[i]) < e.priority(e.rules[j])
+	})
+}
+
+// configureTimeouts aplica os prazos e a política de timeout da configuração
+func (e *RuleEngine) configureTimeouts(config EvaluationConfig) error {
+	if config.RuleTimeoutMs > 0 {
+		e.ruleTimeout = time.Duration(config.RuleTimeoutMs) * time.Millisecond
+	}
+	if config.TotalTimeoutMs > 0 {
+		e.totalTimeout = time.Duration(config.TotalTimeoutMs) * time.Millisecond
+	}
 	
-	for _, rule := range e.rules {
-		if !rule.IsEnabled() {
+	e.ruleTimeouts = make(map[string]time.Duration, len(config.RuleTimeoutsMs))
+	for ruleID, timeout := range config.RuleTimeoutsMs {
+		if !e.hasRule(ruleID) {
+			return fmt.Errorf("timeout references unknown rule %q", ruleID)
+		}
+		e.ruleTimeouts[ruleID] = time.Duration(timeout) * time.Millisecond
+	}
+	
+	if config.TimeoutPolicy.Mode != "" {
+		e.timeoutPolicy = config.TimeoutPolicy
+	}
+	if config.PanicPolicy.Mode != "" {
+		e.panicPolicy = config.PanicPolicy
+	}
+	return nil
+}
+
+// Evaluate avalia as regras contra uma transação em ordem de prioridade.
+// Regras com a mesma prioridade são independentes e rodam em paralelo, cada
+// uma com seu prazo; a avaliação toda respeita o prazo total e o contexto
+// recebido. As regras compostas são avaliadas depois das regras simples, com
+// acesso ao resultado (acionado ou não) de todas as regras já avaliadas. Com
+// short-circuit habilitado, a avaliação para ao fim do nível de prioridade em
+// que um veto BLOCKED é acionado ou o score atinge 100, e as regras restantes
+// são reportadas como ignoradas.
+func (e *RuleEngine) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) Evaluation {
+	evaluation := Evaluation{
+		Results:       make([]RuleResult, 0),
+		Skipped:       make([]string, 0),
+		TimedOut:      make([]string, 0),
+		Errored:       make([]string, 0),
+		TimeoutPolicy: e.timeoutPolicy.Mode,
+		PanicPolicy:   e.panicPolicy.Mode,
+	}
+	evaluated := make(map[string]RuleResult)
+	skipped := make(map[string]bool)
+	
+	ctx, cancel := context.WithTimeout(ctx, e.totalTimeout)
+	defer cancel()
+	
+	for _, level := range e.priorityLevels() {
+		if evaluation.ShortCircuit != "" {
+			for _, rule := range level {
+				skipped[rule.GetID()] = true
+				evaluation.Skipped = append(evaluation.Skipped, rule.GetID())
+			}
 			continue
 		}
 		
-		result := rule.Evaluate(transaction, profile)
+		for _, outcome := range e.evaluateLevel(ctx, level, transaction, profile) {
+			result := outcome.result
+			if outcome.timedOut {
+				evaluation.TimedOut = append(evaluation.TimedOut, result.RuleID)
+			} else if _, failed := result.Details["error"]; failed {
+				evaluation.Errored = append(evaluation.Errored, result.RuleID)
+			}
+			if outcome.travelAdjustment != nil {
+				evaluation.TravelAdjustments = append(evaluation.TravelAdjustments, *outcome.travelAdjustment)
+			}
+			evaluated[result.RuleID] = result
+			if result.Triggered {
+				evaluation.Results = append(evaluation.Results, result)
+				e.checkShortCircuit(&evaluation, result)
+			}
+		}
+	}
+	
+	replaced := make(map[string]bool)
+	for _, composite := range e.composites {
+		if !composite.IsEnabled() {
+			continue
+		}
+		
+		// Compostas que dependem de regras ignoradas também são ignoradas
+		if evaluation.ShortCircuit != "" && (!evaluatedChildren(composite, skipped) || evaluation.ShortCircuit == ShortCircuitVeto) {
+			skipped[composite.GetID()] = true
+			evaluation.Skipped = append(evaluation.Skipped, composite.GetID())
+			continue
+		}
+		
+		result := composite.EvaluateComposite(evaluated)
+		evaluated[result.RuleID] = result
 		if result.Triggered {
-			results = append(results, result)
+			evaluation.Results = append(evaluation.Results, result)
+			if composite.config.ReplaceChildren {
+				for _, child := range composite.Children() {
+					replaced[child] = true
+				}
+			}
+			e.checkShortCircuit(&evaluation, result)
 		}
 	}
 	
-	return results
+	// Regras compostas com replace_children substituem o score das filhas
+	if len(replaced) > 0 {
+		filtered := evaluation.Results[:0]
+		for _, result := range evaluation.Results {
+			if !replaced[result.RuleID] {
+				filtered = append(filtered, result)
+			}
+		}
+		evaluation.Results = filtered
+	}
+	
+	return evaluation
 }
 
-// CalculateTotalScore calcula a pontuação total de risco
-func (e *RuleEngine) CalculateTotalScore(results []RuleResult) int {
-	totalScore := 0
-	for _, result := range results {
-		totalScore += result.Score
+// priorityLevels agrupa as regras habilitadas por prioridade efetiva
+func (e *RuleEngine) priorityLevels() [][]FraudRule {
+	levels := make([][]FraudRule, 0)
+	current := -1
+	
+	for _, rule := range e.rules {
+		if !rule.IsEnabled() {
+			continue
+		}
+		priority := e.priority(rule)
+		if len(levels) == 0 || priority != current {
+			levels = append(levels, make([]FraudRule, 0))
+			current = priority
+		}
+		levels[len(levels)-1] = append(levels[len(levels)-1], rule)
 	}
 	
-	// Limita o score entre 0 e 100
-	if totalScore > 100 {
-		totalScore = 100
+	return levels
+}
+
+// ruleOutcome resultado de uma regra executada com prazo
+type ruleOutcome struct {
+	result           RuleResult
+	timedOut         bool
+	travelAdjustment *TravelAdjustment
+}
+
+// evaluateLevel executa em paralelo as regras de um nível de prioridade,
+// preservando a ordem de registro nos resultados
+func (e *RuleEngine) evaluateLevel(ctx context.Context, level []FraudRule, transaction *models.Transaction, profile *models.UserProfile) []ruleOutcome {
+	outcomes := make([]ruleOutcome, len(level))
+	
+	var wg sync.WaitGroup
+	for i, rule := range level {
+		wg.Add(1)
+		go func(i int, rule FraudRule) {
+			defer wg.Done()
+			outcomes[i] = e.evaluateRule(ctx, rule, transaction, profile)
+		}(i, rule)
+	}
+	wg.Wait()
+	
+	return outcomes
+}
+
+// evaluateRule executa uma regra com o seu prazo. Uma regra que não termina
+// a tempo tem o resultado definido pela política de timeout, e uma que entra
+// em pânico, pela política de pânico.
+func (e *RuleEngine) evaluateRule(ctx context.Context, rule FraudRule, transaction *models.Transaction, profile *models.UserProfile) ruleOutcome {
+	timeout := e.ruleTimeout
+	if override, exists := e.ruleTimeouts[rule.GetID()]; exists {
+		timeout = override
 	}
 	
-	return totalScore
+	ruleCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	
+	// Canal com buffer: a goroutine termina mesmo que o resultado seja descartado
+	done := make(chan RuleResult, 1)
+	failed := make(chan interface{}, 1)
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				failed <- recovered
+			}
+		}()
+		done <- rule.Evaluate(ruleCtx, transaction, profile)
+	}()
+	
+	select {
+	case result := <-done:
+		adjustment := applyTravelNotice(rule, &result, transaction)
+		return ruleOutcome{result: result, travelAdjustment: adjustment}
+	case recovered := <-failed:
+		result := policyResult(rule, e.panicPolicy, fmt.Sprint(recovered))
+		result.Details["panic"] = true
+		return ruleOutcome{result: result}
+	case <-ruleCtx.Done():
+		result := policyResult(rule, e.timeoutPolicy, ruleCtx.Err().Error())
+		result.Details["timeout"] = true
+		return ruleOutcome{result: result, timedOut: true}
+	}
+}
+
+// policyResult aplica a política a uma regra que não concluiu
+func policyResult(rule FraudRule, policy TimeoutPolicyConfig, reason string) RuleResult {
+	result := RuleResult{
+		RuleID:      rule.GetID(),
+		RuleName:    rule.GetName(),
+		Description: "Regra não concluída: " + rule.GetName(),
+		Details: map[string]interface{}{
+			"error":  reason,
+			"policy": policy.Mode,
+		},
+	}
+	
+	switch policy.Mode {
+	case TimeoutFailClosed:
+		result.Triggered = true
+		result.Score = rule.GetWeight()
+	case TimeoutPenalty:
+		result.Triggered = true
+		result.Score = policy.Penalty
+	}
+	
+	return result
+}
+
+// checkShortCircuit marca a interrupção da avaliação após uma regra acionada
+func (e *RuleEngine) checkShortCircuit(evaluation *Evaluation, result RuleResult) {
+	if evaluation.ShortCircuit != "" {
+		return
+	}
+	
+	if e.shortCircuit.OnVeto {
+		for _, veto := range e.vetoes {
+			// Só um veto BLOCKED é definitivo: nenhuma outra regra pode mudar a decisão
+			if veto.RuleID == result.RuleID && veto.Decision == models.DecisionBlocked {
+				evaluation.ShortCircuit = ShortCircuitVeto
+				evaluation.ShortCircuitRule = result.RuleID
+				return
+			}
+		}
+	}
+	
+	if e.shortCircuit.OnMaxScore && e.aggregator.Aggregate(evaluation.Results) >= 100 {
+		evaluation.ShortCircuit = ShortCircuitMaxScore
+	}
+}
+
+// evaluatedChildren verifica se nenhuma regra filha da composta foi ignorada
+func evaluatedChildren(composite *CompositeRule, skipped map[string]bool) bool {
+	for _, child := range composite.Children() {
+		if skipped[child] {
+			return false
+		}
+	}
+	return true
+}
+
+// CalculateTotalScore calcula a pontuação total de risco com a estratégia
+// de agregação configurada
+func (e *RuleEngine) CalculateTotalScore(results []RuleResult) int {
+	return e.aggregator.Aggregate(results)
+}
+
+// AggregationStrategy retorna o nome da estratégia de agregação em uso
+func (e *RuleEngine) AggregationStrategy() string {
+	return e.aggregator.Name()
+}
+
+// mandatoryVetoes vetos que valem com qualquer configuração
+func mandatoryVetoes() []VetoConfig {
+	return []VetoConfig{{RuleID: PIXLimitRuleID, Decision: models.DecisionBlocked}}
+}
+
+// ApplyVetoes verifica se alguma regra de veto foi acionada. Se mais de um
+// veto for acionado, prevalece a decisão mais restritiva.
+func (e *RuleEngine) ApplyVetoes(results []RuleResult) (*VetoConfig, bool) {
+	var applied *VetoConfig
+	
+	for i := range e.vetoes {
+		veto := &e.vetoes[i]
+		for _, result := range results {
+			if !result.Triggered || result.RuleID != veto.RuleID {
+				continue
+			}
+			if applied == nil || decisionSeverity(veto.Decision) > decisionSeverity(applied.Decision) {
+				applied = veto
+			}
+		}
+	}
+	
+	return applied, applied != nil
+}
+
+// StricterDecision retorna a mais restritiva entre duas decisões
+func StricterDecision(a, b models.Decision) models.Decision {
+	if decisionSeverity(b) > decisionSeverity(a) {
+		return b
+	}
+	return a
+}
+
+// decisionSeverity ordena as decisões da menos para a mais restritiva
+func decisionSeverity(decision models.Decision) int {
+	switch decision {
+	case models.DecisionBlocked:
+		return 2
+	case models.DecisionReview:
+		return 1
+	default:
+		return 0
+	}
 }
 
 // GetRiskLevel determina o nível de risco baseado no score
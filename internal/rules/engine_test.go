@@ -0,0 +1,380 @@
+package rules
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+func testTransaction() *models.Transaction {
+	return &models.Transaction{
+		ID:        "tx-1",
+		UserID:    "user-1",
+		Amount:    models.NewMoney(10000, "BRL"),
+		Currency:  "BRL",
+		Timestamp: time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC),
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func TestEvaluationShortCircuit(t *testing.T) {
+	// Regras de expressão sempre acionadas, em níveis de prioridade controlados
+	always := func(id string, weight, priority int) ExpressionRuleConfig {
+		return ExpressionRuleConfig{ID: id, Weight: weight, Priority: priority, Expression: "tx.amount >= 0"}
+	}
+
+	tests := []struct {
+		name         string
+		config       EngineConfig
+		shortCircuit string
+		rule         string
+		evaluated    []string
+		skipped      []string
+	}{
+		{
+			name: "blocked veto stops after its level",
+			config: EngineConfig{
+				Expressions: []ExpressionRuleConfig{always("first", 10, 1), always("same_level", 5, 1), always("later", 5, 15)},
+				Vetoes:      []VetoConfig{{RuleID: "first", Decision: models.DecisionBlocked}},
+				Evaluation:  EvaluationConfig{ShortCircuit: ShortCircuitConfig{OnVeto: true}},
+			},
+			shortCircuit: ShortCircuitVeto,
+			rule:         "first",
+			evaluated:    []string{"first", "same_level"},
+			skipped:      []string{"later", "high_amount_rule", "velocity_rule", PIXLimitRuleID},
+		},
+		{
+			name: "review veto does not stop",
+			config: EngineConfig{
+				Expressions: []ExpressionRuleConfig{always("first", 10, 1), always("later", 5, 15)},
+				Vetoes:      []VetoConfig{{RuleID: "first", Decision: models.DecisionReview}},
+				Evaluation:  EvaluationConfig{ShortCircuit: ShortCircuitConfig{OnVeto: true}},
+			},
+			evaluated: []string{"first", "later"},
+		},
+		{
+			name: "veto without on_veto does not stop",
+			config: EngineConfig{
+				Expressions: []ExpressionRuleConfig{always("first", 10, 1), always("later", 5, 15)},
+				Vetoes:      []VetoConfig{{RuleID: "first", Decision: models.DecisionBlocked}},
+			},
+			evaluated: []string{"first", "later"},
+		},
+		{
+			name: "max score stops after its level",
+			config: EngineConfig{
+				Expressions: []ExpressionRuleConfig{always("first", 60, 1), always("second", 40, 2), always("later", 5, 15)},
+				Evaluation:  EvaluationConfig{ShortCircuit: ShortCircuitConfig{OnMaxScore: true}},
+			},
+			shortCircuit: ShortCircuitMaxScore,
+			evaluated:    []string{"first", "second"},
+			skipped:      []string{"later", "high_amount_rule"},
+		},
+		{
+			name: "priority override moves the veto to the end",
+			config: EngineConfig{
+				Expressions: []ExpressionRuleConfig{always("first", 10, 1), always("later", 5, 15)},
+				Vetoes:      []VetoConfig{{RuleID: "first", Decision: models.DecisionBlocked}},
+				Evaluation: EvaluationConfig{
+					Priorities:   map[string]int{"first": 90},
+					ShortCircuit: ShortCircuitConfig{OnVeto: true},
+				},
+			},
+			shortCircuit: ShortCircuitVeto,
+			rule:         "first",
+			evaluated:    []string{"first", "later"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine, err := NewRuleEngineFromConfig(tt.config, Dependencies{})
+			if err != nil {
+				t.Fatalf("NewRuleEngineFromConfig: %v", err)
+			}
+
+			evaluation := engine.Evaluate(context.Background(), testTransaction(), nil)
+
+			if evaluation.ShortCircuit != tt.shortCircuit {
+				t.Errorf("ShortCircuit = %q, want %q", evaluation.ShortCircuit, tt.shortCircuit)
+			}
+			if evaluation.ShortCircuitRule != tt.rule {
+				t.Errorf("ShortCircuitRule = %q, want %q", evaluation.ShortCircuitRule, tt.rule)
+			}
+
+			triggered := make([]string, 0, len(evaluation.Results))
+			for _, result := range evaluation.Results {
+				triggered = append(triggered, result.RuleID)
+			}
+			for _, id := range tt.evaluated {
+				if !contains(triggered, id) {
+					t.Errorf("%s not triggered; triggered: %v", id, triggered)
+				}
+				if contains(evaluation.Skipped, id) {
+					t.Errorf("%s reported as skipped", id)
+				}
+			}
+			for _, id := range tt.skipped {
+				if !contains(evaluation.Skipped, id) {
+					t.Errorf("%s not skipped; skipped: %v", id, evaluation.Skipped)
+				}
+			}
+			if tt.shortCircuit == "" && len(evaluation.Skipped) > 0 {
+				t.Errorf("unexpected skipped rules: %v", evaluation.Skipped)
+			}
+		})
+	}
+}
+
+func TestPriorityOrder(t *testing.T) {
+	engine, err := NewRuleEngineFromConfig(EngineConfig{
+		Expressions: []ExpressionRuleConfig{{ID: "custom", Priority: 15, Expression: "tx.amount > 0"}},
+		Evaluation:  EvaluationConfig{Priorities: map[string]int{"velocity_rule": 5}},
+	}, Dependencies{})
+	if err != nil {
+		t.Fatalf("NewRuleEngineFromConfig: %v", err)
+	}
+
+	previous := -1
+	for i, level := range engine.priorityLevels() {
+		priority := engine.priority(level[0])
+		if priority <= previous {
+			t.Fatalf("level %d has priority %d after %d", i, priority, previous)
+		}
+		previous = priority
+		for _, rule := range level {
+			if engine.priority(rule) != priority {
+				t.Errorf("rule %s with priority %d in level %d", rule.GetID(), engine.priority(rule), priority)
+			}
+		}
+	}
+
+	levels := engine.priorityLevels()
+	if got := levels[0][0].GetID(); got != "velocity_rule" {
+		t.Errorf("first rule = %s, want velocity_rule", got)
+	}
+	if got := engine.priority(levels[2][0]); got != 15 || levels[2][0].GetID() != "custom" {
+		t.Errorf("third level = %s (%d), want custom (15)", levels[2][0].GetID(), got)
+	}
+}
+
+func TestPriorityConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  EngineConfig
+		message string
+	}{
+		{
+			name:    "unknown rule",
+			config:  EngineConfig{Evaluation: EvaluationConfig{Priorities: map[string]int{"missing_rule": 1}}},
+			message: `priority references unknown rule "missing_rule"`,
+		},
+		{
+			name:    "negative expression priority",
+			config:  EngineConfig{Expressions: []ExpressionRuleConfig{{ID: "custom", Priority: -1, Expression: "tx.amount > 0"}}},
+			message: "priority must not be negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRuleEngineFromConfig(tt.config, Dependencies{})
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
+
+// unavailablePIXLimits simula o store de limites PIX fora do ar
+type unavailablePIXLimits struct{}
+
+func (unavailablePIXLimits) GetPIXLimits(ctx context.Context, userID string) (*models.PIXLimits, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestEvaluationReportsErroredRules(t *testing.T) {
+	engine, err := NewRuleEngineFromConfig(EngineConfig{}, Dependencies{PIXLimits: unavailablePIXLimits{}})
+	if err != nil {
+		t.Fatalf("NewRuleEngineFromConfig: %v", err)
+	}
+
+	transaction := testTransaction()
+	transaction.PIX = &models.PIXTransfer{KeyType: models.PIXKeyEmail, Key: "fulano@exemplo.com"}
+	evaluation := engine.Evaluate(context.Background(), transaction, nil)
+
+	if !contains(evaluation.Errored, PIXLimitRuleID) {
+		t.Errorf("Errored = %v, want %s", evaluation.Errored, PIXLimitRuleID)
+	}
+	if contains(evaluation.TimedOut, PIXLimitRuleID) {
+		t.Errorf("%s reported as timed out", PIXLimitRuleID)
+	}
+}
+
+// stubRule regra de teste que demora delay para concluir, ou entra em pânico
+type stubRule struct {
+	id       string
+	delay    time.Duration
+	panics   bool
+	inFlight *int32
+	maxSeen  *int32
+}
+
+func (r *stubRule) GetID() string    { return r.id }
+func (r *stubRule) GetName() string  { return r.id }
+func (r *stubRule) GetWeight() int   { return 30 }
+func (r *stubRule) GetPriority() int { return 1 }
+func (r *stubRule) IsEnabled() bool  { return true }
+
+func (r *stubRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	if r.inFlight != nil {
+		current := atomic.AddInt32(r.inFlight, 1)
+		defer atomic.AddInt32(r.inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(r.maxSeen)
+			if current <= seen || atomic.CompareAndSwapInt32(r.maxSeen, seen, current) {
+				break
+			}
+		}
+	}
+	if r.panics {
+		panic("rule crashed")
+	}
+	select {
+	case <-time.After(r.delay):
+	case <-ctx.Done():
+	}
+	return RuleResult{RuleID: r.id, RuleName: r.id, Triggered: true, Score: r.GetWeight()}
+}
+
+func newStubEngine(t *testing.T, evaluation EvaluationConfig, stubs ...*stubRule) *RuleEngine {
+	t.Helper()
+	engine, err := NewRuleEngineFromConfig(EngineConfig{Evaluation: evaluation}, Dependencies{})
+	if err != nil {
+		t.Fatalf("NewRuleEngineFromConfig: %v", err)
+	}
+	for _, stub := range stubs {
+		engine.RegisterRule(stub)
+	}
+	return engine
+}
+
+func resultFor(evaluation Evaluation, ruleID string) (RuleResult, bool) {
+	for _, result := range evaluation.Results {
+		if result.RuleID == ruleID {
+			return result, true
+		}
+	}
+	return RuleResult{}, false
+}
+
+func TestSameLevelRulesRunConcurrently(t *testing.T) {
+	var inFlight, maxSeen int32
+	stubs := make([]*stubRule, 3)
+	for i := range stubs {
+		stubs[i] = &stubRule{id: fmt.Sprintf("slow_%d", i), delay: 50 * time.Millisecond, inFlight: &inFlight, maxSeen: &maxSeen}
+	}
+	engine := newStubEngine(t, EvaluationConfig{RuleTimeoutMs: 1000, TotalTimeoutMs: 2000}, stubs...)
+
+	evaluation := engine.Evaluate(context.Background(), testTransaction(), nil)
+
+	if got := atomic.LoadInt32(&maxSeen); got != int32(len(stubs)) {
+		t.Errorf("at most %d rules ran at once, want %d", got, len(stubs))
+	}
+	for _, stub := range stubs {
+		if _, ok := resultFor(evaluation, stub.id); !ok {
+			t.Errorf("%s not triggered", stub.id)
+		}
+	}
+	if len(evaluation.TimedOut) > 0 || len(evaluation.Errored) > 0 {
+		t.Errorf("TimedOut = %v, Errored = %v, want none", evaluation.TimedOut, evaluation.Errored)
+	}
+}
+
+func TestEvaluationDeadlines(t *testing.T) {
+	tests := []struct {
+		name       string
+		evaluation EvaluationConfig
+		ctxTimeout time.Duration
+	}{
+		{"rule timeout", EvaluationConfig{RuleTimeoutMs: 20, TotalTimeoutMs: 2000}, 0},
+		{"total timeout", EvaluationConfig{RuleTimeoutMs: 2000, TotalTimeoutMs: 20}, 0},
+		{"request context", EvaluationConfig{RuleTimeoutMs: 2000, TotalTimeoutMs: 2000}, 20 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.evaluation.TimeoutPolicy = TimeoutPolicyConfig{Mode: TimeoutPenalty, Penalty: 7}
+			engine := newStubEngine(t, tt.evaluation, &stubRule{id: "stuck", delay: 5 * time.Second})
+
+			ctx := context.Background()
+			if tt.ctxTimeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tt.ctxTimeout)
+				defer cancel()
+			}
+
+			start := time.Now()
+			evaluation := engine.Evaluate(ctx, testTransaction(), nil)
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Fatalf("evaluation took %s, deadline not enforced", elapsed)
+			}
+
+			if !contains(evaluation.TimedOut, "stuck") || contains(evaluation.Errored, "stuck") {
+				t.Errorf("TimedOut = %v, Errored = %v, want stuck timed out", evaluation.TimedOut, evaluation.Errored)
+			}
+			if result, ok := resultFor(evaluation, "stuck"); !ok || result.Score != 7 {
+				t.Errorf("stuck result = %+v, want timeout penalty 7", result)
+			}
+		})
+	}
+}
+
+func TestPanickingRuleIsErroredNotTimedOut(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    TimeoutPolicyConfig
+		triggered bool
+		score     int
+	}{
+		{"fail open by default", TimeoutPolicyConfig{}, false, 0},
+		{"fail closed", TimeoutPolicyConfig{Mode: TimeoutFailClosed}, true, 30},
+		{"penalty", TimeoutPolicyConfig{Mode: TimeoutPenalty, Penalty: 5}, true, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newStubEngine(t, EvaluationConfig{
+				TimeoutPolicy: TimeoutPolicyConfig{Mode: TimeoutPenalty, Penalty: 50},
+				PanicPolicy:   tt.policy,
+			}, &stubRule{id: "crashing", panics: true})
+
+			evaluation := engine.Evaluate(context.Background(), testTransaction(), nil)
+
+			if !contains(evaluation.Errored, "crashing") || contains(evaluation.TimedOut, "crashing") {
+				t.Fatalf("TimedOut = %v, Errored = %v, want crashing errored", evaluation.TimedOut, evaluation.Errored)
+			}
+			result, triggered := resultFor(evaluation, "crashing")
+			if triggered != tt.triggered || result.Score != tt.score {
+				t.Errorf("triggered = %v with score %d, want %v with %d", triggered, result.Score, tt.triggered, tt.score)
+			}
+			if triggered && (result.Details["panic"] != true || result.Details["timeout"] != nil) {
+				t.Errorf("details = %v, want a panic without timeout", result.Details)
+			}
+		})
+	}
+}
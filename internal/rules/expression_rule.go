@@ -0,0 +1,345 @@
+package rules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/expr"
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// ExpressionRuleConfig regra escrita por analistas na linguagem de expressões,
+// ex.: tx.amount > profile.avg * 4 && tx.location.country != "BR"
+type ExpressionRuleConfig struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Weight      int    `json:"weight"`
+	Priority    int    `json:"priority,omitempty"`
+	Enabled     *bool  `json:"enabled,omitempty"`
+	Expression  string `json:"expression"`
+}
+
+// velocityWindows janelas expostas como variáveis velocity.* nas expressões
+var velocityWindows = map[string]time.Duration{
+	"10m": 10 * time.Minute,
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+}
+
+// expressionVariables variáveis disponíveis nas expressões e seus tipos
+var expressionVariables = map[string]expr.Type{
+	"tx.id":                 expr.TypeString,
+	"tx.user_id":            expr.TypeString,
+	"tx.amount":             expr.TypeNumber,
+	"tx.original_amount":    expr.TypeNumber,
+	"tx.currency":           expr.TypeString,
+	"tx.merchant":           expr.TypeString,
+	"tx.card_last4":         expr.TypeString,
+	"tx.card_type":          expr.TypeString,
+	"tx.card_country":       expr.TypeString,
+	"tx.card_bin":           expr.TypeString,
+	"tx.card_fingerprint":   expr.TypeString,
+	"tx.card_bank":          expr.TypeString,
+	"tx.card_product":       expr.TypeString,
+	"tx.card_commercial":    expr.TypeBool,
+	"tx.ip_country":         expr.TypeString,
+	"tx.description":        expr.TypeString,
+	"tx.hour":               expr.TypeNumber,
+	"tx.weekday":            expr.TypeNumber,
+	"tx.location.country":   expr.TypeString,
+	"tx.location.city":      expr.TypeString,
+	"tx.location.latitude":  expr.TypeNumber,
+	"tx.location.longitude": expr.TypeNumber,
+	"tx.location.ip":        expr.TypeString,
+	"tx.ip.city":            expr.TypeString,
+	"tx.ip.asn":             expr.TypeNumber,
+	"tx.ip.organization":    expr.TypeString,
+	"tx.ip.categories":      expr.TypeStringList,
+	"tx.device.id":          expr.TypeString,
+	"tx.device.type":        expr.TypeString,
+	"tx.device.os":          expr.TypeString,
+	"tx.device.browser":     expr.TypeString,
+	"tx.device.user_agent":  expr.TypeString,
+	"tx.device.fingerprint": expr.TypeString,
+	"tx.travel_notice":      expr.TypeBool,
+
+	"profile.exists":             expr.TypeBool,
+	"profile.avg":                expr.TypeNumber,
+	"profile.total_transactions": expr.TypeNumber,
+	"profile.account_age_days":   expr.TypeNumber,
+	"profile.minutes_since_last": expr.TypeNumber,
+	"profile.fraud_count":        expr.TypeNumber,
+	"profile.known_merchant":     expr.TypeBool,
+	"profile.known_country":      expr.TypeBool,
+	"profile.trusted_device":     expr.TypeBool,
+	"profile.known_card":         expr.TypeBool,
+	"profile.device_approvals":   expr.TypeNumber,
+	"profile.countries":          expr.TypeStringList,
+	"profile.merchants":          expr.TypeStringList,
+
+	"velocity.count_10m":  expr.TypeNumber,
+	"velocity.count_1h":   expr.TypeNumber,
+	"velocity.count_24h":  expr.TypeNumber,
+	"velocity.amount_10m": expr.TypeNumber,
+	"velocity.amount_1h":  expr.TypeNumber,
+	"velocity.amount_24h": expr.TypeNumber,
+}
+
+// ExpressionVariables retorna as variáveis disponíveis nas expressões
+func ExpressionVariables() map[string]string {
+	variables := make(map[string]string, len(expressionVariables))
+	for name, t := range expressionVariables {
+		variables[name] = t.String()
+	}
+	return variables
+}
+
+// ExpressionRule regra de fraude definida por uma expressão compilada
+type ExpressionRule struct {
+	config   ExpressionRuleConfig
+	program  *expr.Program
+	velocity VelocityProvider
+}
+
+// NewExpressionRule compila e valida uma regra de expressão. Listas nomeadas
+// ficam disponíveis pela função in_list("nome", valor).
+func NewExpressionRule(config ExpressionRuleConfig, lists map[string][]string, deps Dependencies) (*ExpressionRule, error) {
+	if config.ID == "" {
+		return nil, fmt.Errorf("expression rule: id is required")
+	}
+	if config.Weight < 0 {
+		return nil, fmt.Errorf("expression rule %q: weight must not be negative", config.ID)
+	}
+	if config.Priority < 0 {
+		return nil, fmt.Errorf("expression rule %q: priority must not be negative", config.ID)
+	}
+
+	sets := make(map[string]map[string]bool, len(lists))
+	for name, values := range lists {
+		set := make(map[string]bool, len(values))
+		for _, value := range values {
+			set[value] = true
+		}
+		sets[name] = set
+	}
+
+	schema := expr.Schema{
+		Variables: expressionVariables,
+		Functions: expr.Functions(map[string]expr.Function{
+			"in_list": {
+				Params: []expr.Type{expr.TypeString, expr.TypeString},
+				Result: expr.TypeBool,
+				Validate: func(literals []interface{}) error {
+					name, ok := literals[0].(string)
+					if !ok {
+						return fmt.Errorf("list name must be a string literal")
+					}
+					if _, exists := sets[name]; !exists {
+						return fmt.Errorf("unknown list %q", name)
+					}
+					return nil
+				},
+				Call: func(args []interface{}) (interface{}, error) {
+					return sets[args[0].(string)][args[1].(string)], nil
+				},
+			},
+		}),
+	}
+
+	program, err := expr.Compile(config.Expression, schema)
+	if err != nil {
+		return nil, fmt.Errorf("expression rule %q: %w", config.ID, err)
+	}
+
+	return &ExpressionRule{config: config, program: program, velocity: deps.Velocity}, nil
+}
+
+func (r *ExpressionRule) GetID() string { return r.config.ID }
+func (r *ExpressionRule) GetName() string {
+	if r.config.Name == "" {
+		return r.config.ID
+	}
+	return r.config.Name
+}
+func (r *ExpressionRule) GetWeight() int { return r.config.Weight }
+func (r *ExpressionRule) GetPriority() int {
+	// Expressões podem consultar a velocidade do usuário
+	if r.config.Priority == 0 {
+		return PriorityLookup
+	}
+	return r.config.Priority
+}
+func (r *ExpressionRule) IsEnabled() bool {
+	return r.config.Enabled == nil || *r.config.Enabled
+}
+
+func (r *ExpressionRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	description := r.config.Description
+	if description == "" {
+		description = "Regra de expressão acionada: " + r.GetName()
+	}
+
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: description,
+		Details: map[string]interface{}{
+			"expression": r.program.Source(),
+		},
+	}
+
+	env, err := r.environment(ctx, transaction, profile)
+	if err == nil {
+		result.Triggered, err = r.program.Eval(env)
+	}
+	if err != nil {
+		// Erros de avaliação nunca acionam a regra, mas ficam registrados
+		result.Triggered = false
+		result.Details["error"] = err.Error()
+		return result
+	}
+
+	values := make(map[string]interface{}, len(r.program.Variables()))
+	for _, name := range r.program.Variables() {
+		values[name] = env[name]
+	}
+	result.Details["variables"] = values
+
+	if result.Triggered {
+		result.Score = r.GetWeight()
+	}
+
+	return result
+}
+
+// environment monta os valores das variáveis usadas pela expressão
+func (r *ExpressionRule) environment(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) (map[string]interface{}, error) {
+	local, _ := transaction.LocalTime()
+	env := map[string]interface{}{
+		"tx.id":                 transaction.ID,
+		"tx.user_id":            transaction.UserID,
+		"tx.amount":             transaction.AmountInBase().Float64(),
+		"tx.original_amount":    transaction.Amount.Float64(),
+		"tx.currency":           transaction.Currency,
+		"tx.merchant":           transaction.Merchant,
+		"tx.card_last4":         transaction.CardLast4,
+		"tx.card_type":          transaction.CardType,
+		"tx.card_country":       transaction.CardCountry,
+		"tx.card_bin":           transaction.CardBIN,
+		"tx.card_fingerprint":   transaction.CardFingerprint,
+		"tx.card_bank":          "",
+		"tx.card_product":       "",
+		"tx.card_commercial":    false,
+		"tx.ip_country":         transaction.IPCountry,
+		"tx.description":        transaction.Description,
+		"tx.hour":               local.Hour(),
+		"tx.weekday":            int(local.Weekday()),
+		"tx.location.country":   transaction.Location.Country,
+		"tx.location.city":      transaction.Location.City,
+		"tx.location.latitude":  transaction.Location.Latitude,
+		"tx.location.longitude": transaction.Location.Longitude,
+		"tx.location.ip":        transaction.Location.IPAddress,
+		"tx.ip.city":            "",
+		"tx.ip.asn":             0,
+		"tx.ip.organization":    "",
+		"tx.ip.categories":      []string{},
+		"tx.device.id":          transaction.DeviceInfo.DeviceID,
+		"tx.device.type":        transaction.DeviceInfo.DeviceType,
+		"tx.device.os":          transaction.DeviceInfo.OS,
+		"tx.device.browser":     transaction.DeviceInfo.Browser,
+		"tx.device.user_agent":  transaction.DeviceInfo.UserAgent,
+		"tx.device.fingerprint": transaction.DeviceInfo.Fingerprint,
+		"tx.travel_notice":      transaction.TravelNotice != nil,
+
+		"profile.exists":             profile != nil,
+		"profile.avg":                0.0,
+		"profile.total_transactions": 0,
+		"profile.account_age_days":   0.0,
+		"profile.minutes_since_last": 0.0,
+		"profile.fraud_count":        0,
+		"profile.known_merchant":     false,
+		"profile.known_country":      false,
+		"profile.trusted_device":     false,
+		"profile.known_card":         false,
+		"profile.device_approvals":   0,
+		"profile.countries":          []string{},
+		"profile.merchants":          []string{},
+	}
+
+	if ip := transaction.IPGeolocation; ip != nil {
+		env["tx.ip.city"] = ip.City
+		env["tx.ip.asn"] = ip.ASN
+		env["tx.ip.organization"] = ip.Organization
+	}
+	if reputation := transaction.IPReputation; reputation != nil {
+		env["tx.ip.categories"] = reputation.Categories
+	}
+	if issuer := transaction.CardIssuer; issuer != nil {
+		env["tx.card_bank"] = issuer.Bank
+		env["tx.card_product"] = issuer.ProductType
+		env["tx.card_commercial"] = issuer.Commercial
+	}
+
+	if profile != nil {
+		env["profile.avg"] = profile.AvgTransactionValue.Float64()
+		env["profile.total_transactions"] = profile.TotalTransactions
+		env["profile.fraud_count"] = len(profile.FraudHistory)
+		if !profile.FirstTransactionAt.IsZero() {
+			env["profile.account_age_days"] = transaction.Timestamp.Sub(profile.FirstTransactionAt).Hours() / 24
+		}
+		if !profile.LastTransactionAt.IsZero() {
+			env["profile.minutes_since_last"] = transaction.Timestamp.Sub(profile.LastTransactionAt).Minutes()
+		}
+
+		countries := make([]string, 0, len(profile.CommonLocations))
+		for _, location := range profile.CommonLocations {
+			countries = append(countries, location.Country)
+			if location.Country == transaction.Location.Country {
+				env["profile.known_country"] = true
+			}
+		}
+		env["profile.countries"] = countries
+		env["profile.merchants"] = profile.CommonMerchants
+
+		for _, merchant := range profile.CommonMerchants {
+			if merchant == transaction.Merchant {
+				env["profile.known_merchant"] = true
+			}
+		}
+		for _, card := range profile.KnownCards {
+			if card != "" && card == transaction.CardFingerprint {
+				env["profile.known_card"] = true
+			}
+		}
+		env["profile.trusted_device"] = profile.IsTrustedDevice(transaction.DeviceInfo)
+		env["profile.device_approvals"] = profile.DeviceApprovals[transaction.DeviceInfo.Key()]
+	}
+
+	// Velocidade só é consultada se a expressão usar alguma variável velocity.*
+	for _, name := range r.program.Variables() {
+		if !strings.HasPrefix(name, "velocity.") {
+			continue
+		}
+		if r.velocity == nil {
+			return nil, fmt.Errorf("velocity data is not available")
+		}
+		for suffix, window := range velocityWindows {
+			// Não consulta o store se o prazo da regra já expirou
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			check, err := r.velocity.GetVelocity(ctx, transaction.UserID, window, transaction.Timestamp)
+			if err != nil {
+				return nil, err
+			}
+			env["velocity.count_"+suffix] = check.TransactionCount
+			env["velocity.amount_"+suffix] = check.TotalAmount.Float64()
+		}
+		break
+	}
+
+	return env, nil
+}
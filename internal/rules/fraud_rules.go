@@ -1,7 +1,9 @@
 package rules
 
 import (
+	"context"
 	"math"
+	"strings"
 	"time"
 	
 	"github.com/anti-fraud-golang/internal/models"
@@ -13,6 +15,7 @@ type HighAmountRule struct {
 	Name        string
 	Enabled     bool
 	Weight      int
+	Priority    int
 	Threshold   float64
 }
 
@@ -34,22 +37,29 @@ func (r *HighAmountRule) GetWeight() int {
 	}
 	return r.Weight
 }
+func (r *HighAmountRule) GetPriority() int { 
+	if r.Priority == 0 {
+		return PriorityCheap
+	}
+	return r.Priority
+}
 func (r *HighAmountRule) IsEnabled() bool { 
 	return true
 }
 
-func (r *HighAmountRule) Evaluate(transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+func (r *HighAmountRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
 	threshold := 10000.0
 	if r.Threshold > 0 {
 		threshold = r.Threshold
 	}
 	
-	triggered := transaction.Amount > threshold
+	amount := transaction.AmountInBase()
+	triggered := amount.Float64() > threshold
 	score := 0
 	
 	if triggered {
 		// Score baseado em quanto excede o threshold
-		factor := transaction.Amount / threshold
+		factor := amount.Float64() / threshold
 		if factor > 5 {
 			score = r.GetWeight()
 		} else if factor > 3 {
@@ -66,7 +76,7 @@ func (r *HighAmountRule) Evaluate(transaction *models.Transaction, profile *mode
 		Score:       score,
 		Description: "Transação com valor acima do limite normal",
 		Details: map[string]interface{}{
-			"amount":    transaction.Amount,
+			"amount":    amount,
 			"threshold": threshold,
 		},
 	}
@@ -78,9 +88,10 @@ type VelocityRule struct{}
 func (r *VelocityRule) GetID() string { return "velocity_rule" }
 func (r *VelocityRule) GetName() string { return "Transaction Velocity" }
 func (r *VelocityRule) GetWeight() int { return 20 }
+func (r *VelocityRule) GetPriority() int { return PriorityStandard }
 func (r *VelocityRule) IsEnabled() bool { return true }
 
-func (r *VelocityRule) Evaluate(transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+func (r *VelocityRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
 	// Simula verificação de velocidade
 	// Em produção, isso consultaria um cache/database
 	triggered := false
@@ -89,8 +100,9 @@ func (r *VelocityRule) Evaluate(transaction *models.Transaction, profile *models
 	if profile != nil && profile.LastTransactionAt.After(time.Time{}) {
 		timeDiff := transaction.Timestamp.Sub(profile.LastTransactionAt)
 		
-		// Se houver outra transação nos últimos 5 minutos
-		if timeDiff < 5*time.Minute {
+		// Se houver outra transação a menos de 5 minutos do evento; eventos
+		// atrasados podem ser anteriores à última transação registrada
+		if timeDiff < 5*time.Minute && timeDiff > -5*time.Minute {
 			triggered = true
 			score = r.GetWeight()
 		}
@@ -106,66 +118,213 @@ func (r *VelocityRule) Evaluate(transaction *models.Transaction, profile *models
 	}
 }
 
-// GeoVelocityRule detecta mudanças geográficas impossíveis
-type GeoVelocityRule struct{}
+// GeoVelocityRule detecta viagens impossíveis entre a última localização
+// conhecida do usuário e a da transação. Entre cidades com aeroporto o limite
+// de velocidade é maior, e rotas de voo conhecidas usam a duração do voo.
+type GeoVelocityRule struct {
+	MaxSpeedKmh   float64
+	MinDistanceKm float64
+	AirportCities []string
+	FlightRoutes  []models.FlightRoute
+}
+
+// airportSpeedFactor folga do limite entre cidades com aeroporto: com vento de
+// cauda um jato passa de 1.000 km/h em relação ao solo, e compras no embarque e
+// no desembarque medem o tempo de voo sem nenhuma margem
+const airportSpeedFactor = 1.25
 
 func (r *GeoVelocityRule) GetID() string { return "geo_velocity_rule" }
 func (r *GeoVelocityRule) GetName() string { return "Geographical Velocity" }
 func (r *GeoVelocityRule) GetWeight() int { return 30 }
+func (r *GeoVelocityRule) GetPriority() int { return PriorityStandard }
 func (r *GeoVelocityRule) IsEnabled() bool { return true }
 
-func (r *GeoVelocityRule) Evaluate(transaction *models.Transaction, profile *models.UserProfile) RuleResult {
-	triggered := false
-	score := 0
-	
-	if profile != nil && len(profile.CommonLocations) > 0 {
-		lastLocation := profile.CommonLocations[len(profile.CommonLocations)-1]
-		
-		// Calcula distância entre localizações
-		distance := calculateDistance(
-			lastLocation.Latitude, lastLocation.Longitude,
-			transaction.Location.Latitude, transaction.Location.Longitude,
-		)
-		
-		timeDiff := transaction.Timestamp.Sub(profile.LastTransactionAt).Hours()
-		
-		// Velocidade em km/h
-		if timeDiff > 0 {
-			speed := distance / timeDiff
-			
-			// Se a velocidade for maior que 900 km/h (velocidade de avião)
-			if speed > 900 && distance > 100 {
-				triggered = true
-				score = r.GetWeight()
-			}
-		}
-	}
-	
-	return RuleResult{
+func (r *GeoVelocityRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	result := RuleResult{
 		RuleID:      r.GetID(),
 		RuleName:    r.GetName(),
-		Triggered:   triggered,
-		Score:       score,
 		Description: "Mudança geográfica impossível detectada",
 		Details:     map[string]interface{}{},
 	}
+	
+	velocity := r.geoVelocity(transaction, profile)
+	if velocity == nil {
+		return result
+	}
+	result.Details["geo_velocity"] = velocity
+	
+	if !velocity.IsPossible {
+		result.Triggered = true
+		result.Score = r.GetWeight()
+	}
+	
+	return result
 }
 
-// UnusualHourRule detecta transações em horários incomuns
-type UnusualHourRule struct{}
+// geoVelocity calcula o deslocamento desde a última localização conhecida.
+// Retorna nil sem coordenadas ou para eventos anteriores à última posição.
+func (r *GeoVelocityRule) geoVelocity(transaction *models.Transaction, profile *models.UserProfile) *models.GeoVelocity {
+	if profile == nil || profile.LastLocation == nil || !transaction.Location.HasCoordinates() {
+		return nil
+	}
+	
+	elapsed := transaction.Timestamp.Sub(profile.LastLocationAt)
+	if elapsed < 0 {
+		return nil
+	}
+	
+	previous := *profile.LastLocation
+	current := transaction.Location
+	current.IPAddress = ""
+	distance := calculateDistance(previous.Latitude, previous.Longitude, current.Latitude, current.Longitude)
+	
+	// Intervalos abaixo de um minuto contam como um minuto para a velocidade ser finita
+	hours := math.Max(elapsed.Hours(), 1.0/60)
+	
+	velocity := &models.GeoVelocity{
+		UserID:           transaction.UserID,
+		PreviousLocation: previous,
+		CurrentLocation:  current,
+		PreviousAt:       profile.LastLocationAt,
+		CurrentAt:        transaction.Timestamp,
+		Distance:         math.Round(distance*10) / 10,
+		TimeDiff:         int(elapsed.Minutes()),
+		Speed:            math.Round(distance/hours*10) / 10,
+		IsPossible:       true,
+	}
+	
+	minDistance := 100.0
+	if r.MinDistanceKm > 0 {
+		minDistance = r.MinDistanceKm
+	}
+	
+	if distance <= minDistance {
+		velocity.Basis = "distance"
+		return velocity
+	}
+	
+	// Rota conhecida decide pela duração do voo, mais precisa que a velocidade média
+	if duration := r.flightDuration(previous.City, current.City); duration > 0 {
+		velocity.Basis = "flight_route"
+		velocity.MinTravelTime = int(duration.Minutes())
+		velocity.IsPossible = elapsed >= duration
+		return velocity
+	}
+	
+	maxSpeed := 900.0 // velocidade de avião
+	if r.MaxSpeedKmh > 0 {
+		maxSpeed = r.MaxSpeedKmh
+	}
+	velocity.Basis = "speed"
+	if r.isAirportCity(previous.City) && r.isAirportCity(current.City) {
+		maxSpeed *= airportSpeedFactor
+		velocity.Basis = "airport"
+	}
+	velocity.MaxSpeed = maxSpeed
+	velocity.IsPossible = distance/hours <= maxSpeed
+	
+	return velocity
+}
+
+// flightDuration retorna a duração da rota entre as cidades em qualquer sentido; zero se desconhecida
+func (r *GeoVelocityRule) flightDuration(from, to string) time.Duration {
+	from, to = normalizeCity(from), normalizeCity(to)
+	if from == "" || to == "" {
+		return 0
+	}
+	for _, route := range r.FlightRoutes {
+		routeFrom, routeTo := normalizeCity(route.From), normalizeCity(route.To)
+		if (routeFrom == from && routeTo == to) || (routeFrom == to && routeTo == from) {
+			return time.Duration(route.DurationMinutes) * time.Minute
+		}
+	}
+	return 0
+}
+
+func (r *GeoVelocityRule) isAirportCity(city string) bool {
+	city = normalizeCity(city)
+	if city == "" {
+		return false
+	}
+	for _, airport := range r.AirportCities {
+		if normalizeCity(airport) == city {
+			return true
+		}
+	}
+	return false
+}
+
+// cityAccents remove acentos para que "São Paulo" e "Sao Paulo" sejam a mesma cidade
+var cityAccents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a", "ä", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "õ", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// normalizeCity normaliza o nome da cidade para comparação
+func normalizeCity(city string) string {
+	return cityAccents.Replace(strings.ToLower(strings.TrimSpace(city)))
+}
+
+// UnusualHourRule detecta transações em horários incomuns no horário local
+// da transação. Quando o usuário tem histórico suficiente, horários em que ele
+// costuma transacionar não são considerados suspeitos, mesmo de madrugada.
+type UnusualHourRule struct {
+	// NightHourStart e NightHourEnd delimitam a madrugada (inclusive);
+	// sem eles a janela padrão é de 23h às 5h. Valores iguais cobrem uma hora.
+	NightHourStart *int
+	NightHourEnd   *int
+	MinHistory     int
+	MinHourShare   float64
+}
 
 func (r *UnusualHourRule) GetID() string { return "unusual_hour_rule" }
 func (r *UnusualHourRule) GetName() string { return "Unusual Hour Transaction" }
 func (r *UnusualHourRule) GetWeight() int { return 10 }
+func (r *UnusualHourRule) GetPriority() int { return PriorityCheap }
 func (r *UnusualHourRule) IsEnabled() bool { return true }
 
-func (r *UnusualHourRule) Evaluate(transaction *models.Transaction, profile *models.UserProfile) RuleResult {
-	hour := transaction.Timestamp.Hour()
+func (r *UnusualHourRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	local, source := transaction.LocalTime()
+	hour := local.Hour()
 	
-	// Considera 23h às 5h como horário suspeito
-	triggered := hour >= 23 || hour <= 5
-	score := 0
+	start, end := 23, 5
+	if r.NightHourStart != nil && r.NightHourEnd != nil {
+		start, end = *r.NightHourStart, *r.NightHourEnd
+	}
+	
+	night := hour >= start && hour <= end
+	if start > end {
+		night = hour >= start || hour <= end
+	}
+	
+	details := map[string]interface{}{
+		"hour":            hour,
+		"local_time":      local.Format(time.RFC3339),
+		"timezone_source": source,
+		"night_window":    []int{start, end},
+	}
 	
+	triggered := night
+	if night {
+		if share, ok := r.userHourShare(profile, hour); ok {
+			details["user_hour_share"] = share
+			minShare := 0.05
+			if r.MinHourShare > 0 {
+				minShare = r.MinHourShare
+			}
+			// Horário habitual do próprio usuário
+			if share >= minShare {
+				triggered = false
+				details["usual_for_user"] = true
+			}
+		}
+	}
+	
+	score := 0
 	if triggered {
 		score = r.GetWeight()
 	}
@@ -176,35 +335,94 @@ func (r *UnusualHourRule) Evaluate(transaction *models.Transaction, profile *mod
 		Triggered:   triggered,
 		Score:       score,
 		Description: "Transação realizada em horário incomum",
-		Details: map[string]interface{}{
-			"hour": hour,
-		},
+		Details:     details,
+	}
+}
+
+// userHourShare fração das transações do usuário na hora e nas vizinhas
+func (r *UnusualHourRule) userHourShare(profile *models.UserProfile, hour int) (float64, bool) {
+	minHistory := 20
+	if r.MinHistory > 0 {
+		minHistory = r.MinHistory
+	}
+	
+	if profile == nil || profile.Baseline == nil || profile.Baseline.Count < minHistory {
+		return 0, false
 	}
+	
+	counts := profile.Baseline.HourCounts
+	observed := counts[hour] + counts[(hour+23)%24] + counts[(hour+1)%24]
+	return float64(observed) / float64(profile.Baseline.Count), true
 }
 
-// NewUserRule detecta usuários novos com transações altas
-type NewUserRule struct{}
+// NewUserRule detecta usuários novos com transações altas.
+// Para perfis com pouco histórico, compara o valor com a distribuição dos
+// grupos de pares (país, tipo de cartão, tipo de dispositivo, estabelecimento)
+// e só recorre aos limites fixos quando não há estatísticas de pares.
+type NewUserRule struct {
+	Peers               PeerStatsProvider
+	MinHistory          int
+	MinPeerObservations int
+	PeerZScoreThreshold float64
+}
 
 func (r *NewUserRule) GetID() string { return "new_user_rule" }
 func (r *NewUserRule) GetName() string { return "New User High Transaction" }
 func (r *NewUserRule) GetWeight() int { return 15 }
+func (r *NewUserRule) GetPriority() int { return PriorityLookup }
 func (r *NewUserRule) IsEnabled() bool { return true }
 
-func (r *NewUserRule) Evaluate(transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+func (r *NewUserRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
 	triggered := false
 	score := 0
+	details := map[string]interface{}{}
+	
+	minHistory := 5
+	if r.MinHistory > 0 {
+		minHistory = r.MinHistory
+	}
+	
+	accountAge := 0.0
+	if profile != nil {
+		accountAge = transaction.Timestamp.Sub(profile.FirstTransactionAt).Hours() / 24
+	}
+	thinFile := profile == nil || profile.TotalTransactions < minHistory || accountAge < 7
+	
+	if thinFile {
+		if comparisons, maxZ, ok := r.comparePeers(transaction); ok {
+			details["peer_groups"] = comparisons
+			details["max_z_score"] = maxZ
+			
+			threshold := 3.0
+			if r.PeerZScoreThreshold > 0 {
+				threshold = r.PeerZScoreThreshold
+			}
+			
+			if maxZ > threshold {
+				triggered = true
+				score = r.GetWeight()
+			}
+			
+			return RuleResult{
+				RuleID:      r.GetID(),
+				RuleName:    r.GetName(),
+				Triggered:   triggered,
+				Score:       score,
+				Description: "Novo usuário com transação atípica para seu grupo de pares",
+				Details:     details,
+			}
+		}
+	}
 	
 	if profile != nil {
 		// Se o usuário tem menos de 7 dias e faz transação alta
-		accountAge := time.Since(profile.FirstTransactionAt).Hours() / 24
-		
-		if accountAge < 7 && transaction.Amount > 5000 {
+		if accountAge < 7 && transaction.AmountInBase().Float64() > 5000 {
 			triggered = true
 			score = r.GetWeight()
 		}
 	} else {
 		// Usuário completamente novo
-		if transaction.Amount > 3000 {
+		if transaction.AmountInBase().Float64() > 3000 {
 			triggered = true
 			score = r.GetWeight()
 		}
@@ -216,8 +434,52 @@ func (r *NewUserRule) Evaluate(transaction *models.Transaction, profile *models.
 		Triggered:   triggered,
 		Score:       score,
 		Description: "Novo usuário com transação de valor elevado",
-		Details:     map[string]interface{}{},
+		Details:     details,
+	}
+}
+
+// comparePeers calcula o z-score do valor da transação em cada grupo de pares
+// com observações suficientes. Retorna false se nenhum grupo puder ser usado.
+func (r *NewUserRule) comparePeers(transaction *models.Transaction) ([]map[string]interface{}, float64, bool) {
+	if r.Peers == nil {
+		return nil, 0, false
+	}
+	
+	minObservations := 30
+	if r.MinPeerObservations > 0 {
+		minObservations = r.MinPeerObservations
+	}
+	
+	comparisons := make([]map[string]interface{}, 0)
+	maxZ := 0.0
+	
+	for _, key := range models.PeerGroupKeys(transaction) {
+		stats, err := r.Peers.GetPeerGroupStats(key)
+		if err != nil || stats == nil || stats.Count < minObservations {
+			continue
+		}
+		
+		stdDev := stats.AmountStdDev()
+		if stdDev == 0 {
+			continue
+		}
+		
+		z := (transaction.AmountInBase().Float64() - stats.AmountMean) / stdDev
+		if len(comparisons) == 0 || z > maxZ {
+			maxZ = z
+		}
+		
+		comparisons = append(comparisons, map[string]interface{}{
+			"dimension":   key.Dimension,
+			"value":       key.Value,
+			"peer_count":  stats.Count,
+			"peer_mean":   stats.AmountMean,
+			"peer_stddev": stdDev,
+			"z_score":     z,
+		})
 	}
+	
+	return comparisons, maxZ, len(comparisons) > 0
 }
 
 // RoundAmountRule detecta valores redondos suspeitos
@@ -226,16 +488,18 @@ type RoundAmountRule struct{}
 func (r *RoundAmountRule) GetID() string { return "round_amount_rule" }
 func (r *RoundAmountRule) GetName() string { return "Suspicious Round Amount" }
 func (r *RoundAmountRule) GetWeight() int { return 5 }
+func (r *RoundAmountRule) GetPriority() int { return PriorityCheap }
 func (r *RoundAmountRule) IsEnabled() bool { return true }
 
-func (r *RoundAmountRule) Evaluate(transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+func (r *RoundAmountRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
 	// Verifica se é um valor redondo e alto
 	amount := transaction.Amount
 	triggered := false
 	score := 0
 	
-	// Verifica se é múltiplo de 1000 e maior que 5000
-	if amount >= 5000 && math.Mod(amount, 1000) == 0 {
+	// Verifica se é múltiplo de 1000 e maior que 5000, em unidades menores
+	// para não depender da precisão de float
+	if amount.Minor >= 5000*amount.MinorPerUnit() && amount.IsMultipleOf(1000) {
 		triggered = true
 		score = r.GetWeight()
 	}
@@ -258,9 +522,10 @@ type MultipleFailedAttemptsRule struct{}
 func (r *MultipleFailedAttemptsRule) GetID() string { return "multiple_failed_attempts_rule" }
 func (r *MultipleFailedAttemptsRule) GetName() string { return "Multiple Failed Attempts" }
 func (r *MultipleFailedAttemptsRule) GetWeight() int { return 25 }
+func (r *MultipleFailedAttemptsRule) GetPriority() int { return PriorityStandard }
 func (r *MultipleFailedAttemptsRule) IsEnabled() bool { return true }
 
-func (r *MultipleFailedAttemptsRule) Evaluate(transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+func (r *MultipleFailedAttemptsRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
 	// Esta regra seria implementada com histórico de tentativas
 	// Por agora, retorna não-triggered
 	return RuleResult{
@@ -0,0 +1,76 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+func hourPtr(hour int) *int { return &hour }
+
+func TestUnusualHourWindow(t *testing.T) {
+	tests := []struct {
+		name       string
+		start, end *int
+		hour       int
+		triggered  bool
+	}{
+		{"default window", nil, nil, 23, true},
+		{"default window ends at 5h", nil, nil, 6, false},
+		{"single hour window", hourPtr(3), hourPtr(3), 3, true},
+		{"outside the single hour", hourPtr(3), hourPtr(3), 23, false},
+		{"window starting at midnight", hourPtr(0), hourPtr(4), 0, true},
+		{"window across midnight", hourPtr(22), hourPtr(2), 1, true},
+		{"after the window across midnight", hourPtr(22), hourPtr(2), 3, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := testTransaction()
+			transaction.Timestamp = time.Date(2026, 3, 10, tt.hour, 30, 0, 0, time.UTC)
+			transaction.Location = models.Location{Country: "GB"}
+
+			rule := &UnusualHourRule{NightHourStart: tt.start, NightHourEnd: tt.end}
+			result := rule.Evaluate(context.Background(), transaction, nil)
+			if result.Triggered != tt.triggered {
+				t.Errorf("%dh: triggered = %v, want %v (window %v)", tt.hour, result.Triggered, tt.triggered, result.Details["night_window"])
+			}
+		})
+	}
+}
+
+func TestNightWindowConfigErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		alerts  string
+		message string
+	}{
+		{"only start", `{"night_hour_start": 3}`, "must be set together"},
+		{"only end", `{"night_hour_end": 4}`, "must be set together"},
+		{"hour out of range", `{"night_hour_start": 22, "night_hour_end": 24}`, "between 0 and 23"},
+		{"single hour", `{"night_hour_start": 3, "night_hour_end": 3}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var config EngineConfig
+			if err := json.Unmarshal([]byte(`{"alerts": `+tt.alerts+`}`), &config); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			err := config.Validate()
+			if tt.message == "" {
+				if err != nil {
+					t.Errorf("Validate: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("error = %v, want %q", err, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package rules
+
+import (
+	"context"
+	"math"
+
+	"github.com/anti-fraud-golang/internal/ipintel"
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// IPLocationMismatchRule detecta distância grande entre a localização
+// derivada do IP e a localização declarada pelo cliente. A divergência de
+// país é tratada pela CountryMismatchRule.
+type IPLocationMismatchRule struct {
+	Weight        int
+	MaxDistanceKm float64
+}
+
+func (r *IPLocationMismatchRule) GetID() string   { return "ip_location_mismatch_rule" }
+func (r *IPLocationMismatchRule) GetName() string { return "IP Location Mismatch" }
+func (r *IPLocationMismatchRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 20
+	}
+	return r.Weight
+}
+func (r *IPLocationMismatchRule) GetPriority() int { return PriorityCheap }
+func (r *IPLocationMismatchRule) IsEnabled() bool  { return true }
+
+func (r *IPLocationMismatchRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	maxDistance := 500.0
+	if r.MaxDistanceKm > 0 {
+		maxDistance = r.MaxDistanceKm
+	}
+
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Localização do IP distante da localização declarada",
+		Details:     map[string]interface{}{},
+	}
+
+	ipLocation := transaction.IPGeolocation
+	declared := transaction.Location
+	if !ipLocation.HasCoordinates() || (declared.Latitude == 0 && declared.Longitude == 0) {
+		return result
+	}
+
+	distance := calculateDistance(
+		ipLocation.Latitude, ipLocation.Longitude,
+		declared.Latitude, declared.Longitude,
+	)
+
+	result.Details["ip_country"] = ipLocation.Country
+	result.Details["ip_city"] = ipLocation.City
+	result.Details["declared_country"] = declared.Country
+	result.Details["declared_city"] = declared.City
+	result.Details["distance_km"] = math.Round(distance)
+	result.Details["max_distance_km"] = maxDistance
+	if ipLocation.ASN != 0 {
+		result.Details["asn"] = ipLocation.ASN
+	}
+
+	if distance > maxDistance {
+		result.Triggered = true
+		// Score cresce com a distância: de cidade vizinha a outro continente
+		if distance > 4*maxDistance {
+			result.Score = r.GetWeight()
+		} else {
+			result.Score = int(float64(r.GetWeight()) * 0.6)
+		}
+	}
+
+	return result
+}
+
+// anonymizerScores fração do peso aplicada por categoria de anonimizador;
+// Tor e proxies abertos raramente têm uso legítimo em pagamentos
+var anonymizerScores = map[string]float64{
+	ipintel.CategoryTor:     1.0,
+	ipintel.CategoryProxy:   0.8,
+	ipintel.CategoryVPN:     0.5,
+	ipintel.CategoryHosting: 0.4,
+}
+
+// AnonymizedIPRule detecta conexões vindas de Tor, proxies abertos, VPNs e
+// provedores de hospedagem
+type AnonymizedIPRule struct {
+	Weight int
+}
+
+func (r *AnonymizedIPRule) GetID() string   { return "anonymized_ip_rule" }
+func (r *AnonymizedIPRule) GetName() string { return "Anonymized Connection" }
+func (r *AnonymizedIPRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 40
+	}
+	return r.Weight
+}
+func (r *AnonymizedIPRule) GetPriority() int { return PriorityCheap }
+func (r *AnonymizedIPRule) IsEnabled() bool  { return true }
+
+func (r *AnonymizedIPRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Conexão por anonimizador (Tor, proxy, VPN ou hospedagem)",
+		Details:     map[string]interface{}{},
+	}
+
+	reputation := transaction.IPReputation
+	if reputation == nil || len(reputation.Categories) == 0 {
+		return result
+	}
+
+	// A categoria mais grave define o score
+	category, share := "", 0.0
+	for _, c := range reputation.Categories {
+		if anonymizerScores[c] > share {
+			category, share = c, anonymizerScores[c]
+		}
+	}
+	if category == "" {
+		return result
+	}
+
+	result.Triggered = true
+	result.Score = int(math.Round(float64(r.GetWeight()) * share))
+	result.Details["category"] = category
+	result.Details["categories"] = reputation.Categories
+	result.Details["ip"] = transaction.Location.IPAddress
+	if transaction.IPGeolocation != nil && transaction.IPGeolocation.ASN != 0 {
+		result.Details["asn"] = transaction.IPGeolocation.ASN
+	}
+
+	return result
+}
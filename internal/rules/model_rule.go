@@ -0,0 +1,55 @@
+package rules
+
+import (
+	"context"
+
+	"github.com/anti-fraud-golang/internal/ml"
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// ModelRule pontua transações com o modelo treinado pelo cmd/train
+type ModelRule struct {
+	Model     *ml.Model
+	Weight    int
+	Threshold float64
+}
+
+func (r *ModelRule) GetID() string   { return "model_rule" }
+func (r *ModelRule) GetName() string { return "Fraud Model Score" }
+func (r *ModelRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 40
+	}
+	return r.Weight
+}
+func (r *ModelRule) GetPriority() int { return PriorityExpensive }
+func (r *ModelRule) IsEnabled() bool  { return r.Model != nil }
+
+func (r *ModelRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	threshold := 0.5
+	if r.Threshold > 0 {
+		threshold = r.Threshold
+	}
+
+	probability := r.Model.Score(transaction, profile)
+	triggered := probability >= threshold
+	score := 0
+
+	if triggered {
+		// Score proporcional à probabilidade estimada
+		score = int(float64(r.GetWeight()) * probability)
+	}
+
+	return RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Triggered:   triggered,
+		Score:       score,
+		Description: "Modelo estatístico indica alta probabilidade de fraude",
+		Details: map[string]interface{}{
+			"probability":   probability,
+			"threshold":     threshold,
+			"model_version": r.Model.Version,
+		},
+	}
+}
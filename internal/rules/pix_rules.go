@@ -0,0 +1,236 @@
+package rules
+
+import (
+	"context"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// PIXLimitRuleID regra de limite PIX, sempre vetada com BLOCKED: o limite é
+// regulatório e não pode ser compensado por um score baixo
+const PIXLimitRuleID = "pix_limit_rule"
+
+// PIXLimitRule aplica os limites PIX diurno e noturno do usuário ao total
+// transferido no período, incluindo a transferência em análise
+type PIXLimitRule struct {
+	Limits PIXLimitProvider
+	Usage  PIXUsageProvider
+	Weight int
+}
+
+func (r *PIXLimitRule) GetID() string   { return PIXLimitRuleID }
+func (r *PIXLimitRule) GetName() string { return "PIX Limit Exceeded" }
+func (r *PIXLimitRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 100
+	}
+	return r.Weight
+}
+func (r *PIXLimitRule) GetPriority() int { return PriorityLookup }
+func (r *PIXLimitRule) IsEnabled() bool  { return true }
+
+func (r *PIXLimitRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Transferência PIX acima do limite do período",
+		Details:     map[string]interface{}{},
+	}
+
+	if transaction.PIX == nil {
+		return result
+	}
+
+	limits := models.DefaultPIXLimits(transaction.UserID)
+	if r.Limits != nil {
+		configured, err := r.Limits.GetPIXLimits(ctx, transaction.UserID)
+		if err != nil {
+			result.Details["error"] = err.Error()
+			return result
+		}
+		if configured != nil {
+			limits = *configured
+		}
+	}
+	from, to, night, limit := limits.Window(transaction)
+
+	used := models.NewMoney(0, transaction.Amount.Currency)
+	if r.Usage != nil {
+		usage, err := r.Usage.GetPIXUsage(ctx, transaction.UserID, from, to)
+		if err != nil {
+			result.Details["error"] = err.Error()
+			return result
+		}
+		used.Minor = usage.Total.Minor
+	}
+
+	period := "day"
+	if night {
+		period = "night"
+	}
+	result.Details["period"] = period
+	result.Details["period_start"] = from
+	result.Details["period_end"] = to
+	result.Details["limit"] = limit
+	result.Details["used"] = used
+	result.Details["amount"] = transaction.Amount
+
+	// Limite zero é ausência de limite no período
+	if limit.Minor > 0 && used.Minor+transaction.Amount.Minor > limit.Minor {
+		result.Triggered = true
+		result.Score = r.GetWeight()
+	}
+
+	return result
+}
+
+// PIXNewKeyRule detecta a primeira transferência do usuário para uma chave
+// que nunca recebeu dele uma transferência aprovada
+type PIXNewKeyRule struct {
+	Weight     int
+	MinHistory int
+}
+
+func (r *PIXNewKeyRule) GetID() string   { return "pix_new_key_rule" }
+func (r *PIXNewKeyRule) GetName() string { return "First PIX Transfer to Key" }
+func (r *PIXNewKeyRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 15
+	}
+	return r.Weight
+}
+func (r *PIXNewKeyRule) GetPriority() int { return PriorityCheap }
+func (r *PIXNewKeyRule) IsEnabled() bool  { return true }
+
+func (r *PIXNewKeyRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	minHistory := 3
+	if r.MinHistory > 0 {
+		minHistory = r.MinHistory
+	}
+
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Primeira transferência PIX para a chave",
+		Details:     map[string]interface{}{},
+	}
+
+	// Sem histórico toda chave é nova; a regra de usuário novo cobre esse caso
+	if transaction.PIX == nil || profile == nil || profile.TotalTransactions < minHistory {
+		return result
+	}
+
+	key := transaction.PIX.KeyID()
+	for _, known := range profile.KnownPIXKeys {
+		if known == key {
+			return result
+		}
+	}
+
+	result.Triggered = true
+	result.Score = r.GetWeight()
+	result.Details["key_type"] = transaction.PIX.KeyType
+	result.Details["known_keys"] = len(profile.KnownPIXKeys)
+	return result
+}
+
+// PIXRecentKeyRule detecta transferências para chaves registradas há pouco
+// no DICT, comuns em golpes com contas abertas para receber o dinheiro
+type PIXRecentKeyRule struct {
+	Weight    int
+	MaxKeyAge time.Duration
+}
+
+func (r *PIXRecentKeyRule) GetID() string   { return "pix_recent_key_rule" }
+func (r *PIXRecentKeyRule) GetName() string { return "Recently Registered PIX Key" }
+func (r *PIXRecentKeyRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 25
+	}
+	return r.Weight
+}
+func (r *PIXRecentKeyRule) GetPriority() int { return PriorityCheap }
+func (r *PIXRecentKeyRule) IsEnabled() bool  { return true }
+
+func (r *PIXRecentKeyRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	maxAge := 7 * 24 * time.Hour
+	if r.MaxKeyAge > 0 {
+		maxAge = r.MaxKeyAge
+	}
+
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Transferência PIX para chave registrada recentemente",
+		Details:     map[string]interface{}{},
+	}
+
+	if transaction.PIX == nil || transaction.PIX.KeyCreatedAt == nil {
+		return result
+	}
+
+	age := transaction.Timestamp.Sub(*transaction.PIX.KeyCreatedAt)
+	if age >= maxAge {
+		return result
+	}
+
+	// Chave do último dia pesa inteira; até o limite de idade, metade
+	result.Triggered = true
+	result.Details["key_age_hours"] = int(age.Hours())
+	result.Details["max_key_age_hours"] = int(maxAge.Hours())
+	if age < 24*time.Hour {
+		result.Score = r.GetWeight()
+	} else {
+		result.Score = r.GetWeight() / 2
+	}
+	return result
+}
+
+// PIXMuleAccountRule detecta transferências para chaves ou contas marcadas
+// como laranjas
+type PIXMuleAccountRule struct {
+	Mules  MuleAccountProvider
+	Weight int
+}
+
+func (r *PIXMuleAccountRule) GetID() string   { return "pix_mule_account_rule" }
+func (r *PIXMuleAccountRule) GetName() string { return "PIX Mule Account" }
+func (r *PIXMuleAccountRule) GetWeight() int {
+	if r.Weight == 0 {
+		return 60
+	}
+	return r.Weight
+}
+func (r *PIXMuleAccountRule) GetPriority() int { return PriorityLookup }
+func (r *PIXMuleAccountRule) IsEnabled() bool  { return true }
+
+func (r *PIXMuleAccountRule) Evaluate(ctx context.Context, transaction *models.Transaction, profile *models.UserProfile) RuleResult {
+	result := RuleResult{
+		RuleID:      r.GetID(),
+		RuleName:    r.GetName(),
+		Description: "Recebedor PIX marcado como conta laranja",
+		Details:     map[string]interface{}{},
+	}
+
+	if r.Mules == nil || transaction.PIX == nil {
+		return result
+	}
+
+	flag, err := r.Mules.GetMuleFlag(ctx, transaction.PIX)
+	if err != nil {
+		result.Details["error"] = err.Error()
+		return result
+	}
+	if flag == nil {
+		return result
+	}
+
+	result.Triggered = true
+	result.Score = r.GetWeight()
+	result.Details["flag_id"] = flag.ID
+	result.Details["flag_type"] = flag.Type
+	result.Details["reason"] = flag.Reason
+	result.Details["flagged_at"] = flag.FlaggedAt
+	return result
+}
@@ -0,0 +1,95 @@
+package rules
+
+import (
+	"math"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// TravelAwareRule regra geográfica atenuada quando a transação corresponde a
+// um aviso de viagem ativo do usuário
+type TravelAwareRule interface {
+	// TravelNoticeFactor multiplica o score da regra; zero suprime a regra
+	TravelNoticeFactor() float64
+}
+
+// TravelAdjustment score de uma regra atenuado por aviso de viagem
+type TravelAdjustment struct {
+	RuleID        string `json:"rule_id"`
+	OriginalScore int    `json:"original_score"`
+	Score         int    `json:"score"`
+	Suppressed    bool   `json:"suppressed"`
+}
+
+// Fatores aplicados pelas regras geográficas. Viagem avisada explica país novo
+// e divergência de país, mas não torna possível um deslocamento impossível nem
+// elimina o risco de um país da lista de alto risco.
+func (r *GeoVelocityRule) TravelNoticeFactor() float64     { return 0.5 }
+func (r *HighRiskCountryRule) TravelNoticeFactor() float64 { return 0.5 }
+func (r *CountryMismatchRule) TravelNoticeFactor() float64 { return 0 }
+func (r *NewCountryRule) TravelNoticeFactor() float64      { return 0 }
+
+// MatchTravelNotice retorna o aviso que cobre a transação: período que inclui
+// o horário do evento, país declarado ou do IP entre os países do aviso e,
+// se o aviso listar cidades, a cidade declarada entre elas; nil se nenhum cobrir
+func MatchTravelNotice(notices []models.TravelNotice, transaction *models.Transaction) *models.TravelNotice {
+	declared := normalizeCountry(transaction.Location.Country)
+	ipCountry := normalizeCountry(transaction.IPCountry)
+	city := normalizeCity(transaction.Location.City)
+
+	for i := range notices {
+		notice := &notices[i]
+		if !notice.Covers(transaction.Timestamp) {
+			continue
+		}
+
+		countryMatch := false
+		for _, country := range notice.Countries {
+			country = normalizeCountry(country)
+			if country != "" && (country == declared || country == ipCountry) {
+				countryMatch = true
+				break
+			}
+		}
+		if !countryMatch {
+			continue
+		}
+
+		if len(notice.Cities) == 0 {
+			return notice
+		}
+		for _, noticeCity := range notice.Cities {
+			if city != "" && normalizeCity(noticeCity) == city {
+				return notice
+			}
+		}
+	}
+	return nil
+}
+
+// applyTravelNotice atenua o resultado de uma regra geográfica acionada em
+// transação coberta por aviso de viagem. Uma regra suprimida deixa de contar
+// como acionada, inclusive para as regras compostas.
+func applyTravelNotice(rule FraudRule, result *RuleResult, transaction *models.Transaction) *TravelAdjustment {
+	aware, ok := rule.(TravelAwareRule)
+	if !ok || transaction.TravelNotice == nil || !result.Triggered {
+		return nil
+	}
+
+	adjustment := &TravelAdjustment{
+		RuleID:        result.RuleID,
+		OriginalScore: result.Score,
+		Score:         int(math.Round(float64(result.Score) * aware.TravelNoticeFactor())),
+	}
+	adjustment.Suppressed = adjustment.Score == 0
+
+	result.Score = adjustment.Score
+	result.Triggered = !adjustment.Suppressed
+	if result.Details == nil {
+		result.Details = map[string]interface{}{}
+	}
+	result.Details["travel_notice"] = transaction.TravelNotice.ID
+	result.Details["original_score"] = adjustment.OriginalScore
+
+	return adjustment
+}
@@ -0,0 +1,152 @@
+package scenarios
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+	"github.com/anti-fraud-golang/internal/rules"
+	"github.com/anti-fraud-golang/internal/services"
+)
+
+// Scenario exemplo de transação com o resultado esperado do motor de regras
+type Scenario struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Transaction models.Transaction   `json:"transaction"`
+	Profile     *models.UserProfile  `json:"profile,omitempty"`
+	History     []models.Transaction `json:"history,omitempty"`
+	Expect      Expectation          `json:"expect"`
+}
+
+// Expectation resultado esperado de um cenário. Campos vazios não são verificados.
+type Expectation struct {
+	Triggered    []string        `json:"triggered,omitempty"`
+	NotTriggered []string        `json:"not_triggered,omitempty"`
+	Decision     models.Decision `json:"decision,omitempty"`
+}
+
+// Outcome resultado da execução de um cenário
+type Outcome struct {
+	File       string          `json:"file"`
+	Scenario   string          `json:"scenario"`
+	Passed     bool            `json:"passed"`
+	Mismatches []string        `json:"mismatches,omitempty"`
+	Decision   models.Decision `json:"decision"`
+	RiskScore  int             `json:"risk_score"`
+	Triggered  []string        `json:"triggered"`
+}
+
+// LoadFile lê os cenários de um arquivo JSON (um cenário ou uma lista)
+func LoadFile(path string) ([]Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var scenarios []Scenario
+		if err := json.Unmarshal(data, &scenarios); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return scenarios, nil
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return []Scenario{scenario}, nil
+}
+
+// Run executa um cenário contra a configuração de regras informada.
+// Cada cenário roda em um serviço isolado: o histórico é analisado primeiro
+// (alimentando velocidade e estatísticas) e o perfil, se informado, substitui
+// o perfil resultante antes da transação testada.
+func Run(ctx context.Context, config rules.EngineConfig, scenario Scenario) (*Outcome, error) {
+	profileStore := services.NewInMemoryProfileStore()
+	service := services.NewFraudDetectionService(profileStore, services.NewInMemoryBlacklistStore())
+
+	if _, err := service.ApplyRuleConfig(config, services.RuleChange{Author: "scenarios"}); err != nil {
+		return nil, err
+	}
+
+	// Cenários são reprocessados: cada transação é recebida no próprio horário
+	var receivedAt time.Time
+	service.SetClock(services.ClockFunc(func() time.Time { return receivedAt }))
+	replayAt := func(transaction *models.Transaction) {
+		receivedAt = transaction.Timestamp
+		if receivedAt.IsZero() {
+			receivedAt = time.Now()
+		}
+	}
+
+	for i := range scenario.History {
+		past := scenario.History[i]
+		replayAt(&past)
+		if _, err := service.AnalyzeTransaction(ctx, &past); err != nil {
+			return nil, fmt.Errorf("history %d: %w", i, err)
+		}
+	}
+
+	if scenario.Profile != nil {
+		profile := *scenario.Profile
+		if profile.UserID == "" {
+			profile.UserID = scenario.Transaction.UserID
+		}
+		if err := profileStore.UpdateUserProfile(&profile); err != nil {
+			return nil, err
+		}
+	}
+
+	transaction := scenario.Transaction
+	replayAt(&transaction)
+	result, err := service.AnalyzeTransaction(ctx, &transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	outcome := &Outcome{
+		Scenario:  scenario.Name,
+		Decision:  result.Decision,
+		RiskScore: result.RiskScore,
+		Triggered: result.TriggeredRuleIDs,
+	}
+	outcome.Mismatches = compare(scenario.Expect, result)
+	outcome.Passed = len(outcome.Mismatches) == 0
+
+	return outcome, nil
+}
+
+// compare lista as divergências entre o esperado e o resultado obtido
+func compare(expect Expectation, result *models.FraudAnalysisResult) []string {
+	triggered := make(map[string]bool, len(result.TriggeredRuleIDs))
+	for _, id := range result.TriggeredRuleIDs {
+		triggered[id] = true
+	}
+
+	mismatches := make([]string, 0)
+	for _, id := range expect.Triggered {
+		if !triggered[id] {
+			mismatches = append(mismatches, fmt.Sprintf("expected rule %s to trigger", id))
+		}
+	}
+	for _, id := range expect.NotTriggered {
+		if triggered[id] {
+			mismatches = append(mismatches, fmt.Sprintf("expected rule %s not to trigger", id))
+		}
+	}
+	if expect.Decision != "" && expect.Decision != result.Decision {
+		mismatches = append(mismatches, fmt.Sprintf("expected decision %s, got %s (score %d)",
+			expect.Decision, result.Decision, result.RiskScore))
+	}
+
+	sort.Strings(mismatches)
+	return mismatches
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anti-fraud-golang/internal/cardintel"
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// ErrInvalidCard dados do cartão fora do formato aceito
+var ErrInvalidCard = errors.New("invalid card data")
+
+// BINLookup resolve o BIN do cartão para os dados do emissor
+type BINLookup interface {
+	Lookup(bin string) (*models.CardIssuer, bool)
+}
+
+// SetBINTable passa a enriquecer as transações com os dados do emissor do
+// cartão antes das regras; nil desativa o enriquecimento
+func (s *FraudDetectionService) SetBINTable(table BINLookup) {
+	s.enrichMu.Lock()
+	defer s.enrichMu.Unlock()
+	s.binTable = table
+}
+
+// enrichCard preenche o emissor do cartão. O país da tabela prevalece sobre
+// o informado pelo cliente.
+func (s *FraudDetectionService) enrichCard(transaction *models.Transaction) {
+	s.enrichMu.RLock()
+	table := s.binTable
+	s.enrichMu.RUnlock()
+
+	if table == nil || transaction.CardBIN == "" {
+		return
+	}
+
+	issuer, found := table.Lookup(transaction.CardBIN)
+	if !found {
+		return
+	}
+	transaction.CardIssuer = issuer
+	transaction.CardCountry = issuer.Country
+	if transaction.CardType == "" {
+		transaction.CardType = issuer.ProductType
+	}
+}
+
+// validateCard garante que nenhum número de cartão completo entre no serviço:
+// a impressão digital precisa ser um identificador opaco
+func validateCard(transaction *models.Transaction) error {
+	if transaction.CardFingerprint != "" && !cardintel.ValidFingerprint(transaction.CardFingerprint) {
+		return fmt.Errorf("%w: card_fingerprint must be a network token or keyed hash, never the card number", ErrInvalidCard)
+	}
+	if transaction.CardLast4 != "" && len(transaction.CardLast4) > 4 {
+		return fmt.Errorf("%w: card_last4 must have at most 4 digits", ErrInvalidCard)
+	}
+	return nil
+}
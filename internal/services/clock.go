@@ -0,0 +1,103 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// Clock fonte de tempo do serviço, substituível em testes e reprocessamentos
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock relógio do sistema
+type SystemClock struct{}
+
+// Now retorna o horário atual do sistema
+func (SystemClock) Now() time.Time { return time.Now() }
+
+// ClockFunc adapta uma função ao Clock
+type ClockFunc func() time.Time
+
+// Now retorna o horário informado pela função
+func (f ClockFunc) Now() time.Time { return f() }
+
+// ErrInvalidTimestamp timestamp do evento fora da janela aceita
+var ErrInvalidTimestamp = errors.New("invalid transaction timestamp")
+
+// TimestampPolicy janela aceita para o horário do evento informado pelo
+// cliente, relativa ao horário de recebimento
+type TimestampPolicy struct {
+	// MaxFutureSkew tolerância para relógios de clientes adiantados
+	MaxFutureSkew time.Duration
+	// MaxEventAge idade máxima de eventos atrasados, em lote ou reprocessados
+	MaxEventAge time.Duration
+}
+
+// DefaultTimestampPolicy aceita eventos até 5 minutos no futuro e 72 horas no passado
+func DefaultTimestampPolicy() TimestampPolicy {
+	return TimestampPolicy{
+		MaxFutureSkew: 5 * time.Minute,
+		MaxEventAge:   72 * time.Hour,
+	}
+}
+
+// Validate verifica o horário do evento em relação ao recebimento
+func (p TimestampPolicy) Validate(eventTime, receivedAt time.Time) error {
+	skew := eventTime.Sub(receivedAt)
+	if skew > p.MaxFutureSkew {
+		return fmt.Errorf("%w: %s in the future (max %s)", ErrInvalidTimestamp, skew.Round(time.Second), p.MaxFutureSkew)
+	}
+	if -skew > p.MaxEventAge {
+		return fmt.Errorf("%w: %s old (max %s)", ErrInvalidTimestamp, (-skew).Round(time.Second), p.MaxEventAge)
+	}
+	return nil
+}
+
+// SetClock substitui a fonte de tempo do serviço
+func (s *FraudDetectionService) SetClock(clock Clock) {
+	s.timeMu.Lock()
+	defer s.timeMu.Unlock()
+	s.clock = clock
+}
+
+// SetTimestampPolicy define a janela aceita para o horário dos eventos
+func (s *FraudDetectionService) SetTimestampPolicy(policy TimestampPolicy) error {
+	if policy.MaxFutureSkew < 0 || policy.MaxEventAge < 0 {
+		return fmt.Errorf("timestamp policy: windows must not be negative")
+	}
+
+	s.timeMu.Lock()
+	defer s.timeMu.Unlock()
+	s.timestampPolicy = policy
+	return nil
+}
+
+// now retorna o horário atual segundo o relógio do serviço
+func (s *FraudDetectionService) now() time.Time {
+	s.timeMu.RLock()
+	defer s.timeMu.RUnlock()
+	return s.clock.Now()
+}
+
+// eventTime define os horários de evento e de recebimento da transação.
+// Sem timestamp do cliente, o evento é considerado ocorrido no recebimento.
+func (s *FraudDetectionService) eventTime(transaction *models.Transaction) error {
+	s.timeMu.RLock()
+	receivedAt := s.clock.Now()
+	policy := s.timestampPolicy
+	s.timeMu.RUnlock()
+
+	if transaction.ReceivedAt.IsZero() {
+		transaction.ReceivedAt = receivedAt
+	}
+	if transaction.Timestamp.IsZero() {
+		transaction.Timestamp = transaction.ReceivedAt
+		return nil
+	}
+
+	return policy.Validate(transaction.Timestamp, transaction.ReceivedAt)
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+	"github.com/anti-fraud-golang/internal/resilience"
+	"github.com/anti-fraud-golang/internal/rules"
+)
+
+// Dependências externas com política de degradação própria
+const (
+	DependencyBlacklist = "blacklist"
+	DependencyProfile   = "profile"
+	DependencyVelocity  = "velocity"
+	DependencyPeerGroup = "peer_group"
+	DependencyTravel    = "travel"
+	DependencyPIXLimits = "pix_limits"
+	DependencyPIXUsage  = "pix_usage"
+	DependencyMules     = "pix_mules"
+	DependencyModel     = "model"
+)
+
+// Modos de degradação quando uma dependência falha
+const (
+	// FailOpen segue a análise sem o dado da dependência
+	FailOpen = "fail_open"
+	// FailClosed segue a análise, mas a decisão final é no mínimo FallbackDecision
+	FailClosed = "fail_closed"
+)
+
+// DependencyPolicy política de degradação, retry e circuit breaker de uma dependência
+type DependencyPolicy struct {
+	Mode             string          `json:"mode"`
+	FallbackDecision models.Decision `json:"fallback_decision,omitempty"`
+	MaxRetries       int             `json:"max_retries"`
+	BackoffMs        int             `json:"backoff_ms"`
+	MaxBackoffMs     int             `json:"max_backoff_ms,omitempty"`
+	BreakerThreshold int             `json:"breaker_threshold"`
+	BreakerCooldownS int             `json:"breaker_cooldown_s"`
+}
+
+// DegradationConfig políticas de degradação por dependência
+type DegradationConfig struct {
+	Blacklist DependencyPolicy `json:"blacklist"`
+	Profile   DependencyPolicy `json:"profile"`
+	Velocity  DependencyPolicy `json:"velocity"`
+	PeerGroup DependencyPolicy `json:"peer_group"`
+	Travel    DependencyPolicy `json:"travel"`
+	PIXLimits DependencyPolicy `json:"pix_limits"`
+	PIXUsage  DependencyPolicy `json:"pix_usage"`
+	Mules     DependencyPolicy `json:"pix_mules"`
+	Model     DependencyPolicy `json:"model"`
+}
+
+// DefaultDegradationConfig sem lista negra ou marcações de laranjas a
+// transação vai para revisão; as demais dependências apenas marcam a análise
+// como degradada (sem limites ou total PIX, a análise já manda para revisão)
+func DefaultDegradationConfig() DegradationConfig {
+	policy := DependencyPolicy{
+		Mode:             FailOpen,
+		MaxRetries:       2,
+		BackoffMs:        10,
+		MaxBackoffMs:     50,
+		BreakerThreshold: 5,
+		BreakerCooldownS: 30,
+	}
+
+	blacklist := policy
+	blacklist.Mode = FailClosed
+	blacklist.FallbackDecision = models.DecisionReview
+
+	return DegradationConfig{
+		Blacklist: blacklist,
+		Profile:   policy,
+		Velocity:  policy,
+		PeerGroup: policy,
+		Travel:    policy,
+		PIXLimits: policy,
+		PIXUsage:  policy,
+		Mules:     blacklist,
+		Model:     policy,
+	}
+}
+
+// LoadDegradationConfig carrega as políticas de um arquivo JSON; dependências
+// omitidas mantêm a política padrão
+func LoadDegradationConfig(path string) (*DegradationConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	config := DefaultDegradationConfig()
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("invalid degradation config %s: %w", path, err)
+	}
+
+	return &config, nil
+}
+
+// Validate verifica a consistência das políticas
+func (c *DegradationConfig) Validate() error {
+	for name, policy := range c.policies() {
+		switch policy.Mode {
+		case FailOpen:
+		case FailClosed:
+			switch policy.FallbackDecision {
+			case models.DecisionReview, models.DecisionBlocked:
+			default:
+				return fmt.Errorf("%s: fail_closed requires fallback_decision REVIEW or BLOCKED", name)
+			}
+		default:
+			return fmt.Errorf("%s: invalid mode %q", name, policy.Mode)
+		}
+		if policy.MaxRetries < 0 || policy.BackoffMs < 0 || policy.MaxBackoffMs < 0 {
+			return fmt.Errorf("%s: retries and backoff must not be negative", name)
+		}
+		if policy.BreakerThreshold <= 0 || policy.BreakerCooldownS <= 0 {
+			return fmt.Errorf("%s: breaker threshold and cooldown must be positive", name)
+		}
+	}
+	return nil
+}
+
+func (c *DegradationConfig) policies() map[string]DependencyPolicy {
+	return map[string]DependencyPolicy{
+		DependencyBlacklist: c.Blacklist,
+		DependencyProfile:   c.Profile,
+		DependencyVelocity:  c.Velocity,
+		DependencyPeerGroup: c.PeerGroup,
+		DependencyTravel:    c.Travel,
+		DependencyPIXLimits: c.PIXLimits,
+		DependencyPIXUsage:  c.PIXUsage,
+		DependencyMules:     c.Mules,
+		DependencyModel:     c.Model,
+	}
+}
+
+// dependencyGuard aplica retry e circuit breaker às chamadas de uma dependência
+type dependencyGuard struct {
+	name    string
+	policy  DependencyPolicy
+	breaker *resilience.CircuitBreaker
+}
+
+func newDependencyGuards(config DegradationConfig) map[string]*dependencyGuard {
+	guards := make(map[string]*dependencyGuard)
+	for name, policy := range config.policies() {
+		guards[name] = &dependencyGuard{
+			name:    name,
+			policy:  policy,
+			breaker: resilience.NewCircuitBreaker(policy.BreakerThreshold, time.Duration(policy.BreakerCooldownS)*time.Second),
+		}
+	}
+	return guards
+}
+
+// call executa a chamada com retry das falhas transitórias. A chamada, com
+// todas as suas tentativas, conta uma única vez no circuit breaker.
+func (g *dependencyGuard) call(ctx context.Context, call func() error) error {
+	retry := resilience.RetryPolicy{
+		MaxRetries:     g.policy.MaxRetries,
+		InitialBackoff: time.Duration(g.policy.BackoffMs) * time.Millisecond,
+		MaxBackoff:     time.Duration(g.policy.MaxBackoffMs) * time.Millisecond,
+	}
+	return g.breaker.Execute(func() error {
+		return resilience.Retry(ctx, retry, func() error {
+			return classifyStoreError(call())
+		})
+	})
+}
+
+// classifyStoreError marca como permanentes os erros de domínio dos stores:
+// a dependência respondeu, e repetir a chamada não muda a resposta
+func classifyStoreError(err error) error {
+	for _, permanent := range []error{
+		ErrProfileNotFound, ErrInvalidBlacklistEntry, ErrTravelNoticeNotFound,
+		ErrMuleFlagNotFound, ErrVersionNotFound,
+	} {
+		if errors.Is(err, permanent) {
+			return resilience.Permanent(err)
+		}
+	}
+	return err
+}
+
+// degradation registra as dependências que falharam durante uma análise
+type degradation struct {
+	mu       sync.Mutex
+	reasons  []string
+	failed   map[string]bool
+	fallback models.Decision
+}
+
+func newDegradation() *degradation {
+	return &degradation{
+		reasons:  make([]string, 0),
+		failed:   make(map[string]bool),
+		fallback: models.DecisionApproved,
+	}
+}
+
+// record marca a dependência como indisponível e aplica o modo da política
+func (d *degradation) record(guard *dependencyGuard, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.failed[guard.name] {
+		return
+	}
+	d.failed[guard.name] = true
+	d.reasons = append(d.reasons, fmt.Sprintf("%s indisponível (%s): %v", guard.name, guard.policy.Mode, err))
+
+	if guard.policy.Mode == FailClosed {
+		d.fallback = rules.StricterDecision(d.fallback, guard.policy.FallbackDecision)
+	}
+}
+
+func (d *degradation) hasFailed(dependency string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.failed[dependency]
+}
+
+// summary retorna os motivos registrados e a decisão mínima exigida.
+// Regras abandonadas por timeout podem registrar falhas depois, por isso a cópia.
+func (d *degradation) summary() ([]string, models.Decision) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.reasons...), d.fallback
+}
+
+type degradationKey struct{}
+
+// withDegradation associa o registro de degradação ao contexto da análise
+func withDegradation(ctx context.Context, d *degradation) context.Context {
+	return context.WithValue(ctx, degradationKey{}, d)
+}
+
+func degradationFrom(ctx context.Context) *degradation {
+	d, _ := ctx.Value(degradationKey{}).(*degradation)
+	return d
+}
+
+// SetDegradationConfig troca as políticas de degradação; os circuit breakers
+// recomeçam fechados
+func (s *FraudDetectionService) SetDegradationConfig(config DegradationConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	guards := newDependencyGuards(config)
+
+	s.guardsMu.Lock()
+	defer s.guardsMu.Unlock()
+	s.guards = guards
+	return nil
+}
+
+// guard retorna a proteção de uma dependência
+func (s *FraudDetectionService) guard(dependency string) *dependencyGuard {
+	s.guardsMu.RLock()
+	defer s.guardsMu.RUnlock()
+	return s.guards[dependency]
+}
+
+// guardedVelocity expõe o VelocityStore às regras com retry e circuit breaker,
+// registrando falhas na degradação da análise em curso
+type guardedVelocity struct {
+	service *FraudDetectionService
+}
+
+func (v *guardedVelocity) GetVelocity(ctx context.Context, userID string, window time.Duration, at time.Time) (*models.VelocityCheck, error) {
+	guard := v.service.guard(DependencyVelocity)
+
+	var check *models.VelocityCheck
+	err := guard.call(ctx, func() error {
+		var err error
+		check, err = v.service.velocityStore.GetVelocity(userID, window, at)
+		return err
+	})
+	if err != nil {
+		if d := degradationFrom(ctx); d != nil {
+			d.record(guard, err)
+		}
+		return nil, err
+	}
+
+	return check, nil
+}
+
+// GetBINVelocity consulta cartões distintos por BIN com a mesma proteção da velocidade por usuário
+func (v *guardedVelocity) GetBINVelocity(ctx context.Context, bin, card string, window time.Duration, at time.Time) (*models.BINVelocity, error) {
+	guard := v.service.guard(DependencyVelocity)
+
+	var velocity *models.BINVelocity
+	err := guard.call(ctx, func() error {
+		var err error
+		velocity, err = v.service.binVelocityStore.GetBINVelocity(bin, card, window, at)
+		return err
+	})
+	if err != nil {
+		if d := degradationFrom(ctx); d != nil {
+			d.record(guard, err)
+		}
+		return nil, err
+	}
+
+	return velocity, nil
+}
+
+// GetDeviceUsage consulta usuários distintos por dispositivo com a mesma proteção da velocidade por usuário
+func (v *guardedVelocity) GetDeviceUsage(ctx context.Context, fingerprint, userID string, window time.Duration, at time.Time) (*models.DeviceUsage, error) {
+	guard := v.service.guard(DependencyVelocity)
+
+	var usage *models.DeviceUsage
+	err := guard.call(ctx, func() error {
+		var err error
+		usage, err = v.service.deviceStore.GetDeviceUsage(fingerprint, userID, window, at)
+		return err
+	})
+	if err != nil {
+		if d := degradationFrom(ctx); d != nil {
+			d.record(guard, err)
+		}
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// GetCardVelocity consulta a velocidade do cartão com a mesma proteção da velocidade por usuário
+func (v *guardedVelocity) GetCardVelocity(ctx context.Context, fingerprint, userID string, window time.Duration, at time.Time) (*models.VelocityCheck, error) {
+	guard := v.service.guard(DependencyVelocity)
+
+	var check *models.VelocityCheck
+	err := guard.call(ctx, func() error {
+		var err error
+		check, err = v.service.velocityStore.GetCardVelocity(fingerprint, userID, window, at)
+		return err
+	})
+	if err != nil {
+		if d := degradationFrom(ctx); d != nil {
+			d.record(guard, err)
+		}
+		return nil, err
+	}
+
+	return check, nil
+}
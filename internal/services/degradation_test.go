@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/anti-fraud-golang/internal/resilience"
+)
+
+func TestDependencyGuard(t *testing.T) {
+	policy := DefaultDegradationConfig().Profile
+	policy.MaxRetries = 2
+	policy.BackoffMs = 0
+	policy.BreakerThreshold = 3
+
+	tests := []struct {
+		name     string
+		err      error
+		attempts int
+		opensAt  int
+	}{
+		{"transient error is retried and counted once per call", errors.New("connection refused"), 3, 3},
+		{"store unavailable is retried", fmt.Errorf("%w: timeout", ErrStoreUnavailable), 3, 3},
+		{"not found is neither retried nor counted", ErrProfileNotFound, 1, 0},
+		{"invalid entry is neither retried nor counted", fmt.Errorf("%w: empty value", ErrInvalidBlacklistEntry), 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			guard := newDependencyGuards(DegradationConfig{Profile: policy})[DependencyProfile]
+
+			for call := 1; call <= 5; call++ {
+				attempts := 0
+				err := guard.call(context.Background(), func() error {
+					attempts++
+					return tt.err
+				})
+
+				open := tt.opensAt > 0 && call > tt.opensAt
+				if open {
+					if !errors.Is(err, resilience.ErrCircuitOpen) || attempts != 0 {
+						t.Fatalf("call %d: error = %v after %d attempts, want open circuit", call, err, attempts)
+					}
+					continue
+				}
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("call %d: error = %v, want %v", call, err, tt.err)
+				}
+				if attempts != tt.attempts {
+					t.Fatalf("call %d: %d attempts, want %d", call, attempts, tt.attempts)
+				}
+			}
+		})
+	}
+}
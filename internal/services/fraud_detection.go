@@ -1,8 +1,13 @@
 package services
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 	
+	"github.com/anti-fraud-golang/internal/ml"
 	"github.com/anti-fraud-golang/internal/models"
 	"github.com/anti-fraud-golang/internal/rules"
 	"github.com/anti-fraud-golang/pkg/utils"
@@ -10,63 +15,257 @@ import (
 
 // FraudDetectionService serviço de detecção de fraude
 type FraudDetectionService struct {
-	ruleEngine    *rules.RuleEngine
-	profileStore  ProfileStore
-	blacklistStore BlacklistStore
+	ruleEngine           *rules.RuleEngine
+	engineConfig         rules.EngineConfig
+	model                *ml.Model
+	engineMu             sync.RWMutex
+	configMu             sync.Mutex
+	profileStore         ProfileStore
+	blacklistStore       BlacklistStore
+	peerGroupStore       PeerGroupStore
+	velocityStore        VelocityStore
+	binVelocityStore     BINVelocityStore
+	deviceStore          DeviceUsageStore
+	travelNoticeStore    TravelNoticeStore
+	pixLimitStore        PIXLimitStore
+	pixUsageStore        PIXUsageStore
+	muleStore            MuleAccountStore
+	ruleVersionStore     RuleVersionStore
+	profileMu            sync.Mutex
+	pixLimitMu           sync.Mutex
+	deviceTrustThreshold int
+	guards               map[string]*dependencyGuard
+	guardsMu             sync.RWMutex
+	clock                Clock
+	timestampPolicy      TimestampPolicy
+	timeMu               sync.RWMutex
+	ipGeolocator         IPGeolocator
+	ipReputation         IPReputationChecker
+	binTable             BINLookup
+	fxRates              FXRates
+	maxRateAge           time.Duration
+	baseCurrency         string
+	enrichMu             sync.RWMutex
 }
 
-// ProfileStore interface para armazenamento de perfis
+// Erros dos stores. Implementações devem usar ErrProfileNotFound para perfis
+// inexistentes e embrulhar falhas de infraestrutura em ErrStoreUnavailable,
+// para que uma indisponibilidade nunca seja confundida com um usuário novo.
+var (
+	ErrProfileNotFound       = errors.New("profile not found")
+	ErrInvalidBlacklistEntry = errors.New("invalid blacklist entry")
+	ErrStoreUnavailable      = errors.New("store unavailable")
+)
+
+// ProfileStore interface para armazenamento de perfis.
+// GetUserProfile retorna ErrProfileNotFound se o usuário não tem perfil.
 type ProfileStore interface {
 	GetUserProfile(userID string) (*models.UserProfile, error)
 	UpdateUserProfile(profile *models.UserProfile) error
 }
 
-// BlacklistStore interface para lista negra
+// BlacklistStore interface para lista negra.
+// IsBlacklisted considera expiradas as entradas com ExpiresAt antes de at e
+// retorna false, nil para valores ausentes; Add retorna
+// ErrInvalidBlacklistEntry para entradas sem tipo ou valor.
 type BlacklistStore interface {
-	IsBlacklisted(entryType, value string) (bool, error)
+	IsBlacklisted(entryType, value string, at time.Time) (bool, error)
 	Add(entry *models.BlacklistEntry) error
 }
 
+// PeerGroupStore interface para estatísticas de grupos de pares
+type PeerGroupStore interface {
+	Observe(transaction *models.Transaction) error
+	GetPeerGroupStats(key models.PeerGroupKey) (*models.PeerGroupStats, error)
+	ListPeerGroupStats(dimension string) ([]models.PeerGroupStats, error)
+}
+
+// VelocityStore interface para contagem de transações recentes por usuário
+// e por cartão. GetCardVelocity inclui o usuário informado em DistinctUsers.
+type VelocityStore interface {
+	Record(transaction *models.Transaction) error
+	GetVelocity(userID string, window time.Duration, at time.Time) (*models.VelocityCheck, error)
+	GetCardVelocity(fingerprint, userID string, window time.Duration, at time.Time) (*models.VelocityCheck, error)
+}
+
+// BINVelocityStore interface para contagem de cartões distintos por BIN.
+// GetBINVelocity inclui o cartão informado na contagem de cartões distintos.
+type BINVelocityStore interface {
+	Record(transaction *models.Transaction) error
+	GetBINVelocity(bin, card string, window time.Duration, at time.Time) (*models.BINVelocity, error)
+}
+
+// DeviceUsageStore interface para contagem de usuários distintos por
+// impressão digital de dispositivo. GetDeviceUsage inclui o usuário informado.
+type DeviceUsageStore interface {
+	Record(transaction *models.Transaction) error
+	GetDeviceUsage(fingerprint, userID string, window time.Duration, at time.Time) (*models.DeviceUsage, error)
+}
+
+// TravelNoticeStore interface para os avisos de viagem dos usuários.
+// Delete retorna ErrTravelNoticeNotFound para avisos inexistentes.
+type TravelNoticeStore interface {
+	Add(notice *models.TravelNotice) error
+	List(userID string) ([]models.TravelNotice, error)
+	Delete(userID, id string) error
+}
+
+// PIXLimitStore interface para os limites PIX configurados pelos usuários.
+// Get retorna nil, nil para quem nunca configurou os seus.
+type PIXLimitStore interface {
+	Get(userID string) (*models.PIXLimits, error)
+	Save(limits *models.PIXLimits) error
+}
+
+// PIXUsageStore interface para o total transferido via PIX por usuário.
+// GetPIXUsage considera as transferências em [from, to). Reserve registra a
+// transferência só se o total de [from, to) continuar dentro do limite, de
+// forma atômica, e não registra de novo uma transação já reservada.
+type PIXUsageStore interface {
+	Reserve(transaction *models.Transaction, from, to time.Time, limit models.Money) (bool, error)
+	GetPIXUsage(userID string, from, to time.Time) (*models.PIXUsage, error)
+}
+
+// MuleAccountStore interface para as chaves e contas marcadas como laranjas.
+// Get retorna nil, nil para valores não marcados; Delete retorna
+// ErrMuleFlagNotFound para marcações inexistentes.
+type MuleAccountStore interface {
+	Add(flag *models.MuleFlag) error
+	Get(flagType, value string) (*models.MuleFlag, error)
+	List() ([]models.MuleFlag, error)
+	Delete(id string) error
+}
+
+// RuleVersionStore interface para o histórico de versões das regras
+type RuleVersionStore interface {
+	Append(version *RuleSetVersion) error
+	Get(version int) (*RuleSetVersion, error)
+	Latest() (*RuleSetVersion, error)
+	List() ([]RuleSetVersion, error)
+}
+
 // NewFraudDetectionService cria uma nova instância do serviço
 func NewFraudDetectionService(profileStore ProfileStore, blacklistStore BlacklistStore) *FraudDetectionService {
-	return &FraudDetectionService{
-		ruleEngine:    rules.NewRuleEngine(),
-		profileStore:  profileStore,
-		blacklistStore: blacklistStore,
+	// Estatísticas de pares e de velocidade são derivadas do próprio tráfego
+	// analisado; o histórico de versões de regras começa com a configuração padrão
+	service := &FraudDetectionService{
+		profileStore:         profileStore,
+		blacklistStore:       blacklistStore,
+		peerGroupStore:       NewInMemoryPeerGroupStore(),
+		velocityStore:        NewInMemoryVelocityStore(),
+		binVelocityStore:     NewInMemoryBINVelocityStore(),
+		deviceStore:          NewInMemoryDeviceUsageStore(),
+		travelNoticeStore:    NewInMemoryTravelNoticeStore(),
+		pixLimitStore:        NewInMemoryPIXLimitStore(),
+		pixUsageStore:        NewInMemoryPIXUsageStore(),
+		muleStore:            NewInMemoryMuleAccountStore(),
+		ruleVersionStore:     NewInMemoryRuleVersionStore(),
+		guards:               newDependencyGuards(DefaultDegradationConfig()),
+		clock:                SystemClock{},
+		timestampPolicy:      DefaultTimestampPolicy(),
+		deviceTrustThreshold: DefaultDeviceTrustThreshold,
+		baseCurrency:         DefaultBaseCurrency,
+	}
+	
+	// Registra a configuração padrão como versão inicial do conjunto de regras
+	if _, err := service.commitRuleConfig(rules.EngineConfig{}, RuleChange{
+		Author:  "system",
+		Comment: "configuração inicial",
+	}, 0); err != nil {
+		service.ruleEngine = rules.NewRuleEngineWithDependencies(service.dependencies())
 	}
+	
+	return service
 }
 
-// AnalyzeTransaction analisa uma transação para detectar fraude
-func (s *FraudDetectionService) AnalyzeTransaction(transaction *models.Transaction) (*models.FraudAnalysisResult, error) {
+// AnalyzeTransaction analisa uma transação para detectar fraude. O contexto
+// limita o tempo de avaliação das regras.
+func (s *FraudDetectionService) AnalyzeTransaction(ctx context.Context, transaction *models.Transaction) (*models.FraudAnalysisResult, error) {
 	startTime := time.Now()
 	
-	// Define timestamp se não estiver definido
-	if transaction.Timestamp.IsZero() {
-		transaction.Timestamp = time.Now()
+	// Regras usam sempre o horário do evento; o recebimento fica registrado
+	if err := s.eventTime(transaction); err != nil {
+		return nil, err
 	}
+	if err := validateCard(transaction); err != nil {
+		return nil, err
+	}
+	if err := validateCurrency(transaction); err != nil {
+		return nil, err
+	}
+	if err := validatePIX(transaction); err != nil {
+		return nil, err
+	}
+	
+	// Localização e reputação do IP e emissor do cartão vêm das bases locais, não do cliente
+	s.enrichIP(transaction)
+	s.enrichCard(transaction)
+	
+	// Limites e perfis trabalham na moeda base
+	if err := s.normalizeAmount(transaction); err != nil {
+		return nil, err
+	}
+	
+	// Usa o mesmo conjunto de regras durante toda a análise
+	engine := s.engine()
+	
+	// Falhas de dependências degradam a análise em vez de interrompê-la
+	degradation := newDegradation()
+	ctx = withDegradation(ctx, degradation)
 	
 	// Verifica lista negra primeiro
-	blacklisted, err := s.checkBlacklist(transaction)
+	blacklistGuard := s.guard(DependencyBlacklist)
+	var blacklisted bool
+	err := blacklistGuard.call(ctx, func() error {
+		var err error
+		blacklisted, err = s.checkBlacklist(transaction)
+		return err
+	})
 	if err != nil {
-		return nil, err
+		degradation.record(blacklistGuard, err)
 	}
 	
 	if blacklisted {
-		return s.createBlockedResult(transaction, "Entidade na lista negra", startTime), nil
+		result := s.createBlockedResult(transaction, "Entidade na lista negra", startTime)
+		result.RuleSetVersion = engine.Version()
+		return result, nil
 	}
 	
-	// Obtém perfil do usuário
-	profile, err := s.profileStore.GetUserProfile(transaction.UserID)
+	// Obtém perfil do usuário; perfil inexistente significa usuário novo
+	profileGuard := s.guard(DependencyProfile)
+	var profile *models.UserProfile
+	err = profileGuard.call(ctx, func() error {
+		var err error
+		profile, err = s.profileStore.GetUserProfile(transaction.UserID)
+		if errors.Is(err, ErrProfileNotFound) {
+			profile = nil
+			return nil
+		}
+		return err
+	})
 	if err != nil {
-		// Se não encontrar perfil, cria um vazio (usuário novo)
 		profile = nil
+		degradation.record(profileGuard, err)
+	}
+	
+	// Média gravada em outra moeda é levada à moeda base antes das regras
+	profile = s.profileInBaseCurrency(profile, transaction.AmountInBase().Currency)
+	
+	// Aviso de viagem ativo atenua as regras geográficas; sem ele a análise segue sem atenuação
+	travelGuard := s.guard(DependencyTravel)
+	if err := travelGuard.call(ctx, func() error {
+		return s.matchTravelNotice(transaction)
+	}); err != nil {
+		transaction.TravelNotice = nil
+		degradation.record(travelGuard, err)
 	}
 	
-	// Avalia todas as regras
-	ruleResults := s.ruleEngine.Evaluate(transaction, profile)
+	// Avalia as regras em ordem de prioridade
+	evaluation := engine.Evaluate(ctx, transaction, profile)
+	ruleResults := evaluation.Results
 	
 	// Calcula score total
-	totalScore := s.ruleEngine.CalculateTotalScore(ruleResults)
+	totalScore := engine.CalculateTotalScore(ruleResults)
 	
 	// Determina nível de risco e decisão
 	riskLevel := rules.GetRiskLevel(totalScore)
@@ -75,38 +274,213 @@ func (s *FraudDetectionService) AnalyzeTransaction(transaction *models.Transacti
 	// Extrai razões e regras acionadas
 	reasons := make([]string, 0)
 	rulesTriggered := make([]string, 0)
+	triggeredRuleIDs := make([]string, 0)
+	ruleDetails := make(map[string]interface{})
 	
 	for _, result := range ruleResults {
 		if result.Triggered {
 			reasons = append(reasons, result.Description)
 			rulesTriggered = append(rulesTriggered, result.RuleName)
+			triggeredRuleIDs = append(triggeredRuleIDs, result.RuleID)
+			if len(result.Details) > 0 {
+				ruleDetails[result.RuleID] = result.Details
+			}
+		}
+	}
+	
+	// Regra do modelo que não concluiu conta como indisponibilidade do modelo
+	if containsString(evaluation.TimedOut, "model_rule") || containsString(evaluation.Errored, "model_rule") {
+		degradation.record(s.guard(DependencyModel), errors.New("model_rule não concluída"))
+	}
+	
+	// Registra a tentativa para as métricas de velocidade das próximas análises
+	velocityGuard := s.guard(DependencyVelocity)
+	if err := velocityGuard.call(ctx, func() error {
+		return s.velocityStore.Record(transaction)
+	}); err != nil {
+		degradation.record(velocityGuard, err)
+	}
+	if transaction.CardBIN != "" {
+		if err := velocityGuard.call(ctx, func() error {
+			return s.binVelocityStore.Record(transaction)
+		}); err != nil {
+			degradation.record(velocityGuard, err)
+		}
+	}
+	if transaction.DeviceInfo.Fingerprint != "" {
+		if err := velocityGuard.call(ctx, func() error {
+			return s.deviceStore.Record(transaction)
+		}); err != nil {
+			degradation.record(velocityGuard, err)
+		}
+	}
+	
+	// Atenuação por aviso de viagem entra na explicação da decisão
+	for _, adjustment := range evaluation.TravelAdjustments {
+		if adjustment.Suppressed {
+			reasons = append(reasons, "Regra suprimida por aviso de viagem: "+adjustment.RuleID)
+		} else {
+			reasons = append(reasons, "Score reduzido por aviso de viagem: "+adjustment.RuleID)
+		}
+	}
+	
+	// Regras de veto forçam a decisão independente do score
+	veto, vetoed := engine.ApplyVetoes(ruleResults)
+	if vetoed {
+		decision = veto.Decision
+		reasons = append(reasons, "Decisão forçada por regra de veto: "+veto.RuleID)
+	}
+	
+	// O limite PIX é obrigatório: sem conseguir verificá-lo a transferência vai para revisão
+	if transaction.PIX != nil && pixLimitUnverified(evaluation) && decision == models.DecisionApproved {
+		decision = models.DecisionReview
+		reasons = append(reasons, "Limite PIX não verificado")
+	}
+	
+	// Valor convertido com cotação defasada pode estar errado e não é aprovado sozinho
+	if fx := transaction.FXConversion; fx != nil && fx.Stale && decision == models.DecisionApproved {
+		decision = models.DecisionReview
+		reasons = append(reasons, "Cotação defasada para "+fx.Currency)
+	}
+	
+	// Dependências em fail_closed impõem uma decisão mínima
+	degradedReasons, fallback := degradation.summary()
+	if stricter := rules.StricterDecision(decision, fallback); stricter != decision {
+		decision = stricter
+		reasons = append(reasons, "Decisão mínima aplicada por dependência indisponível")
+	}
+	
+	// Transferências PIX liberadas reservam o valor no limite do período antes
+	// da resposta; a regra lê o total sem trava e não barra envios simultâneos
+	if transaction.PIX != nil && decision != models.DecisionBlocked {
+		reserved, err := s.reservePIX(ctx, transaction)
+		switch {
+		case err != nil:
+			if decision == models.DecisionApproved {
+				decision = models.DecisionReview
+				reasons = append(reasons, "Limite PIX não verificado")
+			}
+			degradedReasons, fallback = degradation.summary()
+			decision = rules.StricterDecision(decision, fallback)
+		case !reserved:
+			veto, vetoed = &rules.VetoConfig{RuleID: rules.PIXLimitRuleID, Decision: models.DecisionBlocked}, true
+			decision = models.DecisionBlocked
+			reasons = append(reasons, "Decisão forçada por regra de veto: "+rules.PIXLimitRuleID)
 		}
 	}
 	
 	// Cria resultado da análise
 	analysisResult := &models.FraudAnalysisResult{
-		TransactionID:  transaction.ID,
-		RiskScore:      totalScore,
-		RiskLevel:      riskLevel,
-		Decision:       decision,
-		Reasons:        reasons,
-		RulesTriggered: rulesTriggered,
-		AnalyzedAt:     time.Now(),
-		ProcessingTime: time.Since(startTime).Milliseconds(),
-		Details: map[string]interface{}{
-			"user_id":  transaction.UserID,
-			"amount":   transaction.Amount,
-			"merchant": transaction.Merchant,
+		TransactionID:    transaction.ID,
+		RiskScore:        totalScore,
+		RiskLevel:        riskLevel,
+		Decision:         decision,
+		Reasons:          reasons,
+		RulesTriggered:   rulesTriggered,
+		TriggeredRuleIDs: triggeredRuleIDs,
+		RuleSetVersion:   engine.Version(),
+		SkippedRules:     evaluation.Skipped,
+		TimedOutRules:    evaluation.TimedOut,
+		ErroredRules:     evaluation.Errored,
+		Degraded:         len(degradedReasons) > 0,
+		DegradedReasons:  degradedReasons,
+		EventTime:        transaction.Timestamp,
+		ReceivedAt:       transaction.ReceivedAt,
+		AnalyzedAt:       s.now(),
+		ProcessingTime:   time.Since(startTime).Milliseconds(),
+		Details:          map[string]interface{}{
+			"user_id":     transaction.UserID,
+			"amount":      transaction.Amount,
+			"merchant":    transaction.Merchant,
+			"rules":       ruleDetails,
+			"aggregation": engine.AggregationStrategy(),
 		},
 	}
 	
+	if evaluation.ShortCircuit != "" {
+		shortCircuit := map[string]interface{}{
+			"reason":  evaluation.ShortCircuit,
+			"skipped": evaluation.Skipped,
+		}
+		if evaluation.ShortCircuitRule != "" {
+			shortCircuit["rule_id"] = evaluation.ShortCircuitRule
+		}
+		analysisResult.Details["short_circuit"] = shortCircuit
+	}
+	
+	if len(evaluation.TimedOut) > 0 {
+		analysisResult.Details["timeouts"] = map[string]interface{}{
+			"policy": evaluation.TimeoutPolicy,
+			"rules":  evaluation.TimedOut,
+		}
+	}
+	
+	if transaction.IPGeolocation != nil {
+		analysisResult.Details["ip_geolocation"] = transaction.IPGeolocation
+	}
+	if transaction.IPReputation != nil {
+		analysisResult.Details["ip_reputation"] = transaction.IPReputation
+	}
+	if transaction.CardIssuer != nil {
+		analysisResult.Details["card_issuer"] = transaction.CardIssuer
+	}
+	if transaction.FXConversion != nil {
+		analysisResult.Details["fx"] = transaction.FXConversion
+	}
+	if geoVelocity := engine.GeoVelocity(transaction, profile); geoVelocity != nil {
+		analysisResult.Details["geo_velocity"] = geoVelocity
+	}
+	if notice := transaction.TravelNotice; notice != nil {
+		adjustments := evaluation.TravelAdjustments
+		if adjustments == nil {
+			adjustments = []rules.TravelAdjustment{}
+		}
+		analysisResult.Details["travel_notice"] = map[string]interface{}{
+			"id":          notice.ID,
+			"countries":   notice.Countries,
+			"adjustments": adjustments,
+		}
+	}
+	
+	if vetoed {
+		analysisResult.Details["veto"] = map[string]interface{}{
+			"rule_id":  veto.RuleID,
+			"decision": veto.Decision,
+		}
+	}
+	
+	// Só transações aprovadas alimentam o perfil comportamental e as estatísticas
+	// de pares: aprender com as que estão em revisão levaria a linha de base para
+	// o comportamento de quem está sendo investigado.
+	// Sem um perfil confiável a atualização é adiada para não sobrescrever o histórico.
+	if decision == models.DecisionApproved {
+		if degradation.hasFailed(DependencyProfile) {
+			analysisResult.Details["profile_update"] = "skipped"
+		} else if err := s.updateProfile(transaction); err != nil {
+			degradation.record(profileGuard, err)
+			analysisResult.Degraded = true
+			analysisResult.DegradedReasons, _ = degradation.summary()
+		}
+		
+		// A transação já foi aceita; falha nas estatísticas de pares só degrada a análise
+		peerGuard := s.guard(DependencyPeerGroup)
+		if err := peerGuard.call(ctx, func() error {
+			return s.peerGroupStore.Observe(transaction)
+		}); err != nil {
+			degradation.record(peerGuard, err)
+			analysisResult.Degraded = true
+			analysisResult.DegradedReasons, _ = degradation.summary()
+		}
+	}
+	
 	return analysisResult, nil
 }
 
 // checkBlacklist verifica se algum elemento da transação está na lista negra
+// no horário do evento
 func (s *FraudDetectionService) checkBlacklist(transaction *models.Transaction) (bool, error) {
 	// Verifica usuário
-	blacklisted, err := s.blacklistStore.IsBlacklisted("user", transaction.UserID)
+	blacklisted, err := s.blacklistStore.IsBlacklisted("user", transaction.UserID, transaction.Timestamp)
 	if err != nil {
 		return false, err
 	}
@@ -114,9 +488,9 @@ func (s *FraudDetectionService) checkBlacklist(transaction *models.Transaction)
 		return true, nil
 	}
 	
-	// Verifica cartão
-	if transaction.CardLast4 != "" {
-		blacklisted, err = s.blacklistStore.IsBlacklisted("card", transaction.CardLast4)
+	// Verifica cartão pela impressão digital; os últimos dígitos são só para exibição
+	if transaction.CardFingerprint != "" {
+		blacklisted, err = s.blacklistStore.IsBlacklisted("card", transaction.CardFingerprint, transaction.Timestamp)
 		if err != nil {
 			return false, err
 		}
@@ -127,7 +501,7 @@ func (s *FraudDetectionService) checkBlacklist(transaction *models.Transaction)
 	
 	// Verifica IP
 	if transaction.Location.IPAddress != "" {
-		blacklisted, err = s.blacklistStore.IsBlacklisted("ip", transaction.Location.IPAddress)
+		blacklisted, err = s.blacklistStore.IsBlacklisted("ip", transaction.Location.IPAddress, transaction.Timestamp)
 		if err != nil {
 			return false, err
 		}
@@ -138,7 +512,7 @@ func (s *FraudDetectionService) checkBlacklist(transaction *models.Transaction)
 	
 	// Verifica dispositivo
 	if transaction.DeviceInfo.DeviceID != "" {
-		blacklisted, err = s.blacklistStore.IsBlacklisted("device", transaction.DeviceInfo.DeviceID)
+		blacklisted, err = s.blacklistStore.IsBlacklisted("device", transaction.DeviceInfo.DeviceID, transaction.Timestamp)
 		if err != nil {
 			return false, err
 		}
@@ -153,34 +527,33 @@ func (s *FraudDetectionService) checkBlacklist(transaction *models.Transaction)
 // createBlockedResult cria um resultado de bloqueio
 func (s *FraudDetectionService) createBlockedResult(transaction *models.Transaction, reason string, startTime time.Time) *models.FraudAnalysisResult {
 	return &models.FraudAnalysisResult{
-		TransactionID:  transaction.ID,
-		RiskScore:      100,
-		RiskLevel:      models.RiskLevelHigh,
-		Decision:       models.DecisionBlocked,
-		Reasons:        []string{reason},
-		RulesTriggered: []string{"Blacklist Check"},
-		AnalyzedAt:     time.Now(),
-		ProcessingTime: time.Since(startTime).Milliseconds(),
-		Details: map[string]interface{}{
+		TransactionID:    transaction.ID,
+		RiskScore:        100,
+		RiskLevel:        models.RiskLevelHigh,
+		Decision:         models.DecisionBlocked,
+		Reasons:          []string{reason},
+		RulesTriggered:   []string{"Blacklist Check"},
+		TriggeredRuleIDs: []string{"blacklist_check"},
+		EventTime:        transaction.Timestamp,
+		ReceivedAt:       transaction.ReceivedAt,
+		AnalyzedAt:       s.now(),
+		ProcessingTime:   time.Since(startTime).Milliseconds(),
+		Details:          map[string]interface{}{
 			"blocked_reason": reason,
 		},
 	}
 }
 
-// GetTransactionAnalytics retorna analytics de transações
+// GetTransactionAnalytics retorna analytics de transações.
+// Usuários sem perfil resultam em ErrProfileNotFound.
 func (s *FraudDetectionService) GetTransactionAnalytics(userID string) (*TransactionAnalytics, error) {
 	profile, err := s.profileStore.GetUserProfile(userID)
 	if err != nil {
-		return nil, err
+		return nil, storeFailure(err)
 	}
 	
 	if profile == nil {
-		return &TransactionAnalytics{
-			UserID:             userID,
-			TotalTransactions:  0,
-			FraudCount:        0,
-			FraudRate:         0,
-		}, nil
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, userID)
 	}
 	
 	fraudCount := len(profile.FraudHistory)
@@ -201,10 +574,39 @@ func (s *FraudDetectionService) GetTransactionAnalytics(userID string) (*Transac
 
 // TransactionAnalytics estatísticas de transações
 type TransactionAnalytics struct {
-	UserID             string    `json:"user_id"`
-	TotalTransactions  int       `json:"total_transactions"`
-	AverageAmount      float64   `json:"average_amount"`
-	FraudCount        int       `json:"fraud_count"`
-	FraudRate         float64   `json:"fraud_rate"`
-	LastTransactionAt time.Time `json:"last_transaction_at"`
+	UserID            string       `json:"user_id"`
+	TotalTransactions int          `json:"total_transactions"`
+	AverageAmount     models.Money `json:"average_amount"`
+	FraudCount        int          `json:"fraud_count"`
+	FraudRate         float64      `json:"fraud_rate"`
+	LastTransactionAt time.Time    `json:"last_transaction_at"`
+}
+
+// GetPeerGroupAnalytics retorna as estatísticas dos grupos de pares de uma dimensão
+func (s *FraudDetectionService) GetPeerGroupAnalytics(dimension string) ([]models.PeerGroupStats, error) {
+	switch dimension {
+	case models.PeerDimensionCountry, models.PeerDimensionCardType,
+		models.PeerDimensionDeviceType, models.PeerDimensionMerchant:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidPeerDimension, dimension)
+	}
+	
+	stats, err := s.peerGroupStore.ListPeerGroupStats(dimension)
+	if err != nil {
+		return nil, storeFailure(err)
+	}
+	return stats, nil
+}
+
+// ErrInvalidPeerDimension dimensão de grupo de pares desconhecida
+var ErrInvalidPeerDimension = errors.New("invalid peer group dimension")
+
+// storeFailure classifica o erro de um store: erros conhecidos são mantidos e
+// os demais são tratados como indisponibilidade
+func storeFailure(err error) error {
+	if errors.Is(err, ErrProfileNotFound) || errors.Is(err, ErrInvalidBlacklistEntry) ||
+		errors.Is(err, ErrStoreUnavailable) {
+		return err
+	}
+	return fmt.Errorf("%w: %v", ErrStoreUnavailable, err)
 }
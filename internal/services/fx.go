@@ -0,0 +1,189 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/currency"
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// ErrInvalidCurrency moeda que não é um código ISO 4217 ativo ou sem cotação
+// para a moeda base
+var ErrInvalidCurrency = errors.New("invalid currency")
+
+// DefaultBaseCurrency moeda base de limites, perfis e estatísticas
+const DefaultBaseCurrency = "BRL"
+
+// DefaultMaxRateAge idade máxima de uma cotação antes de ser marcada como defasada
+const DefaultMaxRateAge = 7 * 24 * time.Hour
+
+// FXRates cotações para conversão à moeda base
+type FXRates interface {
+	Base() string
+	Rate(code string) (float64, time.Time, bool)
+}
+
+// SetBaseCurrency define a moeda base; sem cotações, só transações nela são aceitas
+func (s *FraudDetectionService) SetBaseCurrency(code string) error {
+	base, err := normalizeCurrency(code)
+	if err != nil {
+		return err
+	}
+
+	s.enrichMu.Lock()
+	defer s.enrichMu.Unlock()
+	if s.fxRates != nil && s.fxRates.Base() != base {
+		return fmt.Errorf("%w: base currency %s differs from the rate table base %s", ErrInvalidCurrency, base, s.fxRates.Base())
+	}
+	s.baseCurrency = base
+	return nil
+}
+
+// SetFXRates passa a converter os valores das transações para a moeda base
+// antes das regras; nil desativa a conversão. As cotações precisam ter a moeda
+// base configurada. Cotações mais antigas que maxAge em relação ao evento são
+// usadas, mas marcadas como defasadas e mandadas para revisão.
+func (s *FraudDetectionService) SetFXRates(rates FXRates, maxAge time.Duration) error {
+	if maxAge < 0 {
+		return fmt.Errorf("max rate age must not be negative, got %s", maxAge)
+	}
+	if maxAge == 0 {
+		maxAge = DefaultMaxRateAge
+	}
+
+	s.enrichMu.Lock()
+	defer s.enrichMu.Unlock()
+	if rates != nil && rates.Base() != s.baseCurrency {
+		return fmt.Errorf("%w: rate table base %s differs from the base currency %s", ErrInvalidCurrency, rates.Base(), s.baseCurrency)
+	}
+	s.fxRates = rates
+	s.maxRateAge = maxAge
+	return nil
+}
+
+// ParseAmount lê o valor informado pelo cliente, exato nas casas decimais da
+// moeda, com a mesma validação de moeda da análise. O valor precisa ser positivo.
+func ParseAmount(value, code string) (models.Money, error) {
+	normalized, err := normalizeCurrency(code)
+	if err != nil {
+		return models.Money{}, err
+	}
+	amount, err := models.ParseMoney(value, normalized)
+	if err != nil {
+		return models.Money{}, err
+	}
+	if !amount.IsPositive() {
+		return models.Money{}, fmt.Errorf("%w: amount must be greater than zero", models.ErrInvalidAmount)
+	}
+	return amount, nil
+}
+
+// normalizeCurrency padroniza o código em maiúsculas e exige que seja um
+// código ISO 4217 ativo
+func normalizeCurrency(code string) (string, error) {
+	normalized := currency.Normalize(code)
+	if !currency.Valid(normalized) {
+		return "", fmt.Errorf("%w: %q is not an ISO 4217 code", ErrInvalidCurrency, code)
+	}
+	return normalized, nil
+}
+
+// validateCurrency exige um código ISO 4217 ativo, o padroniza em maiúsculas
+// e confere a moeda do valor
+func validateCurrency(transaction *models.Transaction) error {
+	code, err := normalizeCurrency(transaction.Currency)
+	if err != nil {
+		return err
+	}
+	transaction.Currency = code
+
+	// Valores montados sem moeda são reinterpretados nas casas decimais dela
+	switch currency.Normalize(transaction.Amount.Currency) {
+	case code:
+		transaction.Amount.Currency = code
+	case "":
+		amount, err := models.ParseMoney(transaction.Amount.String(), code)
+		if err != nil {
+			return err
+		}
+		transaction.Amount = amount
+	default:
+		return fmt.Errorf("%w: amount in %s but transaction in %s", ErrInvalidCurrency, transaction.Amount.Currency, code)
+	}
+	return nil
+}
+
+// normalizeAmount converte o valor da transação para a moeda base. Moedas sem
+// cotação são rejeitadas, inclusive quando nenhuma tabela foi carregada: limites,
+// médias e estatísticas estão na moeda base e o valor sem conversão não pode
+// ser comparado a eles.
+func (s *FraudDetectionService) normalizeAmount(transaction *models.Transaction) error {
+	s.enrichMu.RLock()
+	rates, maxAge, base := s.fxRates, s.maxRateAge, s.baseCurrency
+	s.enrichMu.RUnlock()
+
+	if rates == nil {
+		if transaction.Currency != base {
+			return fmt.Errorf("%w: no exchange rates loaded, only %s is accepted", ErrInvalidCurrency, base)
+		}
+		return nil
+	}
+
+	rate, date, found := rates.Rate(transaction.Currency)
+	if !found {
+		return fmt.Errorf("%w: no exchange rate from %s to %s", ErrInvalidCurrency, transaction.Currency, rates.Base())
+	}
+
+	transaction.FXConversion = &models.FXConversion{
+		Currency:     transaction.Currency,
+		BaseCurrency: rates.Base(),
+		Rate:         rate,
+		RateDate:     &date,
+		// Arredonda nas casas decimais da moeda base
+		BaseAmount: transaction.Amount.Convert(rate, rates.Base()),
+		Stale:      transaction.Currency != rates.Base() && transaction.Timestamp.Sub(date) > maxAge,
+	}
+	return nil
+}
+
+// inBaseCurrency converte um valor gravado em outra moeda, como a média de um
+// perfil anterior à troca da moeda base. Valores sem moeda, de perfis antigos,
+// já estão na moeda base. false indica moeda sem cotação para a base.
+func (s *FraudDetectionService) inBaseCurrency(amount models.Money, base string) (models.Money, bool) {
+	switch amount.Currency {
+	case base:
+		return amount, true
+	case "":
+		return models.MoneyFromFloat(amount.Float64(), base), true
+	}
+
+	s.enrichMu.RLock()
+	rates := s.fxRates
+	s.enrichMu.RUnlock()
+
+	if rates != nil && rates.Base() == base {
+		if rate, _, found := rates.Rate(amount.Currency); found {
+			return amount.Convert(rate, base), true
+		}
+	}
+	return models.Money{}, false
+}
+
+// profileInBaseCurrency perfil com a média na moeda base da transação, para as
+// regras a compararem com o valor convertido. Média em moeda sem cotação não é
+// comparada: o perfil segue sem ela.
+func (s *FraudDetectionService) profileInBaseCurrency(profile *models.UserProfile, base string) *models.UserProfile {
+	if profile == nil || profile.AvgTransactionValue.Currency == base {
+		return profile
+	}
+
+	adjusted := *profile
+	average, ok := s.inBaseCurrency(profile.AvgTransactionValue, base)
+	if !ok {
+		average = models.NewMoney(0, base)
+	}
+	adjusted.AvgTransactionValue = average
+	return &adjusted
+}
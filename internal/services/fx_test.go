@@ -0,0 +1,340 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/currency"
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+func testRates(t *testing.T) *currency.RateTable {
+	t.Helper()
+	table, err := currency.ParseRateTable(strings.NewReader(`{
+		"base": "BRL",
+		"date": "2026-01-15",
+		"rates": {"USD": 5.42, "JPY": 0.036, "KWD": 17.65, "ARS": 0.0054},
+		"dates": {"ARS": "2025-12-01"}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseRateTable: %v", err)
+	}
+	return table
+}
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		value, code string
+		want        models.Money
+		err         error
+	}{
+		{"1234.56", "BRL", models.NewMoney(123456, "BRL"), nil},
+		{"1234.56", "brl", models.NewMoney(123456, "BRL"), nil},
+		{"450", "JPY", models.NewMoney(450, "JPY"), nil},
+		{"1.5", "KWD", models.NewMoney(1500, "KWD"), nil},
+		{"100.5", "JPY", models.Money{}, models.ErrInvalidAmount},
+		{"0", "BRL", models.Money{}, models.ErrInvalidAmount},
+		{"-10", "BRL", models.Money{}, models.ErrInvalidAmount},
+		{"10", "XYZ", models.Money{}, ErrInvalidCurrency},
+		{"10", "", models.Money{}, ErrInvalidCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value+" "+tt.code, func(t *testing.T) {
+			got, err := ParseAmount(tt.value, tt.code)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateCurrency(t *testing.T) {
+	tests := []struct {
+		name     string
+		currency string
+		amount   models.Money
+		want     models.Money
+		err      error
+	}{
+		{"lowercase code", "usd", models.NewMoney(1000, "USD"), models.NewMoney(1000, "USD"), nil},
+		{"amount without currency", "JPY", models.MoneyFromFloat(450, ""), models.NewMoney(450, "JPY"), nil},
+		{"decimals not allowed by currency", "JPY", models.MoneyFromFloat(100.5, ""), models.Money{}, models.ErrInvalidAmount},
+		{"unknown code", "BRX", models.NewMoney(1000, "BRL"), models.Money{}, ErrInvalidCurrency},
+		{"amount in another currency", "BRL", models.NewMoney(1000, "USD"), models.Money{}, ErrInvalidCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &models.Transaction{Currency: tt.currency, Amount: tt.amount}
+			err := validateCurrency(transaction)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if err == nil && transaction.Amount != tt.want {
+				t.Errorf("amount = %+v, want %+v", transaction.Amount, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeAmount(t *testing.T) {
+	eventTime := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		amount models.Money
+		want   models.Money
+		stale  bool
+		err    error
+	}{
+		{"base currency", models.NewMoney(10000, "BRL"), models.NewMoney(10000, "BRL"), false, nil},
+		{"cents", models.NewMoney(10050, "USD"), models.NewMoney(54471, "BRL"), false, nil},
+		{"no minor unit", models.NewMoney(10000, "JPY"), models.NewMoney(36000, "BRL"), false, nil},
+		{"three decimals", models.NewMoney(1234, "KWD"), models.NewMoney(2178, "BRL"), false, nil},
+		{"stale rate", models.NewMoney(100000, "ARS"), models.NewMoney(540, "BRL"), true, nil},
+		{"missing rate", models.NewMoney(10000, "EUR"), models.Money{}, false, ErrInvalidCurrency},
+	}
+
+	service := NewFraudDetectionService(NewInMemoryProfileStore(), NewInMemoryBlacklistStore())
+	if err := service.SetFXRates(testRates(t), 0); err != nil {
+		t.Fatalf("SetFXRates: %v", err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transaction := &models.Transaction{Currency: tt.amount.Currency, Amount: tt.amount, Timestamp: eventTime}
+			err := service.normalizeAmount(transaction)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				if transaction.FXConversion != nil {
+					t.Error("conversion recorded for a rejected amount")
+				}
+				return
+			}
+			if got := transaction.AmountInBase(); got != tt.want {
+				t.Errorf("AmountInBase() = %s %s, want %s %s", got, got.Currency, tt.want, tt.want.Currency)
+			}
+			if transaction.FXConversion.Stale != tt.stale {
+				t.Errorf("Stale = %v, want %v", transaction.FXConversion.Stale, tt.stale)
+			}
+		})
+	}
+}
+
+func TestMissingRateIsRejectedBeforeAnyEffect(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	profiles := NewInMemoryProfileStore()
+	service := NewFraudDetectionService(profiles, NewInMemoryBlacklistStore())
+	service.SetClock(ClockFunc(func() time.Time { return now }))
+	if err := service.SetFXRates(testRates(t), 0); err != nil {
+		t.Fatalf("SetFXRates: %v", err)
+	}
+
+	_, err := service.AnalyzeTransaction(context.Background(), &models.Transaction{
+		UserID:    "USER1",
+		Amount:    models.NewMoney(1000000, "EUR"),
+		Currency:  "EUR",
+		Timestamp: now,
+	})
+	if !errors.Is(err, ErrInvalidCurrency) {
+		t.Fatalf("error = %v, want %v", err, ErrInvalidCurrency)
+	}
+	if _, err := profiles.GetUserProfile("USER1"); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("profile created for a rejected transaction: %v", err)
+	}
+	velocity, err := service.velocityStore.GetVelocity("USER1", time.Hour, now)
+	if err != nil {
+		t.Fatalf("GetVelocity: %v", err)
+	}
+	if velocity.TransactionCount != 0 {
+		t.Errorf("velocity count = %d, want 0", velocity.TransactionCount)
+	}
+}
+
+func TestAverageAmountInBaseCurrency(t *testing.T) {
+	service := NewFraudDetectionService(NewInMemoryProfileStore(), NewInMemoryBlacklistStore())
+	if err := service.SetFXRates(testRates(t), 0); err != nil {
+		t.Fatalf("SetFXRates: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		average models.Money
+		count   int
+		amount  models.Money
+		want    models.Money
+	}{
+		{"first transaction", models.Money{}, 0, models.NewMoney(12345, "BRL"), models.NewMoney(12345, "BRL")},
+		{"exact minor units", models.NewMoney(10000, "BRL"), 2, models.NewMoney(10001, "BRL"), models.NewMoney(10000, "BRL")},
+		{"rounds half up", models.NewMoney(10001, "BRL"), 1, models.NewMoney(10000, "BRL"), models.NewMoney(10001, "BRL")},
+		{"legacy average without currency", models.MoneyFromFloat(100, ""), 1, models.NewMoney(30000, "BRL"), models.NewMoney(20000, "BRL")},
+		{"average in a previous base currency", models.NewMoney(10000, "USD"), 1, models.NewMoney(54200, "BRL"), models.NewMoney(54200, "BRL")},
+		{"average in a currency without rate", models.NewMoney(10000, "EUR"), 4, models.NewMoney(30000, "BRL"), models.NewMoney(30000, "BRL")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := service.averageAmount(tt.average, tt.count, tt.amount)
+			if got != tt.want {
+				t.Errorf("got %s %s, want %s %s", got, got.Currency, tt.want, tt.want.Currency)
+			}
+		})
+	}
+}
+
+func TestProfileAverageUsesBaseCurrency(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	profiles := NewInMemoryProfileStore()
+	service := NewFraudDetectionService(profiles, NewInMemoryBlacklistStore())
+	service.SetClock(ClockFunc(func() time.Time { return now }))
+	if err := service.SetFXRates(testRates(t), 0); err != nil {
+		t.Fatalf("SetFXRates: %v", err)
+	}
+
+	for _, amount := range []models.Money{models.NewMoney(10000, "JPY"), models.NewMoney(10000, "BRL")} {
+		if _, err := service.AnalyzeTransaction(context.Background(), &models.Transaction{
+			UserID:    "USER1",
+			Amount:    amount,
+			Currency:  amount.Currency,
+			Timestamp: now,
+		}); err != nil {
+			t.Fatalf("AnalyzeTransaction: %v", err)
+		}
+	}
+
+	profile, err := profiles.GetUserProfile("USER1")
+	if err != nil {
+		t.Fatalf("GetUserProfile: %v", err)
+	}
+	// ¥10.000 = R$ 360,00; média com R$ 100,00
+	if want := models.NewMoney(23000, "BRL"); profile.AvgTransactionValue != want {
+		t.Errorf("average = %s %s, want %s BRL", profile.AvgTransactionValue, profile.AvgTransactionValue.Currency, want)
+	}
+}
+
+func TestWithoutRatesOnlyBaseCurrencyIsAccepted(t *testing.T) {
+	tests := []struct {
+		name     string
+		base     string
+		currency string
+		err      error
+	}{
+		{"default base", "", "BRL", nil},
+		{"foreign currency", "", "JPY", ErrInvalidCurrency},
+		{"configured base", "USD", "USD", nil},
+		{"default base after configuring another", "USD", "BRL", ErrInvalidCurrency},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewFraudDetectionService(NewInMemoryProfileStore(), NewInMemoryBlacklistStore())
+			if tt.base != "" {
+				if err := service.SetBaseCurrency(tt.base); err != nil {
+					t.Fatalf("SetBaseCurrency: %v", err)
+				}
+			}
+			amount := models.NewMoney(1000000, tt.currency)
+			transaction := &models.Transaction{Currency: tt.currency, Amount: amount}
+			if err := service.normalizeAmount(transaction); !errors.Is(err, tt.err) {
+				t.Fatalf("error = %v, want %v", err, tt.err)
+			}
+			if transaction.FXConversion != nil || transaction.AmountInBase() != amount {
+				t.Errorf("amount changed without rates: %+v", transaction.AmountInBase())
+			}
+		})
+	}
+}
+
+func TestRateTableMustMatchBaseCurrency(t *testing.T) {
+	service := NewFraudDetectionService(NewInMemoryProfileStore(), NewInMemoryBlacklistStore())
+	if err := service.SetBaseCurrency("USD"); err != nil {
+		t.Fatalf("SetBaseCurrency: %v", err)
+	}
+	if err := service.SetFXRates(testRates(t), 0); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("SetFXRates with a BRL table for USD base: error = %v, want %v", err, ErrInvalidCurrency)
+	}
+
+	if err := service.SetBaseCurrency("BRL"); err != nil {
+		t.Fatalf("SetBaseCurrency: %v", err)
+	}
+	if err := service.SetFXRates(testRates(t), 0); err != nil {
+		t.Fatalf("SetFXRates: %v", err)
+	}
+	if err := service.SetBaseCurrency("USD"); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("SetBaseCurrency with a BRL table loaded: error = %v, want %v", err, ErrInvalidCurrency)
+	}
+}
+
+func TestProfileInBaseCurrency(t *testing.T) {
+	service := NewFraudDetectionService(NewInMemoryProfileStore(), NewInMemoryBlacklistStore())
+	if err := service.SetFXRates(testRates(t), 0); err != nil {
+		t.Fatalf("SetFXRates: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		average models.Money
+		want    models.Money
+	}{
+		{"base currency", models.NewMoney(10000, "BRL"), models.NewMoney(10000, "BRL")},
+		{"converted", models.NewMoney(10000, "USD"), models.NewMoney(54200, "BRL")},
+		{"without rate is not compared", models.NewMoney(10000, "EUR"), models.NewMoney(0, "BRL")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := &models.UserProfile{UserID: "USER1", AvgTransactionValue: tt.average}
+			got := service.profileInBaseCurrency(profile, "BRL")
+			if got.AvgTransactionValue != tt.want {
+				t.Errorf("average = %+v, want %+v", got.AvgTransactionValue, tt.want)
+			}
+			if profile.AvgTransactionValue != tt.average {
+				t.Error("stored profile changed")
+			}
+		})
+	}
+}
+
+func TestStaleRateGoesToReview(t *testing.T) {
+	now := time.Date(2026, 1, 16, 12, 0, 0, 0, time.UTC)
+	service := NewFraudDetectionService(NewInMemoryProfileStore(), NewInMemoryBlacklistStore())
+	service.SetClock(ClockFunc(func() time.Time { return now }))
+	if err := service.SetFXRates(testRates(t), 0); err != nil {
+		t.Fatalf("SetFXRates: %v", err)
+	}
+
+	tests := []struct {
+		currency string
+		want     models.Decision
+	}{
+		{"USD", models.DecisionApproved},
+		{"ARS", models.DecisionReview},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.currency, func(t *testing.T) {
+			result, err := service.AnalyzeTransaction(context.Background(), &models.Transaction{
+				ID:        "tx-" + tt.currency,
+				UserID:    "USER-" + tt.currency,
+				Amount:    models.NewMoney(1000, tt.currency),
+				Currency:  tt.currency,
+				Timestamp: now,
+			})
+			if err != nil {
+				t.Fatalf("AnalyzeTransaction: %v", err)
+			}
+			if result.Decision != tt.want {
+				t.Errorf("decision = %s, want %s (reasons %v)", result.Decision, tt.want, result.Reasons)
+			}
+		})
+	}
+}
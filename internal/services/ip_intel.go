@@ -0,0 +1,62 @@
+package services
+
+import (
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// IPGeolocator resolve endereços IP para localização, ASN e organização
+type IPGeolocator interface {
+	Lookup(ip string) (*models.IPGeolocation, bool)
+}
+
+// IPReputationChecker classifica IPs e ASNs como anonimizadores; retorna nil
+// para IPs sem registro
+type IPReputationChecker interface {
+	Check(ip string, asn int) *models.IPReputation
+}
+
+// SetIPGeolocator passa a enriquecer as transações com a localização do IP
+// antes das regras; nil desativa o enriquecimento
+func (s *FraudDetectionService) SetIPGeolocator(geolocator IPGeolocator) {
+	s.enrichMu.Lock()
+	defer s.enrichMu.Unlock()
+	s.ipGeolocator = geolocator
+}
+
+// SetIPReputation passa a marcar conexões vindas de Tor, proxies, VPNs e
+// provedores de hospedagem; nil desativa a verificação
+func (s *FraudDetectionService) SetIPReputation(checker IPReputationChecker) {
+	s.enrichMu.Lock()
+	defer s.enrichMu.Unlock()
+	s.ipReputation = checker
+}
+
+// enrichIP preenche a localização e a reputação derivadas do IP. O país da
+// base prevalece sobre o informado pelo cliente.
+func (s *FraudDetectionService) enrichIP(transaction *models.Transaction) {
+	s.enrichMu.RLock()
+	geolocator := s.ipGeolocator
+	reputation := s.ipReputation
+	s.enrichMu.RUnlock()
+
+	ip := transaction.Location.IPAddress
+	if ip == "" {
+		return
+	}
+
+	if geolocator != nil {
+		if location, found := geolocator.Lookup(ip); found {
+			transaction.IPGeolocation = location
+			transaction.IPCountry = location.Country
+		}
+	}
+
+	// O ASN da geolocalização permite reconhecer VPNs e provedores de hospedagem
+	if reputation != nil {
+		asn := 0
+		if transaction.IPGeolocation != nil {
+			asn = transaction.IPGeolocation.ASN
+		}
+		transaction.IPReputation = reputation.Check(ip, asn)
+	}
+}
@@ -0,0 +1,302 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+	"github.com/anti-fraud-golang/internal/rules"
+	"github.com/google/uuid"
+)
+
+// Erros do PIX
+var (
+	ErrInvalidPIX       = errors.New("invalid pix transfer")
+	ErrInvalidPIXLimits = errors.New("invalid pix limits")
+	ErrInvalidMuleFlag  = errors.New("invalid mule flag")
+	ErrMuleFlagNotFound = errors.New("mule flag not found")
+)
+
+// PIXLimitIncreaseDelay prazo mínimo para um aumento de limite PIX entrar em
+// vigor (Resolução BCB nº 142/2021); reduções valem na hora
+const PIXLimitIncreaseDelay = 24 * time.Hour
+
+// maxKnownPIXKeys número máximo de chaves PIX conhecidas por perfil
+const maxKnownPIXKeys = 100
+
+// validatePIX exige transferência em reais, tipo e chave válidos no formato do
+// DICT e conta do recebedor completa, quando informada
+func validatePIX(transaction *models.Transaction) error {
+	pix := transaction.PIX
+	if pix == nil {
+		return nil
+	}
+	if transaction.Currency != "BRL" {
+		return fmt.Errorf("%w: pix transfers must be in BRL, got %s", ErrInvalidPIX, transaction.Currency)
+	}
+
+	pix.KeyType = strings.ToUpper(strings.TrimSpace(pix.KeyType))
+	key, ok := models.NormalizePIXKey(pix.KeyType, pix.Key)
+	if !ok {
+		return fmt.Errorf("%w: invalid %s key", ErrInvalidPIX, strings.ToLower(pix.KeyType))
+	}
+	pix.Key = key
+
+	if pix.KeyCreatedAt != nil && pix.KeyCreatedAt.After(transaction.Timestamp) {
+		return fmt.Errorf("%w: key_created_at after the transfer", ErrInvalidPIX)
+	}
+
+	if pix.RecipientISPB != "" || pix.RecipientBranch != "" || pix.RecipientAccount != "" {
+		if len(pix.RecipientISPB) != 8 || !isNumeric(pix.RecipientISPB) {
+			return fmt.Errorf("%w: recipient_ispb must have 8 digits", ErrInvalidPIX)
+		}
+		if pix.RecipientAccount == "" {
+			return fmt.Errorf("%w: recipient_account is required with recipient_ispb", ErrInvalidPIX)
+		}
+	}
+	return nil
+}
+
+func isNumeric(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// GetPIXLimits retorna os limites PIX do usuário, ou os padrões se ele nunca
+// configurou os seus. Aumentos já liberados aparecem aplicados.
+func (s *FraudDetectionService) GetPIXLimits(userID string) (*models.PIXLimits, error) {
+	stored, err := s.pixLimitStore.Get(userID)
+	if err != nil {
+		return nil, storeFailure(err)
+	}
+
+	limits := models.DefaultPIXLimits(userID)
+	if stored != nil {
+		limits = *stored
+	}
+	limits = limits.At(s.now())
+	return &limits, nil
+}
+
+// SetPIXLimits configura os limites PIX do usuário. Reduções e a antecipação
+// do início do período noturno valem na hora; aumentos e o adiamento para as
+// 22h ficam pendentes até PIXLimitIncreaseDelay depois do pedido. Limite zero
+// remove o limite do período.
+func (s *FraudDetectionService) SetPIXLimits(requested *models.PIXLimits) (*models.PIXLimits, error) {
+	if requested.UserID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalidPIXLimits)
+	}
+	if requested.Daytime.Minor < 0 || requested.Nighttime.Minor < 0 {
+		return nil, fmt.Errorf("%w: limits must not be negative", ErrInvalidPIXLimits)
+	}
+	if requested.NightStart != 20 && requested.NightStart != 22 {
+		return nil, fmt.Errorf("%w: night_start_hour must be 20 or 22", ErrInvalidPIXLimits)
+	}
+	if requested.Nighttime.Minor == 0 || (requested.Daytime.Minor > 0 && requested.Nighttime.Minor > requested.Daytime.Minor) {
+		return nil, fmt.Errorf("%w: nighttime limit must be set and not above the daytime limit", ErrInvalidPIXLimits)
+	}
+
+	s.pixLimitMu.Lock()
+	defer s.pixLimitMu.Unlock()
+
+	current, err := s.GetPIXLimits(requested.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	limits := &models.PIXLimits{
+		UserID:     requested.UserID,
+		Daytime:    lowerLimit(current.Daytime, requested.Daytime),
+		Nighttime:  lowerLimit(current.Nighttime, requested.Nighttime),
+		NightStart: current.NightStart,
+		UpdatedAt:  now,
+	}
+	if requested.NightStart < current.NightStart {
+		limits.NightStart = requested.NightStart
+	}
+
+	// Qualquer aumento pedido aguarda o prazo; o pedido substitui um pendente
+	if limits.Daytime.Minor != requested.Daytime.Minor || limits.Nighttime.Minor != requested.Nighttime.Minor ||
+		limits.NightStart != requested.NightStart {
+		limits.Pending = &models.PIXLimitChange{
+			Daytime:     requested.Daytime,
+			Nighttime:   requested.Nighttime,
+			NightStart:  requested.NightStart,
+			RequestedAt: now,
+			EffectiveAt: now.Add(PIXLimitIncreaseDelay),
+		}
+	}
+
+	if err := s.pixLimitStore.Save(limits); err != nil {
+		return nil, storeFailure(err)
+	}
+	return limits, nil
+}
+
+// lowerLimit o mais restritivo entre dois limites, sendo zero ausência de limite
+func lowerLimit(current, requested models.Money) models.Money {
+	if current.Minor == 0 {
+		return requested
+	}
+	if requested.Minor == 0 || requested.Minor > current.Minor {
+		return current
+	}
+	return requested
+}
+
+// FlagMule marca a chave (com key_type e key) ou a conta (com recipient_ispb,
+// recipient_branch e recipient_account) do recebedor como laranja
+func (s *FraudDetectionService) FlagMule(flagType string, target models.PIXTransfer, reason string) (*models.MuleFlag, error) {
+	flag := &models.MuleFlag{
+		ID:        "MULE-" + uuid.New().String(),
+		Type:      flagType,
+		Reason:    strings.TrimSpace(reason),
+		FlaggedAt: s.now(),
+	}
+
+	switch flagType {
+	case models.MuleFlagKey:
+		target.KeyType = strings.ToUpper(strings.TrimSpace(target.KeyType))
+		key, ok := models.NormalizePIXKey(target.KeyType, target.Key)
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid %s key", ErrInvalidMuleFlag, strings.ToLower(target.KeyType))
+		}
+		target.Key = key
+		flag.Value = target.KeyID()
+	case models.MuleFlagAccount:
+		if len(target.RecipientISPB) != 8 || !isNumeric(target.RecipientISPB) || target.RecipientAccount == "" {
+			return nil, fmt.Errorf("%w: recipient_ispb (8 digits) and recipient_account are required", ErrInvalidMuleFlag)
+		}
+		flag.Value = target.AccountID()
+	default:
+		return nil, fmt.Errorf("%w: type must be %q or %q", ErrInvalidMuleFlag, models.MuleFlagKey, models.MuleFlagAccount)
+	}
+
+	if err := s.muleStore.Add(flag); err != nil {
+		return nil, storeFailure(err)
+	}
+	return flag, nil
+}
+
+// ListMules retorna as chaves e contas marcadas como laranjas
+func (s *FraudDetectionService) ListMules() ([]models.MuleFlag, error) {
+	flags, err := s.muleStore.List()
+	if err != nil {
+		return nil, storeFailure(err)
+	}
+	return flags, nil
+}
+
+// UnflagMule remove uma marcação de conta laranja
+func (s *FraudDetectionService) UnflagMule(id string) error {
+	if err := s.muleStore.Delete(id); err != nil {
+		if errors.Is(err, ErrMuleFlagNotFound) {
+			return err
+		}
+		return storeFailure(err)
+	}
+	return nil
+}
+
+// reservePIX reserva o valor da transferência no limite do período em vigor;
+// false indica que outra transferência já consumiu o limite
+func (s *FraudDetectionService) reservePIX(ctx context.Context, transaction *models.Transaction) (bool, error) {
+	pix := &guardedPIX{service: s}
+	stored, err := pix.GetPIXLimits(ctx, transaction.UserID)
+	if err != nil {
+		return false, err
+	}
+	limits := models.DefaultPIXLimits(transaction.UserID)
+	if stored != nil {
+		limits = *stored
+	}
+	from, to, _, limit := limits.Window(transaction)
+
+	var reserved bool
+	err = pix.call(ctx, DependencyPIXUsage, func() error {
+		var err error
+		reserved, err = s.pixUsageStore.Reserve(transaction, from, to, limit)
+		return err
+	})
+	return reserved, err
+}
+
+// pixLimitUnverified indica se a regra de limite PIX não chegou a verificar o
+// limite, por falha dos stores ou por não concluir no prazo
+func pixLimitUnverified(evaluation rules.Evaluation) bool {
+	return containsString(evaluation.TimedOut, rules.PIXLimitRuleID) ||
+		containsString(evaluation.Errored, rules.PIXLimitRuleID)
+}
+
+// learnPIXKey registra a chave de uma transferência aprovada como conhecida
+func learnPIXKey(profile *models.UserProfile, pix *models.PIXTransfer) {
+	if pix == nil {
+		return
+	}
+	key := pix.KeyID()
+	if key != "" && !containsString(profile.KnownPIXKeys, key) && len(profile.KnownPIXKeys) < maxKnownPIXKeys {
+		profile.KnownPIXKeys = append(profile.KnownPIXKeys, key)
+	}
+}
+
+// guardedPIX expõe os stores do PIX às regras com retry e circuit breaker,
+// cada um com a sua política de degradação
+type guardedPIX struct {
+	service *FraudDetectionService
+}
+
+func (p *guardedPIX) GetPIXLimits(ctx context.Context, userID string) (*models.PIXLimits, error) {
+	var limits *models.PIXLimits
+	err := p.call(ctx, DependencyPIXLimits, func() error {
+		var err error
+		limits, err = p.service.pixLimitStore.Get(userID)
+		return err
+	})
+	return limits, err
+}
+
+func (p *guardedPIX) GetPIXUsage(ctx context.Context, userID string, from, to time.Time) (*models.PIXUsage, error) {
+	var usage *models.PIXUsage
+	err := p.call(ctx, DependencyPIXUsage, func() error {
+		var err error
+		usage, err = p.service.pixUsageStore.GetPIXUsage(userID, from, to)
+		return err
+	})
+	return usage, err
+}
+
+// GetMuleFlag consulta a chave e, se informada, a conta do recebedor
+func (p *guardedPIX) GetMuleFlag(ctx context.Context, pix *models.PIXTransfer) (*models.MuleFlag, error) {
+	var flag *models.MuleFlag
+	err := p.call(ctx, DependencyMules, func() error {
+		var err error
+		flag, err = p.service.muleStore.Get(models.MuleFlagKey, pix.KeyID())
+		if err != nil || flag != nil || pix.AccountID() == "" {
+			return err
+		}
+		flag, err = p.service.muleStore.Get(models.MuleFlagAccount, pix.AccountID())
+		return err
+	})
+	return flag, err
+}
+
+// call executa a consulta com a política da dependência e registra a falha
+// na degradação da análise em curso
+func (p *guardedPIX) call(ctx context.Context, dependency string, fn func() error) error {
+	guard := p.service.guard(dependency)
+	err := guard.call(ctx, fn)
+	if err != nil {
+		if d := degradationFrom(ctx); d != nil {
+			d.record(guard, err)
+		}
+	}
+	return err
+}
@@ -0,0 +1,267 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+	"github.com/anti-fraud-golang/internal/rules"
+)
+
+// unavailablePIXLimitStore simula o store de limites PIX fora do ar
+type unavailablePIXLimitStore struct {
+	*InMemoryPIXLimitStore
+}
+
+func (s unavailablePIXLimitStore) Get(userID string) (*models.PIXLimits, error) {
+	return nil, errors.New("connection refused")
+}
+
+// unavailablePIXUsageReader simula falha na leitura do total transferido,
+// com a reserva ainda funcionando
+type unavailablePIXUsageReader struct {
+	*InMemoryPIXUsageStore
+}
+
+func (s unavailablePIXUsageReader) GetPIXUsage(userID string, from, to time.Time) (*models.PIXUsage, error) {
+	return nil, errors.New("connection refused")
+}
+
+func pixTransaction(id string, amount int64, at time.Time) *models.Transaction {
+	return &models.Transaction{
+		ID:        id,
+		UserID:    "USER1",
+		Amount:    models.NewMoney(amount, "BRL"),
+		Currency:  "BRL",
+		Timestamp: at,
+		Location:  models.Location{Country: "BR"},
+		PIX:       &models.PIXTransfer{KeyType: models.PIXKeyEmail, Key: "fulano@exemplo.com"},
+	}
+}
+
+func newPIXTestService(t *testing.T, now *time.Time, daytime int64) *FraudDetectionService {
+	t.Helper()
+	service := NewFraudDetectionService(NewInMemoryProfileStore(), NewInMemoryBlacklistStore())
+	service.SetClock(ClockFunc(func() time.Time { return *now }))
+	if daytime > 0 {
+		if _, err := service.SetPIXLimits(&models.PIXLimits{
+			UserID:     "USER1",
+			Daytime:    models.NewMoney(daytime, "BRL"),
+			Nighttime:  models.DefaultPIXNightLimit,
+			NightStart: models.DefaultPIXNightStart,
+		}); err != nil {
+			t.Fatalf("SetPIXLimits: %v", err)
+		}
+	}
+	return service
+}
+
+func TestSetPIXLimitsDelaysIncreases(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	service := newPIXTestService(t, &now, 0)
+
+	// Sem limite diurno qualquer valor é redução; o adiamento para as 22h espera o prazo
+	limits, err := service.SetPIXLimits(&models.PIXLimits{
+		UserID:     "USER1",
+		Daytime:    models.NewMoney(500000, "BRL"),
+		Nighttime:  models.NewMoney(50000, "BRL"),
+		NightStart: 22,
+	})
+	if err != nil {
+		t.Fatalf("SetPIXLimits: %v", err)
+	}
+	if limits.Daytime.Minor != 500000 || limits.Nighttime.Minor != 50000 || limits.NightStart != 20 {
+		t.Errorf("applied %s/%s from %dh, want 5000.00/500.00 from 20h", limits.Daytime, limits.Nighttime, limits.NightStart)
+	}
+	if limits.Pending == nil || !limits.Pending.EffectiveAt.Equal(now.Add(PIXLimitIncreaseDelay)) {
+		t.Fatalf("pending = %+v, want night start change effective in %s", limits.Pending, PIXLimitIncreaseDelay)
+	}
+
+	if _, err := service.SetPIXLimits(&models.PIXLimits{
+		UserID:     "USER1",
+		Daytime:    models.NewMoney(200000, "BRL"),
+		Nighttime:  models.NewMoney(100000, "BRL"),
+		NightStart: 20,
+	}); err != nil {
+		t.Fatalf("SetPIXLimits: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		after     time.Duration
+		daytime   int64
+		nighttime int64
+	}{
+		{"reduction applies immediately", 0, 200000, 50000},
+		{"increase waits for the delay", PIXLimitIncreaseDelay - time.Minute, 200000, 50000},
+		{"increase applies after the delay", PIXLimitIncreaseDelay, 200000, 100000},
+	}
+
+	start := now
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now = start.Add(tt.after)
+			got, err := service.GetPIXLimits("USER1")
+			if err != nil {
+				t.Fatalf("GetPIXLimits: %v", err)
+			}
+			if got.Daytime.Minor != tt.daytime || got.Nighttime.Minor != tt.nighttime {
+				t.Errorf("limits = %s/%s, want %d/%d", got.Daytime, got.Nighttime, tt.daytime, tt.nighttime)
+			}
+		})
+	}
+}
+
+func TestPIXLimitEnforcement(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)  // 12h em Brasília
+	night := time.Date(2026, 3, 11, 1, 0, 0, 0, time.UTC) // 22h em Brasília
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		amounts []int64
+		blocked []bool
+	}{
+		{"within the daytime limit", now, []int64{60000, 40000}, []bool{false, false}},
+		{"above the daytime limit", now, []int64{60000, 50000}, []bool{false, true}},
+		{"blocked transfer does not consume the limit", now, []int64{60000, 50000, 40000}, []bool{false, true, false}},
+		{"nighttime limit is separate", night, []int64{90000, 20000}, []bool{false, true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := tt.at
+			service := newPIXTestService(t, &clock, 100000)
+
+			for i, amount := range tt.amounts {
+				result, err := service.AnalyzeTransaction(context.Background(), pixTransaction(fmt.Sprintf("tx-%d", i), amount, tt.at))
+				if err != nil {
+					t.Fatalf("AnalyzeTransaction: %v", err)
+				}
+				if blocked := result.Decision == models.DecisionBlocked; blocked != tt.blocked[i] {
+					t.Errorf("transfer %d: decision = %s, want blocked = %v", i, result.Decision, tt.blocked[i])
+				}
+			}
+		})
+	}
+}
+
+func TestConcurrentPIXTransfersRespectLimit(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	service := newPIXTestService(t, &now, 100000)
+
+	var wg sync.WaitGroup
+	decisions := make([]models.Decision, 10)
+	for i := range decisions {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			result, err := service.AnalyzeTransaction(context.Background(), pixTransaction(fmt.Sprintf("tx-%d", i), 30000, now))
+			if err != nil {
+				t.Errorf("AnalyzeTransaction: %v", err)
+				return
+			}
+			decisions[i] = result.Decision
+		}(i)
+	}
+	wg.Wait()
+
+	released := 0
+	for _, decision := range decisions {
+		if decision != models.DecisionBlocked {
+			released++
+		}
+	}
+	usage, err := service.pixUsageStore.GetPIXUsage("USER1", now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetPIXUsage: %v", err)
+	}
+	if usage.Total.Minor > 100000 {
+		t.Errorf("released %s, above the R$ 1000.00 limit", usage.Total)
+	}
+	if released != usage.Count || released != 3 {
+		t.Errorf("released %d transfers with %d recorded, want 3", released, usage.Count)
+	}
+}
+
+func TestPIXLimitFailsClosed(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		setup func(service *FraudDetectionService)
+	}{
+		{"limit store unavailable", func(service *FraudDetectionService) {
+			service.pixLimitStore = unavailablePIXLimitStore{NewInMemoryPIXLimitStore()}
+		}},
+		{"usage store unavailable", func(service *FraudDetectionService) {
+			service.pixUsageStore = unavailablePIXUsageReader{NewInMemoryPIXUsageStore()}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newPIXTestService(t, &now, 0)
+			tt.setup(service)
+
+			result, err := service.AnalyzeTransaction(context.Background(), pixTransaction("tx-1", 10000, now))
+			if err != nil {
+				t.Fatalf("AnalyzeTransaction: %v", err)
+			}
+			if result.Decision != models.DecisionReview {
+				t.Errorf("decision = %s, want %s", result.Decision, models.DecisionReview)
+			}
+			if !result.Degraded {
+				t.Error("analysis not marked as degraded")
+			}
+			if containsString(result.TriggeredRuleIDs, rules.PIXLimitRuleID) {
+				t.Errorf("%s triggered without a limit", rules.PIXLimitRuleID)
+			}
+		})
+	}
+}
+
+func TestReservePIXUsage(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	from, to := now.Add(-time.Hour), now.Add(time.Hour)
+	limit := models.NewMoney(100000, "BRL")
+
+	tests := []struct {
+		name     string
+		id       string
+		amount   int64
+		limit    models.Money
+		reserved bool
+		total    int64
+	}{
+		{"within the limit", "tx-1", 60000, limit, true, 60000},
+		{"same transaction again", "tx-1", 60000, limit, true, 60000},
+		{"above the limit", "tx-2", 50000, limit, false, 60000},
+		{"up to the limit", "tx-3", 40000, limit, true, 100000},
+		{"without limit", "tx-4", 50000, models.NewMoney(0, "BRL"), true, 150000},
+	}
+
+	store := NewInMemoryPIXUsageStore()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reserved, err := store.Reserve(pixTransaction(tt.id, tt.amount, now), from, to, tt.limit)
+			if err != nil {
+				t.Fatalf("Reserve: %v", err)
+			}
+			if reserved != tt.reserved {
+				t.Errorf("reserved = %v, want %v", reserved, tt.reserved)
+			}
+			usage, err := store.GetPIXUsage("USER1", from, to)
+			if err != nil {
+				t.Fatalf("GetPIXUsage: %v", err)
+			}
+			if usage.Total.Minor != tt.total {
+				t.Errorf("total = %s, want %d", usage.Total, tt.total)
+			}
+		})
+	}
+}
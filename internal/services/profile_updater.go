@@ -0,0 +1,199 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// Limites das listas mantidas no perfil
+const (
+	maxCommonLocations = 20
+	maxCommonMerchants = 20
+	maxKnownCards      = 20
+	maxTrustedDevices  = 20
+	maxDeviceApprovals = 20
+)
+
+// DefaultDeviceTrustThreshold aprovações necessárias para um dispositivo se
+// tornar confiável
+const DefaultDeviceTrustThreshold = 3
+
+// SetDeviceTrustThreshold define quantas transações aprovadas promovem um
+// dispositivo a confiável no perfil do usuário
+func (s *FraudDetectionService) SetDeviceTrustThreshold(approvals int) error {
+	if approvals < 1 {
+		return fmt.Errorf("device trust threshold deve ser positivo, recebido %d", approvals)
+	}
+
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+	s.deviceTrustThreshold = approvals
+	return nil
+}
+
+// updateProfile incorpora uma transação aprovada ao perfil do usuário.
+// O perfil armazenado nunca é alterado no lugar: uma cópia é atualizada e
+// gravada, para que análises concorrentes continuem lendo um perfil consistente.
+func (s *FraudDetectionService) updateProfile(transaction *models.Transaction) error {
+	s.profileMu.Lock()
+	defer s.profileMu.Unlock()
+
+	// Só um perfil inexistente é recriado; outras falhas não podem sobrescrever o histórico
+	current, err := s.profileStore.GetUserProfile(transaction.UserID)
+	if err != nil {
+		if !errors.Is(err, ErrProfileNotFound) {
+			return err
+		}
+		current = nil
+	}
+
+	var profile *models.UserProfile
+	if current == nil {
+		profile = &models.UserProfile{
+			UserID:             transaction.UserID,
+			FirstTransactionAt: transaction.Timestamp,
+			CommonLocations:    []models.Location{},
+			CommonMerchants:    []string{},
+			FraudHistory:       []models.FraudIncident{},
+			TrustedDevices:     []string{},
+			KnownCards:         []string{},
+		}
+	} else {
+		profile = cloneProfile(current)
+	}
+
+	profile.AvgTransactionValue = s.averageAmount(profile.AvgTransactionValue, profile.TotalTransactions, transaction.AmountInBase())
+	profile.TotalTransactions++
+
+	if transaction.Timestamp.After(profile.LastTransactionAt) {
+		profile.LastTransactionAt = transaction.Timestamp
+	}
+	if profile.FirstTransactionAt.IsZero() || transaction.Timestamp.Before(profile.FirstTransactionAt) {
+		profile.FirstTransactionAt = transaction.Timestamp
+	}
+
+	// Eventos fora de ordem não substituem a última posição conhecida
+	if transaction.Location.HasCoordinates() && !transaction.Timestamp.Before(profile.LastLocationAt) {
+		location := transaction.Location
+		location.IPAddress = ""
+		profile.LastLocation = &location
+		profile.LastLocationAt = transaction.Timestamp
+	}
+
+	if transaction.Merchant != "" && !containsString(profile.CommonMerchants, transaction.Merchant) &&
+		len(profile.CommonMerchants) < maxCommonMerchants {
+		profile.CommonMerchants = append(profile.CommonMerchants, transaction.Merchant)
+	}
+
+	if transaction.CardFingerprint != "" && !containsString(profile.KnownCards, transaction.CardFingerprint) &&
+		len(profile.KnownCards) < maxKnownCards {
+		profile.KnownCards = append(profile.KnownCards, transaction.CardFingerprint)
+	}
+
+	s.learnDevice(profile, transaction.DeviceInfo)
+	learnPIXKey(profile, transaction.PIX)
+
+	if !containsLocation(profile.CommonLocations, transaction.Location) &&
+		len(profile.CommonLocations) < maxCommonLocations {
+		location := transaction.Location
+		location.IPAddress = ""
+		profile.CommonLocations = append(profile.CommonLocations, location)
+	}
+
+	if profile.Baseline == nil {
+		profile.Baseline = models.NewBehaviorBaseline()
+	}
+	profile.Baseline.Observe(transaction)
+
+	return s.profileStore.UpdateUserProfile(profile)
+}
+
+// averageAmount incorpora o valor à média de count transações, em unidades
+// menores da moeda base. Médias gravadas em outra moeda são convertidas antes;
+// sem cotação para elas, a média recomeça do valor.
+func (s *FraudDetectionService) averageAmount(average models.Money, count int, amount models.Money) models.Money {
+	average, ok := s.inBaseCurrency(average, amount.Currency)
+	if count <= 0 || !ok {
+		return amount
+	}
+
+	// Arredonda para a unidade menor mais próxima
+	n := int64(count) + 1
+	sum := average.Minor*int64(count) + amount.Minor
+	minor := (sum + n/2) / n
+	if sum < 0 {
+		minor = (sum - n/2) / n
+	}
+	return models.NewMoney(minor, amount.Currency)
+}
+
+// cloneProfile cria uma cópia independente de um perfil
+func cloneProfile(profile *models.UserProfile) *models.UserProfile {
+	clone := *profile
+	clone.CommonLocations = append([]models.Location(nil), profile.CommonLocations...)
+	clone.CommonMerchants = append([]string(nil), profile.CommonMerchants...)
+	clone.FraudHistory = append([]models.FraudIncident(nil), profile.FraudHistory...)
+	if profile.LastLocation != nil {
+		location := *profile.LastLocation
+		clone.LastLocation = &location
+	}
+	clone.TrustedDevices = append([]string(nil), profile.TrustedDevices...)
+	if profile.DeviceApprovals != nil {
+		clone.DeviceApprovals = make(map[string]int, len(profile.DeviceApprovals))
+		for device, approvals := range profile.DeviceApprovals {
+			clone.DeviceApprovals[device] = approvals
+		}
+	}
+	clone.KnownCards = append([]string(nil), profile.KnownCards...)
+	clone.KnownPIXKeys = append([]string(nil), profile.KnownPIXKeys...)
+	if profile.Baseline != nil {
+		clone.Baseline = profile.Baseline.Clone()
+	}
+	return &clone
+}
+
+// learnDevice conta uma transação aprovada para o dispositivo e o promove a
+// confiável ao atingir o limite
+func (s *FraudDetectionService) learnDevice(profile *models.UserProfile, device models.DeviceInfo) {
+	key := device.Key()
+	if key == "" || containsString(profile.TrustedDevices, key) {
+		return
+	}
+
+	if profile.DeviceApprovals == nil {
+		profile.DeviceApprovals = make(map[string]int)
+	}
+	if _, seen := profile.DeviceApprovals[key]; !seen && len(profile.DeviceApprovals) >= maxDeviceApprovals {
+		return
+	}
+	profile.DeviceApprovals[key]++
+
+	threshold := s.deviceTrustThreshold
+	if threshold < 1 {
+		threshold = DefaultDeviceTrustThreshold
+	}
+	if profile.DeviceApprovals[key] >= threshold && len(profile.TrustedDevices) < maxTrustedDevices {
+		profile.TrustedDevices = append(profile.TrustedDevices, key)
+		delete(profile.DeviceApprovals, key)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func containsLocation(locations []models.Location, location models.Location) bool {
+	for _, l := range locations {
+		if l.Country == location.Country && l.City == location.City {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+	"github.com/anti-fraud-golang/internal/rules"
+)
+
+func TestOnlyApprovedTransactionsFeedTheProfile(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	profiles := NewInMemoryProfileStore()
+	service := NewFraudDetectionService(profiles, NewInMemoryBlacklistStore())
+	service.SetClock(ClockFunc(func() time.Time { return now }))
+
+	// Valores acima de R$ 500 vão para revisão
+	config := service.RuleConfig()
+	config.Expressions = append(config.Expressions, rules.ExpressionRuleConfig{ID: "review_large", Weight: 1, Expression: "tx.amount > 500"})
+	config.Vetoes = append(config.Vetoes, rules.VetoConfig{RuleID: "review_large", Decision: models.DecisionReview})
+	if _, err := service.ApplyRuleConfig(config, RuleChange{Author: "test"}); err != nil {
+		t.Fatalf("ApplyRuleConfig: %v", err)
+	}
+
+	tests := []struct {
+		amount   int64
+		merchant string
+		decision models.Decision
+	}{
+		{10000, "Padaria", models.DecisionApproved},
+		{90000, "Eletrônicos", models.DecisionReview},
+		{90000, "Joalheria", models.DecisionReview},
+	}
+	for i, tt := range tests {
+		result, err := service.AnalyzeTransaction(context.Background(), &models.Transaction{
+			ID:        "tx-" + tt.merchant,
+			UserID:    "USER1",
+			Amount:    models.NewMoney(tt.amount, "BRL"),
+			Currency:  "BRL",
+			Merchant:  tt.merchant,
+			Timestamp: now.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("AnalyzeTransaction: %v", err)
+		}
+		if result.Decision != tt.decision {
+			t.Fatalf("%s: decision = %s, want %s", tt.merchant, result.Decision, tt.decision)
+		}
+	}
+
+	profile, err := profiles.GetUserProfile("USER1")
+	if err != nil {
+		t.Fatalf("GetUserProfile: %v", err)
+	}
+	if profile.TotalTransactions != 1 || profile.AvgTransactionValue != models.NewMoney(10000, "BRL") {
+		t.Errorf("profile has %d transactions averaging %s, want only the approved one", profile.TotalTransactions, profile.AvgTransactionValue)
+	}
+	if len(profile.CommonMerchants) != 1 || profile.Baseline == nil || profile.Baseline.Count != 1 {
+		t.Errorf("merchants %v and baseline learned from transactions under review", profile.CommonMerchants)
+	}
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/ml"
+	"github.com/anti-fraud-golang/internal/models"
+	"github.com/anti-fraud-golang/internal/rules"
+)
+
+// ErrRuleNotFound regra não encontrada na configuração
+var ErrRuleNotFound = errors.New("rule not found")
+
+// ErrVersionNotFound versão de configuração não encontrada
+var ErrVersionNotFound = errors.New("rule set version not found")
+
+// RuleChange autoria de uma alteração na configuração de regras
+type RuleChange struct {
+	Author  string
+	Comment string
+}
+
+// RuleSetVersion versão imutável da configuração de regras
+type RuleSetVersion struct {
+	Version        int                  `json:"version"`
+	Author         string               `json:"author"`
+	Comment        string               `json:"comment,omitempty"`
+	CreatedAt      time.Time            `json:"created_at"`
+	RolledBackFrom int                  `json:"rolled_back_from,omitempty"`
+	ModelVersion   string               `json:"model_version,omitempty"`
+	Changes        []rules.ConfigChange `json:"changes"`
+	Config         rules.EngineConfig   `json:"config"`
+}
+
+// engine retorna o motor de regras em uso. Cada análise obtém o motor uma
+// única vez, de modo que uma troca de configuração nunca afeta uma análise em curso.
+func (s *FraudDetectionService) engine() *rules.RuleEngine {
+	s.engineMu.RLock()
+	defer s.engineMu.RUnlock()
+
+	return s.ruleEngine
+}
+
+// ApplyRuleConfig valida a configuração, registra uma nova versão e troca o
+// motor de regras atomicamente
+func (s *FraudDetectionService) ApplyRuleConfig(config rules.EngineConfig, change RuleChange) (*RuleSetVersion, error) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	return s.commitRuleConfig(config, change, 0)
+}
+
+// commitRuleConfig constrói o novo motor, grava a versão e só então troca o
+// motor em uso. Deve ser chamado com configMu travado.
+func (s *FraudDetectionService) commitRuleConfig(config rules.EngineConfig, change RuleChange, rolledBackFrom int) (*RuleSetVersion, error) {
+	engine, err := rules.NewRuleEngineFromConfig(config, s.dependencies())
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := rules.DiffConfigs(s.RuleConfig(), config)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := s.ruleVersionStore.Latest()
+	if err != nil {
+		return nil, err
+	}
+	number := 1
+	if latest != nil {
+		number = latest.Version + 1
+	}
+
+	author := change.Author
+	if author == "" {
+		author = "anonymous"
+	}
+
+	version := &RuleSetVersion{
+		Version:        number,
+		Author:         author,
+		Comment:        change.Comment,
+		CreatedAt:      s.now(),
+		RolledBackFrom: rolledBackFrom,
+		Changes:        changes,
+		Config:         copyEngineConfig(config),
+	}
+	if s.model != nil {
+		version.ModelVersion = s.model.Version
+	}
+
+	if err := s.ruleVersionStore.Append(version); err != nil {
+		return nil, err
+	}
+	engine.SetVersion(version.Version)
+
+	s.engineMu.Lock()
+	s.ruleEngine = engine
+	s.engineConfig = copyEngineConfig(config)
+	s.engineMu.Unlock()
+
+	return version, nil
+}
+
+// SetModel passa a pontuar as transações com o modelo treinado pelo cmd/train
+func (s *FraudDetectionService) SetModel(model *ml.Model) error {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	previous := s.model
+	s.model = model
+
+	_, err := s.commitRuleConfig(s.RuleConfig(), RuleChange{
+		Author:  "system",
+		Comment: "modelo " + model.Version + " carregado",
+	}, 0)
+	if err != nil {
+		s.model = previous
+		return err
+	}
+
+	return nil
+}
+
+// dependencies monta as fontes de dados consultadas pelas regras.
+// Deve ser chamado com configMu travado.
+func (s *FraudDetectionService) dependencies() rules.Dependencies {
+	return rules.Dependencies{
+		PeerStats:   s.peerGroupStore,
+		Velocity:    &guardedVelocity{service: s},
+		BINVelocity: &guardedVelocity{service: s},
+		DeviceUsage: &guardedVelocity{service: s},
+		PIXLimits:   &guardedPIX{service: s},
+		PIXUsage:    &guardedPIX{service: s},
+		Mules:       &guardedPIX{service: s},
+		Model:       s.model,
+	}
+}
+
+// RuleConfig retorna uma cópia da configuração do motor em uso
+func (s *FraudDetectionService) RuleConfig() rules.EngineConfig {
+	s.engineMu.RLock()
+	defer s.engineMu.RUnlock()
+
+	return copyEngineConfig(s.engineConfig)
+}
+
+// RuleSetVersion retorna a versão do conjunto de regras em uso
+func (s *FraudDetectionService) RuleSetVersion() int {
+	return s.engine().Version()
+}
+
+// ListRuleVersions lista o histórico de versões da configuração de regras
+func (s *FraudDetectionService) ListRuleVersions() ([]RuleSetVersion, error) {
+	return s.ruleVersionStore.List()
+}
+
+// GetRuleVersion retorna uma versão da configuração de regras
+func (s *FraudDetectionService) GetRuleVersion(version int) (*RuleSetVersion, error) {
+	return s.ruleVersionStore.Get(version)
+}
+
+// DiffRuleVersions compara a configuração de duas versões
+func (s *FraudDetectionService) DiffRuleVersions(from, to int) ([]rules.ConfigChange, error) {
+	before, err := s.ruleVersionStore.Get(from)
+	if err != nil {
+		return nil, err
+	}
+	after, err := s.ruleVersionStore.Get(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules.DiffConfigs(before.Config, after.Config)
+}
+
+// RollbackRuleConfig restaura a configuração de uma versão anterior. O
+// rollback gera uma nova versão, preservando o histórico.
+func (s *FraudDetectionService) RollbackRuleConfig(version int, change RuleChange) (*RuleSetVersion, error) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	target, err := s.ruleVersionStore.Get(version)
+	if err != nil {
+		return nil, err
+	}
+
+	if change.Comment == "" {
+		change.Comment = fmt.Sprintf("rollback para a versão %d", version)
+	}
+
+	return s.commitRuleConfig(target.Config, change, version)
+}
+
+// ValidateExpressionRule compila uma regra de expressão sem aplicá-la
+func (s *FraudDetectionService) ValidateExpressionRule(expression rules.ExpressionRuleConfig) error {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	_, err := rules.NewExpressionRule(expression, s.RuleConfig().Lists, s.dependencies())
+	return err
+}
+
+// SaveExpressionRule cria ou substitui uma regra de expressão e aplica a nova
+// configuração sem reiniciar o serviço
+func (s *FraudDetectionService) SaveExpressionRule(expression rules.ExpressionRuleConfig, change RuleChange) (*RuleSetVersion, error) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	config := s.RuleConfig()
+
+	replaced := false
+	for i := range config.Expressions {
+		if config.Expressions[i].ID == expression.ID {
+			config.Expressions[i] = expression
+			replaced = true
+		}
+	}
+	if !replaced {
+		config.Expressions = append(config.Expressions, expression)
+	}
+
+	return s.commitRuleConfig(config, change, 0)
+}
+
+// DeleteExpressionRule remove uma regra de expressão da configuração
+func (s *FraudDetectionService) DeleteExpressionRule(id string, change RuleChange) (*RuleSetVersion, error) {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	config := s.RuleConfig()
+
+	kept := make([]rules.ExpressionRuleConfig, 0, len(config.Expressions))
+	for _, expression := range config.Expressions {
+		if expression.ID != id {
+			kept = append(kept, expression)
+		}
+	}
+	if len(kept) == len(config.Expressions) {
+		return nil, fmt.Errorf("%w: %s", ErrRuleNotFound, id)
+	}
+	config.Expressions = kept
+
+	return s.commitRuleConfig(config, change, 0)
+}
+
+// copyEngineConfig copia em profundidade listas, mapas e ponteiros da
+// configuração, para que versões gravadas e a configuração em uso não
+// compartilhem nada
+func copyEngineConfig(config rules.EngineConfig) rules.EngineConfig {
+	clone := config
+
+	aggregation := &clone.Aggregation
+	if config.Aggregation.BonusPerRule != nil {
+		bonus := *config.Aggregation.BonusPerRule
+		aggregation.BonusPerRule = &bonus
+	}
+	if config.Aggregation.Bias != nil {
+		bias := *config.Aggregation.Bias
+		aggregation.Bias = &bias
+	}
+	if config.Aggregation.DefaultWeight != nil {
+		weight := *config.Aggregation.DefaultWeight
+		aggregation.DefaultWeight = &weight
+	}
+	if config.Aggregation.Weights != nil {
+		aggregation.Weights = make(map[string]float64, len(config.Aggregation.Weights))
+		for ruleID, weight := range config.Aggregation.Weights {
+			aggregation.Weights[ruleID] = weight
+		}
+	}
+
+	clone.Vetoes = append([]rules.VetoConfig(nil), config.Vetoes...)
+
+	if config.Composites != nil {
+		clone.Composites = make([]rules.CompositeRuleConfig, len(config.Composites))
+		for i, composite := range config.Composites {
+			composite.Enabled = copyBool(composite.Enabled)
+			composite.Condition = copyCondition(composite.Condition)
+			clone.Composites[i] = composite
+		}
+	}
+
+	if config.Expressions != nil {
+		clone.Expressions = make([]rules.ExpressionRuleConfig, len(config.Expressions))
+		for i, expression := range config.Expressions {
+			expression.Enabled = copyBool(expression.Enabled)
+			clone.Expressions[i] = expression
+		}
+	}
+
+	if config.Lists != nil {
+		clone.Lists = make(map[string][]string, len(config.Lists))
+		for name, values := range config.Lists {
+			clone.Lists[name] = append([]string(nil), values...)
+		}
+	}
+
+	clone.Evaluation.Priorities = copyIntMap(config.Evaluation.Priorities)
+	clone.Evaluation.RuleTimeoutsMs = copyIntMap(config.Evaluation.RuleTimeoutsMs)
+
+	clone.Alerts.NightHourStart = copyInt(config.Alerts.NightHourStart)
+	clone.Alerts.NightHourEnd = copyInt(config.Alerts.NightHourEnd)
+	clone.Alerts.HighRiskCountries = append([]string(nil), config.Alerts.HighRiskCountries...)
+	clone.Alerts.AirportCities = append([]string(nil), config.Alerts.AirportCities...)
+	clone.Alerts.FlightRoutes = append([]models.FlightRoute(nil), config.Alerts.FlightRoutes...)
+
+	return clone
+}
+
+// copyCondition copia a árvore de condição de uma regra composta
+func copyCondition(condition rules.ConditionConfig) rules.ConditionConfig {
+	if condition.Children != nil {
+		children := make([]rules.ConditionConfig, len(condition.Children))
+		for i, child := range condition.Children {
+			children[i] = copyCondition(child)
+		}
+		condition.Children = children
+	}
+	return condition
+}
+
+func copyBool(value *bool) *bool {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+	return &clone
+}
+
+func copyInt(value *int) *int {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+	return &clone
+}
+
+func copyIntMap(values map[string]int) map[string]int {
+	if values == nil {
+		return nil
+	}
+	clone := make(map[string]int, len(values))
+	for key, value := range values {
+		clone[key] = value
+	}
+	return clone
+}
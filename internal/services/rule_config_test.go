@@ -0,0 +1,92 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/anti-fraud-golang/internal/models"
+	"github.com/anti-fraud-golang/internal/rules"
+)
+
+func TestCopyEngineConfigIsDeep(t *testing.T) {
+	bias, enabled, start := 0.5, true, 3
+	config := rules.EngineConfig{
+		Aggregation: rules.AggregationConfig{Bias: &bias, Weights: map[string]float64{"a": 1}},
+		Composites: []rules.CompositeRuleConfig{{
+			ID:        "c",
+			Enabled:   &enabled,
+			Condition: rules.ConditionConfig{Op: "and", Children: []rules.ConditionConfig{{Op: "rule", Rule: "a"}}},
+		}},
+		Expressions: []rules.ExpressionRuleConfig{{ID: "e", Enabled: &enabled}},
+		Lists:       map[string][]string{"l": {"x"}},
+		Evaluation: rules.EvaluationConfig{
+			Priorities:     map[string]int{"a": 1},
+			RuleTimeoutsMs: map[string]int{"a": 10},
+		},
+		Alerts: models.AlertConfig{
+			NightHourStart:    &start,
+			HighRiskCountries: []string{"KP"},
+			AirportCities:     []string{"Guarulhos"},
+			FlightRoutes:      []models.FlightRoute{{From: "GRU", To: "LIS"}},
+		},
+	}
+
+	clone := copyEngineConfig(config)
+
+	*clone.Aggregation.Bias = 2
+	clone.Aggregation.Weights["a"] = 3
+	*clone.Composites[0].Enabled = false
+	clone.Composites[0].Condition.Children[0].Rule = "b"
+	*clone.Expressions[0].Enabled = false
+	clone.Lists["l"][0] = "y"
+	clone.Evaluation.Priorities["a"] = 9
+	clone.Evaluation.RuleTimeoutsMs["a"] = 99
+	*clone.Alerts.NightHourStart = 0
+	clone.Alerts.HighRiskCountries[0] = "BR"
+	clone.Alerts.AirportCities[0] = "Lisboa"
+	clone.Alerts.FlightRoutes[0].To = "MAD"
+
+	switch {
+	case bias != 0.5, config.Aggregation.Weights["a"] != 1:
+		t.Error("aggregation shared with the copy")
+	case !enabled:
+		t.Error("enabled flags shared with the copy")
+	case config.Composites[0].Condition.Children[0].Rule != "a":
+		t.Error("composite condition shared with the copy")
+	case config.Lists["l"][0] != "x":
+		t.Error("lists shared with the copy")
+	case config.Evaluation.Priorities["a"] != 1, config.Evaluation.RuleTimeoutsMs["a"] != 10:
+		t.Error("evaluation maps shared with the copy")
+	case start != 3:
+		t.Error("night window shared with the copy")
+	case config.Alerts.HighRiskCountries[0] != "KP", config.Alerts.AirportCities[0] != "Guarulhos",
+		config.Alerts.FlightRoutes[0].To != "LIS":
+		t.Error("alert slices shared with the copy")
+	}
+}
+
+func TestRuleVersionsAreSnapshots(t *testing.T) {
+	service := NewFraudDetectionService(NewInMemoryProfileStore(), NewInMemoryBlacklistStore())
+
+	config := service.RuleConfig()
+	config.Evaluation.Priorities = map[string]int{"high_amount_rule": 5}
+	version, err := service.ApplyRuleConfig(config, RuleChange{Author: "test"})
+	if err != nil {
+		t.Fatalf("ApplyRuleConfig: %v", err)
+	}
+
+	// Alterar a configuração devolvida não pode mudar a versão gravada
+	live := service.RuleConfig()
+	live.Evaluation.Priorities["high_amount_rule"] = 50
+	config.Evaluation.Priorities["high_amount_rule"] = 60
+
+	stored, err := service.GetRuleVersion(version.Version)
+	if err != nil {
+		t.Fatalf("GetRuleVersion: %v", err)
+	}
+	if got := stored.Config.Evaluation.Priorities["high_amount_rule"]; got != 5 {
+		t.Errorf("stored priority = %d, want 5", got)
+	}
+	if got := service.RuleConfig().Evaluation.Priorities["high_amount_rule"]; got != 5 {
+		t.Errorf("live priority = %d, want 5", got)
+	}
+}
@@ -2,9 +2,11 @@ package services
 
 import (
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 	
+	"github.com/anti-fraud-golang/internal/cardintel"
 	"github.com/anti-fraud-golang/internal/models"
 )
 
@@ -28,7 +30,7 @@ func (s *InMemoryProfileStore) GetUserProfile(userID string) (*models.UserProfil
 	
 	profile, exists := s.profiles[userID]
 	if !exists {
-		return nil, fmt.Errorf("profile not found for user %s", userID)
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, userID)
 	}
 	
 	return profile, nil
@@ -47,10 +49,17 @@ func (s *InMemoryProfileStore) UpdateUserProfile(profile *models.UserProfile) er
 func (s *InMemoryProfileStore) CreateSampleProfile(userID string) *models.UserProfile {
 	profile := &models.UserProfile{
 		UserID:              userID,
-		AvgTransactionValue: 500.0,
+		AvgTransactionValue: models.NewMoney(50000, "BRL"),
 		TotalTransactions:   150,
 		FirstTransactionAt:  time.Now().AddDate(0, -6, 0),
 		LastTransactionAt:   time.Now().Add(-24 * time.Hour),
+		LastLocation: &models.Location{
+			Country:   "BR",
+			City:      "São Paulo",
+			Latitude:  -23.5505,
+			Longitude: -46.6333,
+		},
+		LastLocationAt: time.Now().Add(-24 * time.Hour),
 		CommonLocations: []models.Location{
 			{
 				Country:   "BR",
@@ -62,6 +71,22 @@ func (s *InMemoryProfileStore) CreateSampleProfile(userID string) *models.UserPr
 		CommonMerchants: []string{"Amazon", "Mercado Livre", "Magazine Luiza"},
 		FraudHistory:    []models.FraudIncident{},
 		TrustedDevices:  []string{"device-123"},
+		Baseline: &models.BehaviorBaseline{
+			Count:      150,
+			AmountMean: 500.0,
+			AmountM2:   149 * 250.0 * 250.0,
+			MerchantCounts: map[string]int{
+				"Amazon":         60,
+				"Mercado Livre":  50,
+				"Magazine Luiza": 40,
+			},
+			CountryCounts: map[string]int{"BR": 150},
+		},
+	}
+	
+	// Distribui as transações de exemplo em horário comercial (9h às 20h)
+	for i := 0; i < 150; i++ {
+		profile.Baseline.HourCounts[9+i%12]++
 	}
 	
 	s.UpdateUserProfile(profile)
@@ -81,8 +106,8 @@ func NewInMemoryBlacklistStore() *InMemoryBlacklistStore {
 	}
 }
 
-// IsBlacklisted verifica se um valor está na lista negra
-func (s *InMemoryBlacklistStore) IsBlacklisted(entryType, value string) (bool, error) {
+// IsBlacklisted verifica se um valor está na lista negra no instante informado
+func (s *InMemoryBlacklistStore) IsBlacklisted(entryType, value string, at time.Time) (bool, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	
@@ -101,7 +126,7 @@ func (s *InMemoryBlacklistStore) IsBlacklisted(entryType, value string) (bool, e
 		return false, nil
 	}
 	
-	if entry.ExpiresAt != nil && time.Now().After(*entry.ExpiresAt) {
+	if entry.ExpiresAt != nil && at.After(*entry.ExpiresAt) {
 		return false, nil
 	}
 	
@@ -110,6 +135,14 @@ func (s *InMemoryBlacklistStore) IsBlacklisted(entryType, value string) (bool, e
 
 // Add adiciona uma entrada na lista negra
 func (s *InMemoryBlacklistStore) Add(entry *models.BlacklistEntry) error {
+	if entry == nil || entry.Type == "" || entry.Value == "" {
+		return ErrInvalidBlacklistEntry
+	}
+	// Cartões entram pela impressão digital, nunca pelo número ou pelos últimos dígitos
+	if entry.Type == "card" && !cardintel.ValidFingerprint(entry.Value) {
+		return ErrInvalidBlacklistEntry
+	}
+	
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	
@@ -136,7 +169,7 @@ func (s *InMemoryBlacklistStore) AddSampleBlacklist() {
 	s.Add(&models.BlacklistEntry{
 		ID:       "bl-2",
 		Type:     "card",
-		Value:    "4567",
+		Value:    "9f2c4e7a1b3d5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8",
 		Reason:   "Cartão reportado como roubado",
 		AddedAt:  time.Now(),
 		IsActive: true,
@@ -151,3 +184,615 @@ func (s *InMemoryBlacklistStore) AddSampleBlacklist() {
 		IsActive: true,
 	})
 }
+
+// InMemoryPeerGroupStore implementação em memória do PeerGroupStore
+type InMemoryPeerGroupStore struct {
+	stats map[models.PeerGroupKey]*models.PeerGroupStats
+	mu    sync.RWMutex
+}
+
+// NewInMemoryPeerGroupStore cria uma nova instância
+func NewInMemoryPeerGroupStore() *InMemoryPeerGroupStore {
+	return &InMemoryPeerGroupStore{
+		stats: make(map[models.PeerGroupKey]*models.PeerGroupStats),
+	}
+}
+
+// Observe incorpora uma transação aos grupos de pares aos quais ela pertence
+func (s *InMemoryPeerGroupStore) Observe(transaction *models.Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range models.PeerGroupKeys(transaction) {
+		stats, exists := s.stats[key]
+		if !exists {
+			stats = &models.PeerGroupStats{PeerGroupKey: key}
+			s.stats[key] = stats
+		}
+		stats.Observe(transaction.AmountInBase().Float64(), transaction.Timestamp)
+	}
+
+	return nil
+}
+
+// GetPeerGroupStats retorna uma cópia das estatísticas de um grupo, ou nil se não existir
+func (s *InMemoryPeerGroupStore) GetPeerGroupStats(key models.PeerGroupKey) (*models.PeerGroupStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats, exists := s.stats[key]
+	if !exists {
+		return nil, nil
+	}
+
+	copy := *stats
+	return &copy, nil
+}
+
+// ListPeerGroupStats lista as estatísticas de todos os grupos de uma dimensão
+func (s *InMemoryPeerGroupStore) ListPeerGroupStats(dimension string) ([]models.PeerGroupStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]models.PeerGroupStats, 0)
+	for key, stats := range s.stats {
+		if key.Dimension == dimension {
+			result = append(result, *stats)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Count > result[j].Count
+	})
+
+	return result, nil
+}
+
+// velocityRetention tempo máximo de retenção das transações para velocidade
+const velocityRetention = 24 * time.Hour
+
+type velocityEntry struct {
+	at     time.Time
+	amount models.Money
+	userID string
+}
+
+// InMemoryVelocityStore implementação em memória do VelocityStore, indexada
+// por usuário e por impressão digital do cartão
+type InMemoryVelocityStore struct {
+	entries     map[string][]velocityEntry
+	cardEntries map[string][]velocityEntry
+	mu          sync.RWMutex
+}
+
+// NewInMemoryVelocityStore cria uma nova instância
+func NewInMemoryVelocityStore() *InMemoryVelocityStore {
+	return &InMemoryVelocityStore{
+		entries:     make(map[string][]velocityEntry),
+		cardEntries: make(map[string][]velocityEntry),
+	}
+}
+
+// Record registra uma transação e descarta as que saíram da janela de retenção
+func (s *InMemoryVelocityStore) Record(transaction *models.Transaction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := velocityEntry{
+		at:     transaction.Timestamp,
+		amount: transaction.AmountInBase(),
+		userID: transaction.UserID,
+	}
+	cutoff := transaction.Timestamp.Add(-velocityRetention)
+
+	s.entries[transaction.UserID] = appendVelocityEntry(s.entries[transaction.UserID], entry, cutoff)
+	if transaction.CardFingerprint != "" {
+		s.cardEntries[transaction.CardFingerprint] = appendVelocityEntry(s.cardEntries[transaction.CardFingerprint], entry, cutoff)
+	}
+	return nil
+}
+
+// GetVelocity retorna contagem e valor das transações do usuário em (at-window, at]
+func (s *InMemoryVelocityStore) GetVelocity(userID string, window time.Duration, at time.Time) (*models.VelocityCheck, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	check := &models.VelocityCheck{
+		UserID:     userID,
+		TimeWindow: int(window.Minutes()),
+	}
+	countVelocity(check, s.entries[userID], window, at)
+
+	return check, nil
+}
+
+// GetCardVelocity retorna contagem e valor das transações do cartão em
+// (at-window, at] e os usuários distintos que o usaram, incluindo o informado
+func (s *InMemoryVelocityStore) GetCardVelocity(fingerprint, userID string, window time.Duration, at time.Time) (*models.VelocityCheck, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	check := &models.VelocityCheck{
+		UserID:          userID,
+		CardFingerprint: fingerprint,
+		TimeWindow:      int(window.Minutes()),
+	}
+	users := countVelocity(check, s.cardEntries[fingerprint], window, at)
+	users[userID] = true
+	check.DistinctUsers = len(users)
+
+	return check, nil
+}
+
+// appendVelocityEntry acrescenta o registro descartando os anteriores ao corte
+func appendVelocityEntry(entries []velocityEntry, entry velocityEntry, cutoff time.Time) []velocityEntry {
+	kept := make([]velocityEntry, 0, len(entries)+1)
+	for _, e := range entries {
+		if e.at.After(cutoff) {
+			kept = append(kept, e)
+		}
+	}
+	return append(kept, entry)
+}
+
+// countVelocity acumula no check os registros da janela e retorna os usuários envolvidos
+func countVelocity(check *models.VelocityCheck, entries []velocityEntry, window time.Duration, at time.Time) map[string]bool {
+	users := make(map[string]bool)
+	start := at.Add(-window)
+	for _, entry := range entries {
+		if entry.at.After(start) && !entry.at.After(at) {
+			check.TransactionCount++
+			if check.TotalAmount.Currency == "" {
+				check.TotalAmount.Currency = entry.amount.Currency
+			}
+			check.TotalAmount.Minor += entry.amount.Minor
+			users[entry.userID] = true
+			if entry.at.After(check.LastTransactionAt) {
+				check.LastTransactionAt = entry.at
+			}
+		}
+	}
+	return users
+}
+
+// InMemoryRuleVersionStore implementação em memória do RuleVersionStore
+type InMemoryRuleVersionStore struct {
+	versions []RuleSetVersion
+	mu       sync.RWMutex
+}
+
+// NewInMemoryRuleVersionStore cria uma nova instância
+func NewInMemoryRuleVersionStore() *InMemoryRuleVersionStore {
+	return &InMemoryRuleVersionStore{
+		versions: make([]RuleSetVersion, 0),
+	}
+}
+
+// Append grava uma nova versão
+func (s *InMemoryRuleVersionStore) Append(version *RuleSetVersion) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if n := len(s.versions); n > 0 && version.Version <= s.versions[n-1].Version {
+		return fmt.Errorf("rule set version %d already exists", version.Version)
+	}
+
+	s.versions = append(s.versions, *version)
+	return nil
+}
+
+// Get retorna uma versão específica
+func (s *InMemoryRuleVersionStore) Get(version int) (*RuleSetVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for i := range s.versions {
+		if s.versions[i].Version == version {
+			found := s.versions[i]
+			return &found, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %d", ErrVersionNotFound, version)
+}
+
+// Latest retorna a versão mais recente, ou nil se não houver versões
+func (s *InMemoryRuleVersionStore) Latest() (*RuleSetVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.versions) == 0 {
+		return nil, nil
+	}
+
+	latest := s.versions[len(s.versions)-1]
+	return &latest, nil
+}
+
+// List retorna todas as versões, da mais recente para a mais antiga
+func (s *InMemoryRuleVersionStore) List() ([]RuleSetVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]RuleSetVersion, len(s.versions))
+	for i := range s.versions {
+		result[len(s.versions)-1-i] = s.versions[i]
+	}
+	return result, nil
+}
+
+// binVelocityRetention janela máxima consultada pelas regras de BIN
+const binVelocityRetention = time.Hour
+
+type binVelocityEntry struct {
+	at   time.Time
+	card string
+}
+
+// InMemoryBINVelocityStore implementação em memória do BINVelocityStore
+type InMemoryBINVelocityStore struct {
+	entries map[string][]binVelocityEntry
+	mu      sync.RWMutex
+}
+
+// NewInMemoryBINVelocityStore cria uma nova instância
+func NewInMemoryBINVelocityStore() *InMemoryBINVelocityStore {
+	return &InMemoryBINVelocityStore{
+		entries: make(map[string][]binVelocityEntry),
+	}
+}
+
+// Record registra o cartão da transação no seu BIN e descarta os registros
+// que saíram da janela de retenção
+func (s *InMemoryBINVelocityStore) Record(transaction *models.Transaction) error {
+	if transaction.CardBIN == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := transaction.Timestamp.Add(-binVelocityRetention)
+	kept := make([]binVelocityEntry, 0, len(s.entries[transaction.CardBIN])+1)
+	for _, entry := range s.entries[transaction.CardBIN] {
+		if entry.at.After(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+
+	s.entries[transaction.CardBIN] = append(kept, binVelocityEntry{
+		at:   transaction.Timestamp,
+		card: transaction.CardKey(),
+	})
+	return nil
+}
+
+// GetBINVelocity retorna cartões distintos e transações do BIN em (at-window, at],
+// contando também o cartão e a transação em análise
+func (s *InMemoryBINVelocityStore) GetBINVelocity(bin, card string, window time.Duration, at time.Time) (*models.BINVelocity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	velocity := &models.BINVelocity{
+		BIN:              bin,
+		TransactionCount: 1,
+		TimeWindow:       int(window.Minutes()),
+	}
+
+	cards := map[string]bool{card: true}
+	start := at.Add(-window)
+	for _, entry := range s.entries[bin] {
+		if entry.at.After(start) && !entry.at.After(at) {
+			velocity.TransactionCount++
+			cards[entry.card] = true
+		}
+	}
+	velocity.DistinctCards = len(cards)
+
+	return velocity, nil
+}
+
+// deviceUsageRetention janela máxima consultada pelas regras de dispositivo
+const deviceUsageRetention = 7 * 24 * time.Hour
+
+// InMemoryDeviceUsageStore implementação em memória do DeviceUsageStore.
+// Guarda, por impressão digital, o último uso de cada usuário.
+type InMemoryDeviceUsageStore struct {
+	lastSeen map[string]map[string]time.Time
+	mu       sync.RWMutex
+}
+
+// NewInMemoryDeviceUsageStore cria uma nova instância
+func NewInMemoryDeviceUsageStore() *InMemoryDeviceUsageStore {
+	return &InMemoryDeviceUsageStore{
+		lastSeen: make(map[string]map[string]time.Time),
+	}
+}
+
+// Record registra o usuário na impressão digital do dispositivo e descarta
+// os usuários que saíram da janela de retenção
+func (s *InMemoryDeviceUsageStore) Record(transaction *models.Transaction) error {
+	fingerprint := transaction.DeviceInfo.Fingerprint
+	if fingerprint == "" {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users, ok := s.lastSeen[fingerprint]
+	if !ok {
+		users = make(map[string]time.Time)
+		s.lastSeen[fingerprint] = users
+	}
+
+	cutoff := transaction.Timestamp.Add(-deviceUsageRetention)
+	for userID, at := range users {
+		if !at.After(cutoff) {
+			delete(users, userID)
+		}
+	}
+
+	if transaction.Timestamp.After(users[transaction.UserID]) {
+		users[transaction.UserID] = transaction.Timestamp
+	}
+	return nil
+}
+
+// GetDeviceUsage retorna os usuários distintos da impressão digital em
+// (at-window, at], contando também o usuário em análise
+func (s *InMemoryDeviceUsageStore) GetDeviceUsage(fingerprint, userID string, window time.Duration, at time.Time) (*models.DeviceUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usage := &models.DeviceUsage{
+		Fingerprint:   fingerprint,
+		DistinctUsers: 1,
+		TimeWindow:    int(window.Minutes()),
+	}
+
+	start := at.Add(-window)
+	for user, seen := range s.lastSeen[fingerprint] {
+		if user != userID && seen.After(start) && !seen.After(at) {
+			usage.DistinctUsers++
+		}
+	}
+
+	return usage, nil
+}
+
+// InMemoryTravelNoticeStore implementação em memória do TravelNoticeStore
+type InMemoryTravelNoticeStore struct {
+	notices map[string][]models.TravelNotice
+	mu      sync.RWMutex
+}
+
+// NewInMemoryTravelNoticeStore cria uma nova instância
+func NewInMemoryTravelNoticeStore() *InMemoryTravelNoticeStore {
+	return &InMemoryTravelNoticeStore{
+		notices: make(map[string][]models.TravelNotice),
+	}
+}
+
+// Add registra um aviso de viagem
+func (s *InMemoryTravelNoticeStore) Add(notice *models.TravelNotice) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.notices[notice.UserID] = append(s.notices[notice.UserID], *notice)
+	return nil
+}
+
+// List retorna os avisos do usuário em ordem de registro
+func (s *InMemoryTravelNoticeStore) List(userID string) ([]models.TravelNotice, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]models.TravelNotice{}, s.notices[userID]...), nil
+}
+
+// Delete remove um aviso do usuário
+func (s *InMemoryTravelNoticeStore) Delete(userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	notices := s.notices[userID]
+	for i := range notices {
+		if notices[i].ID == id {
+			s.notices[userID] = append(notices[:i:i], notices[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrTravelNoticeNotFound, id)
+}
+
+// InMemoryPIXLimitStore implementação em memória do PIXLimitStore
+type InMemoryPIXLimitStore struct {
+	limits map[string]models.PIXLimits
+	mu     sync.RWMutex
+}
+
+// NewInMemoryPIXLimitStore cria uma nova instância
+func NewInMemoryPIXLimitStore() *InMemoryPIXLimitStore {
+	return &InMemoryPIXLimitStore{
+		limits: make(map[string]models.PIXLimits),
+	}
+}
+
+// Get retorna uma cópia dos limites do usuário
+func (s *InMemoryPIXLimitStore) Get(userID string) (*models.PIXLimits, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	limits, ok := s.limits[userID]
+	if !ok {
+		return nil, nil
+	}
+	if limits.Pending != nil {
+		pending := *limits.Pending
+		limits.Pending = &pending
+	}
+	return &limits, nil
+}
+
+// Save substitui os limites do usuário
+func (s *InMemoryPIXLimitStore) Save(limits *models.PIXLimits) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	saved := *limits
+	if limits.Pending != nil {
+		pending := *limits.Pending
+		saved.Pending = &pending
+	}
+	s.limits[limits.UserID] = saved
+	return nil
+}
+
+// pixUsageRetention tempo mantido das transferências, suficiente para
+// cobrir o período mais longo e eventos fora de ordem
+const pixUsageRetention = 48 * time.Hour
+
+type pixUsageEntry struct {
+	transactionID string
+	at            time.Time
+	amount        models.Money
+}
+
+// InMemoryPIXUsageStore implementação em memória do PIXUsageStore
+type InMemoryPIXUsageStore struct {
+	transfers map[string][]pixUsageEntry
+	mu        sync.RWMutex
+}
+
+// NewInMemoryPIXUsageStore cria uma nova instância
+func NewInMemoryPIXUsageStore() *InMemoryPIXUsageStore {
+	return &InMemoryPIXUsageStore{
+		transfers: make(map[string][]pixUsageEntry),
+	}
+}
+
+// Reserve registra a transferência PIX se o total de [from, to) com ela não
+// passar do limite, verificando e somando sob a mesma trava. Limite zero é
+// ausência de limite; uma transação já registrada conta como reservada.
+func (s *InMemoryPIXUsageStore) Reserve(transaction *models.Transaction, from, to time.Time, limit models.Money) (bool, error) {
+	if transaction.PIX == nil {
+		return true, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := transaction.Timestamp.Add(-pixUsageRetention)
+	kept := s.transfers[transaction.UserID][:0]
+	var used int64
+	reserved := false
+	for _, entry := range s.transfers[transaction.UserID] {
+		if !entry.at.After(cutoff) {
+			continue
+		}
+		if transaction.ID != "" && entry.transactionID == transaction.ID {
+			reserved = true
+		}
+		if !entry.at.Before(from) && entry.at.Before(to) {
+			used += entry.amount.Minor
+		}
+		kept = append(kept, entry)
+	}
+	s.transfers[transaction.UserID] = kept
+	if reserved {
+		return true, nil
+	}
+	if limit.Minor > 0 && used+transaction.Amount.Minor > limit.Minor {
+		return false, nil
+	}
+	s.transfers[transaction.UserID] = append(kept, pixUsageEntry{
+		transactionID: transaction.ID,
+		at:            transaction.Timestamp,
+		amount:        transaction.Amount,
+	})
+	return true, nil
+}
+
+// GetPIXUsage soma as transferências do usuário em [from, to)
+func (s *InMemoryPIXUsageStore) GetPIXUsage(userID string, from, to time.Time) (*models.PIXUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usage := &models.PIXUsage{
+		UserID: userID,
+		Total:  models.NewMoney(0, "BRL"),
+		From:   from,
+		To:     to,
+	}
+	for _, entry := range s.transfers[userID] {
+		if !entry.at.Before(from) && entry.at.Before(to) {
+			usage.Count++
+			usage.Total.Minor += entry.amount.Minor
+		}
+	}
+	return usage, nil
+}
+
+// InMemoryMuleAccountStore implementação em memória do MuleAccountStore
+type InMemoryMuleAccountStore struct {
+	flags map[string]models.MuleFlag
+	mu    sync.RWMutex
+}
+
+// NewInMemoryMuleAccountStore cria uma nova instância
+func NewInMemoryMuleAccountStore() *InMemoryMuleAccountStore {
+	return &InMemoryMuleAccountStore{
+		flags: make(map[string]models.MuleFlag),
+	}
+}
+
+// Add marca a chave ou conta, substituindo uma marcação anterior do mesmo valor
+func (s *InMemoryMuleAccountStore) Add(flag *models.MuleFlag) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.flags[flag.Type+"|"+flag.Value] = *flag
+	return nil
+}
+
+// Get retorna a marcação do valor
+func (s *InMemoryMuleAccountStore) Get(flagType, value string) (*models.MuleFlag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	flag, ok := s.flags[flagType+"|"+value]
+	if !ok {
+		return nil, nil
+	}
+	return &flag, nil
+}
+
+// List retorna as marcações da mais antiga para a mais recente
+func (s *InMemoryMuleAccountStore) List() ([]models.MuleFlag, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	flags := make([]models.MuleFlag, 0, len(s.flags))
+	for _, flag := range s.flags {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].FlaggedAt.Before(flags[j].FlaggedAt)
+	})
+	return flags, nil
+}
+
+// Delete remove uma marcação pelo ID
+func (s *InMemoryMuleAccountStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, flag := range s.flags {
+		if flag.ID == id {
+			delete(s.flags, key)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrMuleFlagNotFound, id)
+}
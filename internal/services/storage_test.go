@@ -0,0 +1,61 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+func TestVelocityTotalsAreExact(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	store := NewInMemoryVelocityStore()
+
+	transactions := []*models.Transaction{
+		{UserID: "USER1", Amount: models.NewMoney(10, "BRL"), Currency: "BRL", Timestamp: now.Add(-2 * time.Hour)},
+		{UserID: "USER1", Amount: models.NewMoney(10, "BRL"), Currency: "BRL", CardFingerprint: "card-1", Timestamp: now.Add(-30 * time.Minute)},
+		{UserID: "USER1", Amount: models.NewMoney(20, "BRL"), Currency: "BRL", CardFingerprint: "card-1", Timestamp: now.Add(-10 * time.Minute)},
+		{
+			UserID:          "USER2",
+			Amount:          models.NewMoney(1000, "USD"),
+			Currency:        "USD",
+			CardFingerprint: "card-1",
+			Timestamp:       now,
+			FXConversion:    &models.FXConversion{Currency: "USD", BaseCurrency: "BRL", Rate: 5.1234, BaseAmount: models.NewMoney(5123, "BRL")},
+		},
+	}
+	for _, transaction := range transactions {
+		if err := store.Record(transaction); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name  string
+		check func() (*models.VelocityCheck, error)
+		count int
+		total models.Money
+	}{
+		{"user in the last hour", func() (*models.VelocityCheck, error) {
+			return store.GetVelocity("USER1", time.Hour, now)
+		}, 2, models.NewMoney(30, "BRL")},
+		{"card in base currency", func() (*models.VelocityCheck, error) {
+			return store.GetCardVelocity("card-1", "USER2", time.Hour, now)
+		}, 3, models.NewMoney(5153, "BRL")},
+		{"empty window", func() (*models.VelocityCheck, error) {
+			return store.GetVelocity("USER3", time.Hour, now)
+		}, 0, models.Money{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check, err := tt.check()
+			if err != nil {
+				t.Fatalf("velocity: %v", err)
+			}
+			if check.TransactionCount != tt.count || check.TotalAmount != tt.total {
+				t.Errorf("got %d transactions totalling %+v, want %d totalling %+v", check.TransactionCount, check.TotalAmount, tt.count, tt.total)
+			}
+		})
+	}
+}
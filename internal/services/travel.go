@@ -0,0 +1,117 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+	"github.com/anti-fraud-golang/internal/rules"
+	"github.com/google/uuid"
+)
+
+// Erros dos avisos de viagem
+var (
+	ErrInvalidTravelNotice  = errors.New("invalid travel notice")
+	ErrTravelNoticeNotFound = errors.New("travel notice not found")
+)
+
+// maxTravelNoticeDays duração máxima de um aviso de viagem
+const maxTravelNoticeDays = 365
+
+// RegisterTravelNotice valida e registra um aviso de viagem do usuário. As
+// datas são truncadas para o dia; avisos já encerrados são rejeitados.
+func (s *FraudDetectionService) RegisterTravelNotice(notice *models.TravelNotice) (*models.TravelNotice, error) {
+	if notice.UserID == "" {
+		return nil, fmt.Errorf("%w: user_id is required", ErrInvalidTravelNotice)
+	}
+	if len(notice.Countries) == 0 {
+		return nil, fmt.Errorf("%w: at least one country is required", ErrInvalidTravelNotice)
+	}
+
+	countries := make([]string, 0, len(notice.Countries))
+	for _, country := range notice.Countries {
+		country = strings.ToUpper(strings.TrimSpace(country))
+		if len(country) != 2 {
+			return nil, fmt.Errorf("%w: invalid country %q, expected ISO 3166-1 alpha-2", ErrInvalidTravelNotice, country)
+		}
+		countries = append(countries, country)
+	}
+	cities := make([]string, 0, len(notice.Cities))
+	for _, city := range notice.Cities {
+		if city = strings.TrimSpace(city); city == "" {
+			return nil, fmt.Errorf("%w: city must not be empty", ErrInvalidTravelNotice)
+		}
+		cities = append(cities, city)
+	}
+
+	start := truncateDate(notice.StartDate)
+	end := truncateDate(notice.EndDate)
+	if start.IsZero() || end.IsZero() {
+		return nil, fmt.Errorf("%w: start_date and end_date are required", ErrInvalidTravelNotice)
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("%w: end_date before start_date", ErrInvalidTravelNotice)
+	}
+	if end.Sub(start) > maxTravelNoticeDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: notice longer than %d days", ErrInvalidTravelNotice, maxTravelNoticeDays)
+	}
+	if end.Before(truncateDate(s.now())) {
+		return nil, fmt.Errorf("%w: end_date in the past", ErrInvalidTravelNotice)
+	}
+
+	registered := &models.TravelNotice{
+		ID:        "TRV-" + uuid.New().String(),
+		UserID:    notice.UserID,
+		Countries: countries,
+		Cities:    cities,
+		StartDate: start,
+		EndDate:   end,
+		CreatedAt: s.now(),
+	}
+	if err := s.travelNoticeStore.Add(registered); err != nil {
+		return nil, storeFailure(err)
+	}
+
+	return registered, nil
+}
+
+// ListTravelNotices retorna os avisos de viagem do usuário
+func (s *FraudDetectionService) ListTravelNotices(userID string) ([]models.TravelNotice, error) {
+	notices, err := s.travelNoticeStore.List(userID)
+	if err != nil {
+		return nil, storeFailure(err)
+	}
+	return notices, nil
+}
+
+// DeleteTravelNotice remove um aviso de viagem do usuário
+func (s *FraudDetectionService) DeleteTravelNotice(userID, id string) error {
+	if err := s.travelNoticeStore.Delete(userID, id); err != nil {
+		if errors.Is(err, ErrTravelNoticeNotFound) {
+			return err
+		}
+		return storeFailure(err)
+	}
+	return nil
+}
+
+// matchTravelNotice associa à transação o aviso de viagem ativo que a cobre
+func (s *FraudDetectionService) matchTravelNotice(transaction *models.Transaction) error {
+	notices, err := s.travelNoticeStore.List(transaction.UserID)
+	if err != nil {
+		return err
+	}
+	transaction.TravelNotice = rules.MatchTravelNotice(notices, transaction)
+	return nil
+}
+
+// truncateDate reduz o instante à data, em UTC
+func truncateDate(t time.Time) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
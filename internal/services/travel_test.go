@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anti-fraud-golang/internal/models"
+)
+
+// unavailableTravelStore simula o store de avisos de viagem fora do ar
+type unavailableTravelStore struct {
+	*InMemoryTravelNoticeStore
+}
+
+func (s unavailableTravelStore) List(userID string) ([]models.TravelNotice, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestTravelStoreFailureDoesNotAffectProfile(t *testing.T) {
+	now := time.Date(2026, 3, 10, 15, 0, 0, 0, time.UTC)
+	profiles := NewInMemoryProfileStore()
+	service := NewFraudDetectionService(profiles, NewInMemoryBlacklistStore())
+	service.SetClock(ClockFunc(func() time.Time { return now }))
+	service.travelNoticeStore = unavailableTravelStore{NewInMemoryTravelNoticeStore()}
+
+	// Falhas seguidas abririam o circuit breaker do perfil se fossem registradas nele
+	for i := 0; i < 10; i++ {
+		result, err := service.AnalyzeTransaction(context.Background(), &models.Transaction{
+			UserID:    "USER1",
+			Amount:    models.NewMoney(5000, "BRL"),
+			Currency:  "BRL",
+			Timestamp: now,
+		})
+		if err != nil {
+			t.Fatalf("AnalyzeTransaction: %v", err)
+		}
+		if !result.Degraded || len(result.DegradedReasons) != 1 || !strings.HasPrefix(result.DegradedReasons[0], DependencyTravel) {
+			t.Fatalf("degraded reasons = %v, want only %s", result.DegradedReasons, DependencyTravel)
+		}
+		if _, skipped := result.Details["profile_update"]; skipped {
+			t.Fatalf("analysis %d skipped the profile update", i+1)
+		}
+	}
+
+	profile, err := profiles.GetUserProfile("USER1")
+	if err != nil {
+		t.Fatalf("GetUserProfile: %v", err)
+	}
+	if profile.TotalTransactions != 10 {
+		t.Errorf("TotalTransactions = %d, want 10", profile.TotalTransactions)
+	}
+}